@@ -2,11 +2,15 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/Muto1907/interpreterInGo/token"
 )
 
+// Node is implemented by all syntax tree nodes. String returns source that
+// parses back to an equivalent tree.
 type Node interface {
 	TokenLiteral() string
 	String() string
@@ -34,17 +38,21 @@ func (p *Program) TokenLiteral() string {
 }
 
 func (p *Program) String() string {
-	var output bytes.Buffer
+	stmts := []string{}
 	for _, stmt := range p.Statements {
-		output.WriteString(stmt.String())
+		stmts = append(stmts, stmt.String())
 	}
-	return output.String()
+	return strings.Join(stmts, "\n")
 }
 
+// ReassignmentStatement assigns to an identifier, a dereferenced pointer or
+// an index expression. Operator is "=", a compound operator such as "+=",
+// or "++"/"--", which have no Value.
 type ReassignmentStatement struct {
-	Token token.Token
-	Left  Expression
-	Value Expression
+	Token    token.Token
+	Left     Expression
+	Operator string
+	Value    Expression
 }
 
 func (as *ReassignmentStatement) statementNode() {}
@@ -53,10 +61,11 @@ func (as *ReassignmentStatement) TokenLiteral() string {
 }
 func (as *ReassignmentStatement) String() string {
 	var output bytes.Buffer
-	output.WriteString(as.Left.String() + " = ")
-	if as.Value != nil {
-		output.WriteString(as.Value.String())
+	if as.Value == nil {
+		return as.Left.String() + as.Operator + ";"
 	}
+	output.WriteString(as.Left.String() + " " + as.Operator + " ")
+	output.WriteString(as.Value.String())
 	output.WriteString(";")
 	return output.String()
 }
@@ -73,28 +82,45 @@ func (ws *WhileStatement) TokenLiteral() string {
 }
 
 func (ws *WhileStatement) String() string {
-	var output bytes.Buffer
-	output.WriteString(ws.Token.Literal)
-	output.WriteString(ws.Condition.String() + " ")
-	output.WriteString(ws.Body.String())
-	return output.String()
+	return "while (" + ws.Condition.String() + ") " + ws.Body.String()
 }
 
+// LetStatement binds Name, or destructures Value into Pattern when the
+// binding is an array or hash pattern. It also represents const statements.
+// Type is the optional annotation of the binding.
 type LetStatement struct {
-	Token token.Token
-	Value Expression
-	Name  *Identifier
+	Token   token.Token
+	Value   Expression
+	Name    *Identifier
+	Pattern Pattern
+	Type    Type
 }
 
 func (ls *LetStatement) statementNode() {}
+
+// IsConst reports whether the statement declares constants with const.
+func (ls *LetStatement) IsConst() bool {
+	return ls.Token.Type == token.CONST
+}
 func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
 
 func (ls *LetStatement) String() string {
 	var output bytes.Buffer
-	output.WriteString(ls.TokenLiteral() + " ")
-	output.WriteString(ls.Name.String())
+	if ls.IsConst() {
+		output.WriteString("const ")
+	} else {
+		output.WriteString("let ")
+	}
+	if ls.Pattern != nil {
+		output.WriteString(ls.Pattern.String())
+	} else {
+		output.WriteString(ls.Name.String())
+	}
+	if ls.Type != nil {
+		output.WriteString(": " + ls.Type.String())
+	}
 	output.WriteString(" = ")
 	if ls.Value != nil {
 		output.WriteString(ls.Value.String())
@@ -115,7 +141,7 @@ func (ret *ReturnStatement) TokenLiteral() string {
 
 func (ret *ReturnStatement) String() string {
 	var output bytes.Buffer
-	output.WriteString(ret.TokenLiteral() + " ")
+	output.WriteString("return ")
 	if ret.ReturnValue != nil {
 		output.WriteString(ret.ReturnValue.String())
 	}
@@ -134,7 +160,7 @@ func (expr *ExpressionStatement) TokenLiteral() string {
 }
 func (expr *ExpressionStatement) String() string {
 	if expr.Expression != nil {
-		return expr.Expression.String()
+		return expr.Expression.String() + ";"
 	}
 	return ""
 }
@@ -145,6 +171,7 @@ type Identifier struct {
 }
 
 func (id *Identifier) expressionNode() {}
+func (id *Identifier) patternNode()    {}
 func (id *Identifier) TokenLiteral() string {
 	return id.Token.Literal
 }
@@ -152,9 +179,12 @@ func (id *Identifier) String() string {
 	return id.Value
 }
 
+// IntegerLiteral holds literals that fit into an int64 in Value and larger
+// ones in Big.
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int
 }
 
 func (inte *IntegerLiteral) expressionNode() {}
@@ -162,7 +192,13 @@ func (inte *IntegerLiteral) TokenLiteral() string {
 	return inte.Token.Literal
 }
 func (inte *IntegerLiteral) String() string {
-	return inte.Token.Literal
+	if inte.Token.Literal != "" {
+		return inte.Token.Literal
+	}
+	if inte.Big != nil {
+		return inte.Big.String()
+	}
+	return strconv.FormatInt(inte.Value, 10)
 }
 
 type StringLiteral struct {
@@ -175,8 +211,44 @@ func (str *StringLiteral) TokenLiteral() string {
 	return str.Token.Literal
 }
 
+// String quotes the value. A literal ${ can only be written escaped, as
+// \${, which the parser turns back into a string literal.
 func (str *StringLiteral) String() string {
-	return str.Token.Literal
+	return `"` + strings.ReplaceAll(str.Value, "${", `\${`) + `"`
+}
+
+// InterpolatedString is a string literal with embedded ${...} expressions.
+// Literal text is kept as *StringLiteral parts with a TEMPLATE token, which
+// tells it apart from a string literal embedded as ${"..."}.
+type InterpolatedString struct {
+	Token token.Token
+	Parts []Expression
+}
+
+func (interp *InterpolatedString) expressionNode() {}
+func (interp *InterpolatedString) TokenLiteral() string {
+	return interp.Token.Literal
+}
+
+// IsText reports whether part, a part of an InterpolatedString, is literal
+// text rather than an embedded expression.
+func IsText(part Expression) bool {
+	str, ok := part.(*StringLiteral)
+	return ok && str.Token.Type == token.TEMPLATE
+}
+
+func (interp *InterpolatedString) String() string {
+	var out bytes.Buffer
+	out.WriteString(`"`)
+	for _, part := range interp.Parts {
+		if IsText(part) {
+			out.WriteString(strings.ReplaceAll(part.(*StringLiteral).Value, "${", `\${`))
+			continue
+		}
+		out.WriteString("${" + part.String() + "}")
+	}
+	out.WriteString(`"`)
+	return out.String()
 }
 
 type PrefixExpression struct {
@@ -225,7 +297,7 @@ func (bool *Boolean) TokenLiteral() string {
 	return bool.Token.Literal
 }
 func (bool *Boolean) String() string {
-	return bool.Token.Literal
+	return strconv.FormatBool(bool.Value)
 }
 
 type IfExpression struct {
@@ -241,8 +313,7 @@ func (iff *IfExpression) TokenLiteral() string {
 }
 func (iff *IfExpression) String() string {
 	var output bytes.Buffer
-	output.WriteString("if")
-	output.WriteString(iff.Condition.String() + " ")
+	output.WriteString("if (" + iff.Condition.String() + ") ")
 	output.WriteString(iff.Then.String())
 	if iff.Alt != nil {
 		output.WriteString(" else ")
@@ -251,10 +322,12 @@ func (iff *IfExpression) String() string {
 	return output.String()
 }
 
+// BlockStatement spans from its opening Token to the closing brace Close.
 type BlockStatement struct {
 	Token          token.Token
 	Statements     []Statement
 	IsFunctionBody bool
+	Close          token.Token
 }
 
 func (blck *BlockStatement) statementNode() {}
@@ -262,19 +335,35 @@ func (blck *BlockStatement) TokenLiteral() string {
 	return blck.Token.Literal
 }
 func (blck *BlockStatement) String() string {
-	var output bytes.Buffer
+	if len(blck.Statements) == 0 {
+		return "{}"
+	}
+	stmts := []string{}
 	for _, stmt := range blck.Statements {
-		output.WriteString(stmt.String())
+		stmts = append(stmts, stmt.String())
 	}
-	return output.String()
+	return "{ " + strings.Join(stmts, " ") + " }"
 }
 
+// FuncLiteral is a function. ParamTypes is nil if no parameter is
+// annotated, otherwise ParamTypes[i] is the annotation of Parameters[i] or
+// nil. ReturnType is the optional annotation of the result.
 type FuncLiteral struct {
 	Token      token.Token
-	Parameters []*Identifier
+	Parameters []Pattern
+	ParamTypes []Type
+	ReturnType Type
 	Body       *BlockStatement
 }
 
+// ParamType returns the annotation of the i-th parameter, or nil.
+func (fn *FuncLiteral) ParamType(i int) Type {
+	if i < len(fn.ParamTypes) {
+		return fn.ParamTypes[i]
+	}
+	return nil
+}
+
 func (fn *FuncLiteral) expressionNode() {}
 func (fn *FuncLiteral) TokenLiteral() string {
 	return fn.Token.Literal
@@ -282,11 +371,19 @@ func (fn *FuncLiteral) TokenLiteral() string {
 func (fn *FuncLiteral) String() string {
 	var output bytes.Buffer
 	params := []string{}
-	for _, pa := range fn.Parameters {
-		params = append(params, pa.String())
+	for i, pa := range fn.Parameters {
+		if typ := fn.ParamType(i); typ != nil {
+			params = append(params, pa.String()+": "+typ.String())
+		} else {
+			params = append(params, pa.String())
+		}
+	}
+	output.WriteString("fnc(")
+	output.WriteString(strings.Join(params, ", ") + ")")
+	if fn.ReturnType != nil {
+		output.WriteString(": " + fn.ReturnType.String())
 	}
-	output.WriteString(fn.TokenLiteral() + "( ")
-	output.WriteString(strings.Join(params, ", ") + ") ")
+	output.WriteString(" ")
 	output.WriteString(fn.Body.String())
 	return output.String()
 }
@@ -354,9 +451,42 @@ func (ie *IndexExpression) String() string {
 	return output.String()
 }
 
+type SliceExpression struct {
+	Token token.Token
+	Left  Expression
+	Start Expression
+	End   Expression
+}
+
+func (se *SliceExpression) expressionNode() {}
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SliceExpression) String() string {
+	var output bytes.Buffer
+	output.WriteString("(")
+	output.WriteString(se.Left.String())
+	output.WriteString("[")
+	if se.Start != nil {
+		output.WriteString(se.Start.String())
+	}
+	output.WriteString(":")
+	if se.End != nil {
+		output.WriteString(se.End.String())
+	}
+	output.WriteString("])")
+	return output.String()
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+// HashLiteral keeps its pairs in source order.
 type HashLiteral struct {
 	Token token.Token
-	Pairs map[Expression]Expression
+	Pairs []HashPair
 }
 
 func (hsh *HashLiteral) expressionNode() {}
@@ -366,11 +496,258 @@ func (hsh *HashLiteral) TokenLiteral() string {
 func (hsh *HashLiteral) String() string {
 	var output bytes.Buffer
 	pairs := []string{}
-	for key, value := range hsh.Pairs {
-		pairs = append(pairs, key.String()+": "+value.String())
+	for _, pair := range hsh.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
 	}
 	output.WriteString("{")
 	output.WriteString(strings.Join(pairs, ", "))
 	output.WriteString("}")
 	return output.String()
 }
+
+// Pattern is a binding target in let statements and function parameters:
+// an *Identifier, *ArrayPattern or *HashPattern.
+type Pattern interface {
+	Expression
+	patternNode()
+}
+
+// ArrayPattern destructures an array, e.g. [a, [b, c], ...rest].
+type ArrayPattern struct {
+	Token    token.Token
+	Elements []Pattern
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) expressionNode() {}
+func (ap *ArrayPattern) patternNode()    {}
+func (ap *ArrayPattern) TokenLiteral() string {
+	return ap.Token.Literal
+}
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// HashPatternPair binds the value at Key, an *Identifier or *StringLiteral,
+// to Value.
+type HashPatternPair struct {
+	Key   Expression
+	Value Pattern
+}
+
+// KeyName returns the hash key the pair reads.
+func (pair HashPatternPair) KeyName() string {
+	if str, ok := pair.Key.(*StringLiteral); ok {
+		return str.Value
+	}
+	return pair.Key.String()
+}
+
+// HashPattern destructures a hash, e.g. {name, age: years, ...rest}.
+type HashPattern struct {
+	Token token.Token
+	Pairs []HashPatternPair
+	Rest  *Identifier
+}
+
+func (hp *HashPattern) expressionNode() {}
+func (hp *HashPattern) patternNode()    {}
+func (hp *HashPattern) TokenLiteral() string {
+	return hp.Token.Literal
+}
+func (hp *HashPattern) String() string {
+	pairs := []string{}
+	for _, pair := range hp.Pairs {
+		if ident, ok := pair.Value.(*Identifier); ok && ident.Value == pair.Key.String() {
+			if _, isIdent := pair.Key.(*Identifier); isIdent {
+				pairs = append(pairs, ident.String())
+				continue
+			}
+		}
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+	if hp.Rest != nil {
+		pairs = append(pairs, "..."+hp.Rest.String())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// ImportStatement loads the module at Path and either binds it to Alias or
+// binds the selected Names. With neither the module only runs.
+type ImportStatement struct {
+	Token token.Token
+	Path  *StringLiteral
+	Alias *Identifier
+	Names []ImportName
+}
+
+// ImportName selects an export, optionally renamed with as.
+type ImportName struct {
+	Name  *Identifier
+	Alias *Identifier
+}
+
+// Binding returns the name the import is bound to.
+func (in ImportName) Binding() *Identifier {
+	if in.Alias != nil {
+		return in.Alias
+	}
+	return in.Name
+}
+
+func (is *ImportStatement) statementNode() {}
+func (is *ImportStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+func (is *ImportStatement) String() string {
+	path := "\"" + is.Path.Value + "\""
+	if is.Names != nil {
+		names := []string{}
+		for _, name := range is.Names {
+			if name.Alias != nil {
+				names = append(names, name.Name.String()+" as "+name.Alias.String())
+			} else {
+				names = append(names, name.Name.String())
+			}
+		}
+		return "import {" + strings.Join(names, ", ") + "} from " + path + ";"
+	}
+	if is.Alias != nil {
+		return "import " + path + " as " + is.Alias.String() + ";"
+	}
+	return "import " + path + ";"
+}
+
+// ExportStatement lists the names a module makes visible to importers.
+type ExportStatement struct {
+	Token token.Token
+	Names []*Identifier
+}
+
+func (es *ExportStatement) statementNode() {}
+func (es *ExportStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+func (es *ExportStatement) String() string {
+	names := []string{}
+	for _, name := range es.Names {
+		names = append(names, name.String())
+	}
+	return "export {" + strings.Join(names, ", ") + "};"
+}
+
+// MemberExpression reads an export of a module, e.g. m.name.
+type MemberExpression struct {
+	Token    token.Token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode() {}
+func (me *MemberExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+func (me *MemberExpression) String() string {
+	return "(" + me.Object.String() + "." + me.Property.String() + ")"
+}
+
+// TokenOf returns the token recorded in node, which carries its position.
+// Programs have none.
+func TokenOf(node Node) token.Token {
+	switch n := node.(type) {
+	case *LetStatement:
+		return n.Token
+	case *ReturnStatement:
+		return n.Token
+	case *ExpressionStatement:
+		return n.Token
+	case *ReassignmentStatement:
+		return n.Token
+	case *WhileStatement:
+		return n.Token
+	case *BlockStatement:
+		return n.Token
+	case *ImportStatement:
+		return n.Token
+	case *ExportStatement:
+		return n.Token
+	case *Identifier:
+		return n.Token
+	case *IntegerLiteral:
+		return n.Token
+	case *StringLiteral:
+		return n.Token
+	case *InterpolatedString:
+		return n.Token
+	case *Boolean:
+		return n.Token
+	case *PrefixExpression:
+		return n.Token
+	case *InfixExpression:
+		return n.Token
+	case *IfExpression:
+		return n.Token
+	case *FuncLiteral:
+		return n.Token
+	case *CallExpression:
+		return n.Token
+	case *ArrayLiteral:
+		return n.Token
+	case *HashLiteral:
+		return n.Token
+	case *IndexExpression:
+		return n.Token
+	case *SliceExpression:
+		return n.Token
+	case *MemberExpression:
+		return n.Token
+	case *ArrayPattern:
+		return n.Token
+	case *HashPattern:
+		return n.Token
+	case *NamedType:
+		return n.Token
+	case *ArrayType:
+		return n.Token
+	case *HashType:
+		return n.Token
+	case *RecordType:
+		return n.Token
+	case *FuncType:
+		return n.Token
+	default:
+		return token.Token{}
+	}
+}
+
+// Start returns the first token of node. Unlike TokenOf it looks through
+// expressions that record an operator or delimiter, e.g. for a + b it
+// returns the token of a.
+func Start(node Node) token.Token {
+	switch n := node.(type) {
+	case *ExpressionStatement:
+		if n.Expression != nil {
+			return Start(n.Expression)
+		}
+	case *ReassignmentStatement:
+		return Start(n.Left)
+	case *InfixExpression:
+		return Start(n.Left)
+	case *CallExpression:
+		return Start(n.Function)
+	case *IndexExpression:
+		return Start(n.Left)
+	case *SliceExpression:
+		return Start(n.Left)
+	case *MemberExpression:
+		return Start(n.Object)
+	}
+	return TokenOf(node)
+}
@@ -1,27 +1,36 @@
-package ast
+package ast_test
 
 import (
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
 	"github.com/Muto1907/interpreterInGo/token"
 )
 
 func TestString(t *testing.T) {
-	Program := &Program{
-		Statements: []Statement{
-			&LetStatement{
+	Program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.LetStatement{
 				Token: token.Token{
 					Type:    token.LET,
 					Literal: "let",
 				},
-				Name: &Identifier{
+				Name: &ast.Identifier{
 					Token: token.Token{
 						Type:    token.IDENT,
 						Literal: "myVar",
 					},
 					Value: "myVar",
 				},
-				Value: &Identifier{
+				Value: &ast.Identifier{
 					Token: token.Token{
 						Type:    token.IDENT,
 						Literal: "anotherVar",
@@ -35,3 +44,408 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() error, got=%q", Program.String())
 	}
 }
+
+func TestStringIsValidSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x > 0) { x--; }", "while ((x > 0)) { x--; }"},
+		{"let f = fnc(a, [b, ...c]) { a + b };", "let f = fnc(a, [b, ...c]) { (a + b); };"},
+		{"if (a) { b } else { c; d }", "if (a) { b; } else { c; d; };"},
+		{"if (a) {}", "if (a) {};"},
+		{`let s = "x${a + 1}\${y}";`, `let s = "x${(a + 1)}\${y}";`},
+		{`"a${"b"}c${"${d}"}"`, `"a${"b"}c${"${d}"}";`},
+		{`"\${raw}"`, `"\${raw}";`},
+		{`{"a": [1, 2], 3: true}["a"]`, `({"a": [1, 2], 3: true}["a"]);`},
+		{`const {name, "full name": full} = p;`, `const {name, "full name": full} = p;`},
+		{"a; b", "a;\nb;"},
+	}
+
+	for i, tt := range tests {
+		program := parse(t, tt.input)
+		if program.String() != tt.expected {
+			t.Errorf("tests[%d] - wrong String(). expected=%q got=%q", i, tt.expected, program.String())
+		}
+	}
+}
+
+// TestStringRoundTrip generates random programs and checks that their source
+// parses back to the same tree.
+func TestStringRoundTrip(t *testing.T) {
+	gen := &generator{rand: rand.New(rand.NewSource(1))}
+	for i := 0; i < 2000; i++ {
+		program := gen.program()
+		src := program.String()
+		reparsed := parse(t, src)
+		if !reflect.DeepEqual(shape(t, reparsed), shape(t, program)) {
+			t.Fatalf("program %d does not round trip.\nsource:   %s\nreparsed: %s", i, src, reparsed.String())
+		}
+	}
+}
+
+func FuzzStringRoundTrip(f *testing.F) {
+	for _, seed := range []string{
+		"let x = 5; x += 1; x++;",
+		"let f = fnc([a, b], {c, d: e, ...f}) { if (a < b) { return c; } else { e } }; f([1, 2], {})",
+		`while (i < len(arr)) { puts("${arr[i]}: \${raw}"); i++ }`,
+		`import "std/math" as m; import {max as largest} from "std/math"; export {m}; m.pow(2, 10)[1:]`,
+		"-(-a) * ~b & *p | !c << 2 % 3 ^ 4 >> 1 == 99999999999999999999999",
+		`"a${"b"}c"`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Skip()
+		}
+		src := program.String()
+		if reparsed := parse(t, src); !reflect.DeepEqual(shape(t, reparsed), shape(t, program)) {
+			t.Fatalf("program does not round trip.\nsource:   %s\nreparsed: %s", src, reparsed.String())
+		}
+	})
+}
+
+// shape returns the JSON encoding of a tree without positions and integer
+// spellings, so trees can be compared whatever source they came from.
+func shape(t *testing.T, node ast.Node) any {
+	t.Helper()
+	data, err := ast.ToJSON(node)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var strip func(v any)
+	strip = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for _, key := range []string{"line", "column", "endLine", "endColumn", "literal"} {
+				delete(v, key)
+			}
+			for _, child := range v {
+				strip(child)
+			}
+		case []any:
+			for _, child := range v {
+				strip(child)
+			}
+		}
+	}
+	strip(tree)
+	return tree
+}
+
+func parse(t *testing.T, src string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %s", src, strings.Join(p.Errors(), "; "))
+	}
+	return program
+}
+
+// generator builds random syntax trees. Only the fields String uses are
+// filled in.
+type generator struct {
+	rand  *rand.Rand
+	depth int
+}
+
+var (
+	names            = []string{"a", "b", "x", "arr", "größe", "_tmp"}
+	prefixOperators  = []string{"!", "-", "~", "&", "*"}
+	infixOperators   = []string{"+", "-", "*", "/", "%", "<", ">", "==", "!=", "&", "|", "^", "<<", ">>"}
+	assignOperators  = []string{"=", "+=", "-=", "*=", "/=", "%="}
+	stringCharacters = []string{"a", "Z", " ", "{", "}", "$", "ü", "${"}
+	typeNames        = []string{"int", "string", "bool", "null", "any"}
+)
+
+func (g *generator) pick(n int) int {
+	return g.rand.Intn(n)
+}
+
+func (g *generator) program() *ast.Program {
+	program := &ast.Program{}
+	for i := g.pick(4); i >= 0; i-- {
+		program.Statements = append(program.Statements, g.statement())
+	}
+	return program
+}
+
+func (g *generator) ident() *ast.Identifier {
+	return &ast.Identifier{Value: names[g.pick(len(names))]}
+}
+
+func (g *generator) block() *ast.BlockStatement {
+	block := &ast.BlockStatement{}
+	for i := g.pick(3); i > 0; i-- {
+		block.Statements = append(block.Statements, g.statement())
+	}
+	return block
+}
+
+func (g *generator) statement() ast.Statement {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	switch g.pick(9) {
+	case 0:
+		tok := token.Token{Type: token.LET}
+		if g.pick(2) == 0 {
+			tok.Type = token.CONST
+		}
+		stmt := &ast.LetStatement{Token: tok, Name: g.ident(), Value: g.expr()}
+		if g.pick(3) == 0 {
+			// A pattern that is just an identifier is parsed as a name.
+			switch pattern := g.pattern().(type) {
+			case *ast.Identifier:
+				stmt.Name = pattern
+			default:
+				stmt.Name, stmt.Pattern = nil, pattern
+			}
+		}
+		if g.pick(3) == 0 {
+			stmt.Type = g.typ()
+		}
+		return stmt
+	case 1:
+		return &ast.ReturnStatement{ReturnValue: g.expr()}
+	case 2:
+		var left ast.Expression = g.ident()
+		switch g.pick(3) {
+		case 0:
+			left = &ast.IndexExpression{Left: g.ident(), Index: g.expr()}
+		case 1:
+			left = &ast.PrefixExpression{Operator: "*", Right: g.ident()}
+		}
+		if g.pick(3) == 0 {
+			return &ast.ReassignmentStatement{Left: left, Operator: []string{"++", "--"}[g.pick(2)]}
+		}
+		return &ast.ReassignmentStatement{Left: left, Operator: assignOperators[g.pick(len(assignOperators))], Value: g.expr()}
+	case 3:
+		if g.depth > 3 {
+			return &ast.ExpressionStatement{Expression: g.ident()}
+		}
+		return &ast.WhileStatement{Condition: g.expr(), Body: g.block()}
+	case 4:
+		stmt := &ast.ImportStatement{Path: &ast.StringLiteral{Value: "lib/" + g.ident().Value}}
+		switch g.pick(3) {
+		case 0:
+			stmt.Alias = g.ident()
+		case 1:
+			stmt.Names = []ast.ImportName{}
+			for i := g.pick(3); i > 0; i-- {
+				name := ast.ImportName{Name: g.ident()}
+				if g.pick(2) == 0 {
+					name.Alias = g.ident()
+				}
+				stmt.Names = append(stmt.Names, name)
+			}
+		}
+		return stmt
+	case 5:
+		stmt := &ast.ExportStatement{}
+		for i := g.pick(3); i > 0; i-- {
+			stmt.Names = append(stmt.Names, g.ident())
+		}
+		return stmt
+	default:
+		return &ast.ExpressionStatement{Expression: g.expr()}
+	}
+}
+
+func (g *generator) pattern() ast.Pattern {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > 4 {
+		return g.ident()
+	}
+	switch g.pick(3) {
+	case 0:
+		pattern := &ast.ArrayPattern{}
+		for i := g.pick(3); i > 0; i-- {
+			pattern.Elements = append(pattern.Elements, g.pattern())
+		}
+		if g.pick(2) == 0 {
+			pattern.Rest = g.ident()
+		}
+		return pattern
+	case 1:
+		pattern := &ast.HashPattern{}
+		for i := g.pick(3); i > 0; i-- {
+			var key ast.Expression = g.ident()
+			if g.pick(2) == 0 {
+				// Keys are plain strings, which cannot contain ${.
+				key = &ast.StringLiteral{Value: strings.ReplaceAll(g.stringLiteral().Value, "${", "$ {")}
+			}
+			pattern.Pairs = append(pattern.Pairs, ast.HashPatternPair{Key: key, Value: g.pattern()})
+		}
+		if g.pick(2) == 0 {
+			pattern.Rest = g.ident()
+		}
+		return pattern
+	default:
+		return g.ident()
+	}
+}
+
+func (g *generator) typ() ast.Type {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > 6 {
+		return &ast.NamedType{Name: typeNames[g.pick(len(typeNames))]}
+	}
+	switch g.pick(5) {
+	case 0:
+		return &ast.ArrayType{Element: g.typ()}
+	case 1:
+		return &ast.HashType{Key: g.typ(), Value: g.typ()}
+	case 2:
+		record := &ast.RecordType{}
+		for i := g.pick(3); i > 0; i-- {
+			var key ast.Expression = g.ident()
+			if g.pick(2) == 0 {
+				key = &ast.StringLiteral{Value: strings.ReplaceAll(g.stringLiteral().Value, "${", "$ {")}
+			}
+			record.Fields = append(record.Fields, ast.RecordField{Key: key, Type: g.typ()})
+		}
+		return record
+	case 3:
+		fn := &ast.FuncType{}
+		for i := g.pick(3); i > 0; i-- {
+			fn.Parameters = append(fn.Parameters, g.typ())
+		}
+		if g.pick(2) == 0 {
+			fn.Return = g.typ()
+		}
+		return fn
+	default:
+		return &ast.NamedType{Name: typeNames[g.pick(len(typeNames))]}
+	}
+}
+
+func (g *generator) stringLiteral() *ast.StringLiteral {
+	var value strings.Builder
+	for i := g.pick(4); i > 0; i-- {
+		value.WriteString(stringCharacters[g.pick(len(stringCharacters))])
+	}
+	return &ast.StringLiteral{Value: value.String()}
+}
+
+// text returns literal text for an interpolated string, which is never
+// empty since the parser leaves empty text out.
+func (g *generator) text() *ast.StringLiteral {
+	text := g.stringLiteral()
+	if text.Value == "" {
+		text.Value = "_"
+	}
+	text.Token = token.Token{Type: token.TEMPLATE}
+	return text
+}
+
+func (g *generator) exprs() []ast.Expression {
+	exprs := []ast.Expression{}
+	for i := g.pick(4); i > 0; i-- {
+		exprs = append(exprs, g.expr())
+	}
+	return exprs
+}
+
+func (g *generator) expr() ast.Expression {
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if g.depth > 5 {
+		switch g.pick(3) {
+		case 0:
+			return &ast.IntegerLiteral{Value: g.rand.Int63()}
+		case 1:
+			return g.stringLiteral()
+		default:
+			return g.ident()
+		}
+	}
+	switch g.pick(17) {
+	case 0:
+		return &ast.IntegerLiteral{Value: g.rand.Int63n(1000)}
+	case 1:
+		big, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		return &ast.IntegerLiteral{Big: big}
+	case 2:
+		return &ast.Boolean{Value: g.pick(2) == 0}
+	case 3:
+		return g.stringLiteral()
+	case 4:
+		interp := &ast.InterpolatedString{}
+		for i := g.pick(3); i >= 0; i-- {
+			if g.pick(2) == 0 {
+				interp.Parts = append(interp.Parts, g.text())
+			}
+			interp.Parts = append(interp.Parts, g.expr())
+		}
+		return interp
+	case 5:
+		return &ast.PrefixExpression{Operator: prefixOperators[g.pick(len(prefixOperators))], Right: g.expr()}
+	case 6, 7:
+		return &ast.InfixExpression{Left: g.expr(), Operator: infixOperators[g.pick(len(infixOperators))], Right: g.expr()}
+	case 8:
+		iff := &ast.IfExpression{Condition: g.expr(), Then: g.block()}
+		if g.pick(2) == 0 {
+			iff.Alt = g.block()
+		}
+		return iff
+	case 9:
+		fn := &ast.FuncLiteral{Body: g.block()}
+		annotated := g.pick(2) == 0
+		for i := g.pick(3); i > 0; i-- {
+			fn.Parameters = append(fn.Parameters, g.pattern())
+			if annotated {
+				var typ ast.Type
+				if g.pick(2) == 0 {
+					typ = g.typ()
+				}
+				fn.ParamTypes = append(fn.ParamTypes, typ)
+			}
+		}
+		if fn.ParamTypes != nil && !slices.ContainsFunc(fn.ParamTypes, func(t ast.Type) bool { return t != nil }) {
+			fn.ParamTypes = nil
+		}
+		if g.pick(3) == 0 {
+			fn.ReturnType = g.typ()
+		}
+		return fn
+	case 10:
+		return &ast.CallExpression{Function: g.expr(), Arguments: g.exprs()}
+	case 11:
+		return &ast.ArrayLiteral{Elements: g.exprs()}
+	case 12:
+		hash := &ast.HashLiteral{}
+		for i := g.pick(3); i > 0; i-- {
+			hash.Pairs = append(hash.Pairs, ast.HashPair{Key: g.expr(), Value: g.expr()})
+		}
+		return hash
+	case 13:
+		return &ast.IndexExpression{Left: g.expr(), Index: g.expr()}
+	case 14:
+		slice := &ast.SliceExpression{Left: g.expr()}
+		if g.pick(2) == 0 {
+			slice.Start = g.expr()
+		}
+		if g.pick(2) == 0 {
+			slice.End = g.expr()
+		}
+		return slice
+	case 15:
+		return &ast.MemberExpression{Object: g.expr(), Property: g.ident()}
+	default:
+		return g.ident()
+	}
+}
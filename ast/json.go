@@ -0,0 +1,641 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+// ToJSON encodes the tree rooted at node. Every node becomes an object with
+// its "kind", the Go type name such as "InfixExpression", its "line" and
+// "column" and its children under lower-case field names, e.g.
+//
+//	{"kind": "InfixExpression", "line": 1, "column": 3, "operator": "+",
+//	 "left": {...}, "right": {...}}
+//
+// Missing children are null. Integer values are strings, as they may not
+// fit into a float64.
+func ToJSON(node Node) ([]byte, error) {
+	return json.Marshal(encode(node))
+}
+
+// jsonObject is a JSON object that keeps the order of its fields.
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.key)
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(key)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+func object(kind string, tok token.Token, fields ...jsonField) jsonObject {
+	obj := jsonObject{{"kind", kind}}
+	if tok.Line > 0 {
+		obj = append(obj, jsonField{"line", tok.Line}, jsonField{"column", tok.Column})
+	}
+	return append(obj, fields...)
+}
+
+func isNil(node Node) bool {
+	return node == nil || reflect.ValueOf(node).IsNil()
+}
+
+func encode(node Node) any {
+	if isNil(node) {
+		return nil
+	}
+	switch n := node.(type) {
+	case *Program:
+		return jsonObject{{"kind", "Program"}, {"statements", encodeList(n.Statements)}}
+	case *LetStatement:
+		return object("LetStatement", n.Token,
+			jsonField{"const", n.IsConst()},
+			jsonField{"name", encode(n.Name)},
+			jsonField{"pattern", encode(n.Pattern)},
+			jsonField{"type", encode(n.Type)},
+			jsonField{"value", encode(n.Value)})
+	case *ReturnStatement:
+		return object("ReturnStatement", n.Token, jsonField{"value", encode(n.ReturnValue)})
+	case *ExpressionStatement:
+		return object("ExpressionStatement", n.Token, jsonField{"expression", encode(n.Expression)})
+	case *ReassignmentStatement:
+		return object("ReassignmentStatement", n.Token,
+			jsonField{"operator", n.Operator},
+			jsonField{"left", encode(n.Left)},
+			jsonField{"value", encode(n.Value)})
+	case *WhileStatement:
+		return object("WhileStatement", n.Token,
+			jsonField{"condition", encode(n.Condition)},
+			jsonField{"body", encode(n.Body)})
+	case *BlockStatement:
+		obj := object("BlockStatement", n.Token, jsonField{"statements", encodeList(n.Statements)})
+		if n.Close.Line > 0 {
+			obj = append(obj, jsonField{"endLine", n.Close.Line}, jsonField{"endColumn", n.Close.Column})
+		}
+		return obj
+	case *ImportStatement:
+		var names any
+		if n.Names != nil {
+			list := []any{}
+			for _, name := range n.Names {
+				list = append(list, jsonObject{{"name", encode(name.Name)}, {"alias", encode(name.Alias)}})
+			}
+			names = list
+		}
+		return object("ImportStatement", n.Token,
+			jsonField{"path", encode(n.Path)},
+			jsonField{"alias", encode(n.Alias)},
+			jsonField{"names", names})
+	case *ExportStatement:
+		return object("ExportStatement", n.Token, jsonField{"names", encodeList(n.Names)})
+
+	case *Identifier:
+		return object("Identifier", n.Token, jsonField{"value", n.Value})
+	case *IntegerLiteral:
+		value := strconv.FormatInt(n.Value, 10)
+		if n.Big != nil {
+			value = n.Big.String()
+		}
+		return object("IntegerLiteral", n.Token, jsonField{"value", value}, jsonField{"literal", n.Token.Literal})
+	case *StringLiteral:
+		if IsText(n) {
+			return object("StringLiteral", n.Token, jsonField{"value", n.Value}, jsonField{"text", true})
+		}
+		return object("StringLiteral", n.Token, jsonField{"value", n.Value})
+	case *InterpolatedString:
+		return object("InterpolatedString", n.Token, jsonField{"parts", encodeList(n.Parts)})
+	case *Boolean:
+		return object("Boolean", n.Token, jsonField{"value", n.Value})
+	case *PrefixExpression:
+		return object("PrefixExpression", n.Token,
+			jsonField{"operator", n.Operator},
+			jsonField{"right", encode(n.Right)})
+	case *InfixExpression:
+		return object("InfixExpression", n.Token,
+			jsonField{"operator", n.Operator},
+			jsonField{"left", encode(n.Left)},
+			jsonField{"right", encode(n.Right)})
+	case *IfExpression:
+		return object("IfExpression", n.Token,
+			jsonField{"condition", encode(n.Condition)},
+			jsonField{"then", encode(n.Then)},
+			jsonField{"else", encode(n.Alt)})
+	case *FuncLiteral:
+		var paramTypes any
+		if n.ParamTypes != nil {
+			paramTypes = encodeList(n.ParamTypes)
+		}
+		return object("FuncLiteral", n.Token,
+			jsonField{"parameters", encodeList(n.Parameters)},
+			jsonField{"parameterTypes", paramTypes},
+			jsonField{"returnType", encode(n.ReturnType)},
+			jsonField{"body", encode(n.Body)})
+	case *CallExpression:
+		return object("CallExpression", n.Token,
+			jsonField{"function", encode(n.Function)},
+			jsonField{"arguments", encodeList(n.Arguments)})
+	case *ArrayLiteral:
+		return object("ArrayLiteral", n.Token, jsonField{"elements", encodeList(n.Elements)})
+	case *HashLiteral:
+		pairs := []any{}
+		for _, pair := range n.Pairs {
+			pairs = append(pairs, jsonObject{{"key", encode(pair.Key)}, {"value", encode(pair.Value)}})
+		}
+		return object("HashLiteral", n.Token, jsonField{"pairs", pairs})
+	case *IndexExpression:
+		return object("IndexExpression", n.Token,
+			jsonField{"left", encode(n.Left)},
+			jsonField{"index", encode(n.Index)})
+	case *SliceExpression:
+		return object("SliceExpression", n.Token,
+			jsonField{"left", encode(n.Left)},
+			jsonField{"start", encode(n.Start)},
+			jsonField{"end", encode(n.End)})
+	case *MemberExpression:
+		return object("MemberExpression", n.Token,
+			jsonField{"object", encode(n.Object)},
+			jsonField{"property", encode(n.Property)})
+
+	case *ArrayPattern:
+		return object("ArrayPattern", n.Token,
+			jsonField{"elements", encodeList(n.Elements)},
+			jsonField{"rest", encode(n.Rest)})
+	case *HashPattern:
+		pairs := []any{}
+		for _, pair := range n.Pairs {
+			pairs = append(pairs, jsonObject{{"key", encode(pair.Key)}, {"value", encode(pair.Value)}})
+		}
+		return object("HashPattern", n.Token,
+			jsonField{"pairs", pairs},
+			jsonField{"rest", encode(n.Rest)})
+
+	case *NamedType:
+		return object("NamedType", n.Token, jsonField{"name", n.Name})
+	case *ArrayType:
+		return object("ArrayType", n.Token, jsonField{"element", encode(n.Element)})
+	case *HashType:
+		return object("HashType", n.Token,
+			jsonField{"key", encode(n.Key)},
+			jsonField{"value", encode(n.Value)})
+	case *RecordType:
+		fields := []any{}
+		for _, field := range n.Fields {
+			fields = append(fields, jsonObject{{"key", encode(field.Key)}, {"type", encode(field.Type)}})
+		}
+		return object("RecordType", n.Token, jsonField{"fields", fields})
+	case *FuncType:
+		return object("FuncType", n.Token,
+			jsonField{"parameters", encodeList(n.Parameters)},
+			jsonField{"return", encode(n.Return)})
+	default:
+		panic(fmt.Sprintf("ast: cannot encode %T", node))
+	}
+}
+
+func encodeList[T Node](nodes []T) []any {
+	list := []any{}
+	for _, node := range nodes {
+		list = append(list, encode(node))
+	}
+	return list
+}
+
+// FromJSON decodes a tree encoded by ToJSON. Tokens are rebuilt from the
+// node kinds and values, so the tree can be evaluated or printed again.
+// Positions are optional, but a node missing a child its kind always has,
+// such as the left operand of an infix expression, is an error.
+func FromJSON(data []byte) (Node, error) {
+	d := &decoder{}
+	node := d.node(json.RawMessage(data), "")
+	if d.err != nil {
+		return nil, d.err
+	}
+	return node, nil
+}
+
+// decoder keeps the first error, so decoding can go on without checks
+// after every field.
+type decoder struct {
+	err error
+}
+
+func (d *decoder) fail(path, format string, args ...any) {
+	if d.err == nil {
+		if path == "" {
+			path = "root"
+		}
+		d.err = fmt.Errorf("ast: %s: %s", path, fmt.Sprintf(format, args...))
+	}
+}
+
+// fields parses a JSON object, returning nil for null.
+func (d *decoder) fields(data json.RawMessage, path string) map[string]json.RawMessage {
+	if d.err != nil || len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		d.fail(path, "%s", err)
+		return nil
+	}
+	return fields
+}
+
+func (d *decoder) value(fields map[string]json.RawMessage, key, path string, target any) {
+	raw, ok := fields[key]
+	if d.err != nil || !ok || string(raw) == "null" {
+		return
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		d.fail(path+"."+key, "%s", err)
+	}
+}
+
+func (d *decoder) string(fields map[string]json.RawMessage, key, path string) string {
+	var s string
+	d.value(fields, key, path, &s)
+	return s
+}
+
+// token rebuilds the token of a node from its position.
+func (d *decoder) token(fields map[string]json.RawMessage, path string, typ token.TokenType, literal string) token.Token {
+	tok := token.Token{Type: typ, Literal: literal}
+	d.value(fields, "line", path, &tok.Line)
+	d.value(fields, "column", path, &tok.Column)
+	return tok
+}
+
+func (d *decoder) list(fields map[string]json.RawMessage, key, path string) []Node {
+	var raws []json.RawMessage
+	d.value(fields, key, path, &raws)
+	nodes := []Node{}
+	for i, raw := range raws {
+		nodes = append(nodes, d.node(raw, fmt.Sprintf("%s.%s[%d]", path, key, i)))
+	}
+	return nodes
+}
+
+func (d *decoder) child(fields map[string]json.RawMessage, key, path string) Node {
+	return d.node(fields[key], path+"."+key)
+}
+
+func (d *decoder) node(data json.RawMessage, path string) Node {
+	fields := d.fields(data, path)
+	if fields == nil {
+		return nil
+	}
+	kind := d.string(fields, "kind", path)
+	at := path
+	if path == "" {
+		path = kind
+	}
+
+	switch kind {
+	case "Program":
+		return &Program{Statements: as[Statement](d, d.list(fields, "statements", path), path+".statements")}
+	case "LetStatement":
+		var constant bool
+		d.value(fields, "const", path, &constant)
+		stmt := &LetStatement{Token: d.token(fields, path, token.LET, "let")}
+		if constant {
+			stmt.Token.Type, stmt.Token.Literal = token.CONST, "const"
+		}
+		stmt.Name = one[*Identifier](d, d.child(fields, "name", path), path+".name")
+		stmt.Pattern = one[Pattern](d, d.child(fields, "pattern", path), path+".pattern")
+		stmt.Type = d.typ(fields, "type", path)
+		stmt.Value = need(d, d.expr(fields, "value", path), "value", path)
+		if _, isIdent := stmt.Pattern.(*Identifier); isIdent {
+			d.fail(path, "identifiers are bound with name, not pattern")
+		}
+		if (stmt.Name == nil) == (stmt.Pattern == nil) {
+			d.fail(path, "needs exactly one of name and pattern")
+		}
+		return stmt
+	case "ReturnStatement":
+		return &ReturnStatement{Token: d.token(fields, path, token.RETURN, "return"), ReturnValue: need(d, d.expr(fields, "value", path), "value", path)}
+	case "ExpressionStatement":
+		return &ExpressionStatement{Token: d.token(fields, path, "", ""), Expression: need(d, d.expr(fields, "expression", path), "expression", path)}
+	case "ReassignmentStatement":
+		operator := d.operator(fields, path)
+		stmt := &ReassignmentStatement{
+			Token:    d.token(fields, path, token.TokenType(operator), operator),
+			Left:     need(d, d.expr(fields, "left", path), "left", path),
+			Operator: operator,
+			Value:    d.expr(fields, "value", path),
+		}
+		if operator != "++" && operator != "--" {
+			need(d, stmt.Value, "value", path)
+		}
+		return stmt
+	case "WhileStatement":
+		return &WhileStatement{
+			Token:     d.token(fields, path, token.WHILE, "while"),
+			Condition: need(d, d.expr(fields, "condition", path), "condition", path),
+			Body:      need(d, d.block(fields, "body", path), "body", path),
+		}
+	case "BlockStatement":
+		block := &BlockStatement{
+			Token:      d.token(fields, path, token.BRACEL, "{"),
+			Statements: as[Statement](d, d.list(fields, "statements", path), path+".statements"),
+			Close:      token.Token{Type: token.BRACER, Literal: "}"},
+		}
+		d.value(fields, "endLine", path, &block.Close.Line)
+		d.value(fields, "endColumn", path, &block.Close.Column)
+		return block
+	case "ImportStatement":
+		stmt := &ImportStatement{
+			Token: d.token(fields, path, token.IMPORT, "import"),
+			Path:  one[*StringLiteral](d, d.child(fields, "path", path), path+".path"),
+			Alias: d.ident(fields, "alias", path),
+		}
+		var names []json.RawMessage
+		d.value(fields, "names", path, &names)
+		if names != nil {
+			stmt.Names = []ImportName{}
+		}
+		for i, raw := range names {
+			namePath := fmt.Sprintf("%s.names[%d]", path, i)
+			nameFields := d.fields(raw, namePath)
+			stmt.Names = append(stmt.Names, ImportName{Name: need(d, d.ident(nameFields, "name", namePath), "name", namePath), Alias: d.ident(nameFields, "alias", namePath)})
+		}
+		if stmt.Path == nil {
+			d.fail(path, "missing path")
+		}
+		return stmt
+	case "ExportStatement":
+		return &ExportStatement{
+			Token: d.token(fields, path, token.EXPORT, "export"),
+			Names: as[*Identifier](d, d.list(fields, "names", path), path+".names"),
+		}
+
+	case "Identifier":
+		value := d.string(fields, "value", path)
+		return &Identifier{Token: d.token(fields, path, token.IDENT, value), Value: value}
+	case "IntegerLiteral":
+		value := d.string(fields, "value", path)
+		literal := d.string(fields, "literal", path)
+		if literal == "" {
+			literal = value
+		}
+		inte := &IntegerLiteral{Token: d.token(fields, path, token.INT, literal)}
+		n, ok := new(big.Int).SetString(value, 10)
+		switch {
+		case !ok:
+			d.fail(path, "invalid integer %q", value)
+		case n.IsInt64():
+			inte.Value = n.Int64()
+		default:
+			inte.Big = n
+		}
+		return inte
+	case "StringLiteral":
+		value := d.string(fields, "value", path)
+		var text bool
+		d.value(fields, "text", path, &text)
+		var typ token.TokenType = token.STRING
+		if text {
+			typ = token.TEMPLATE
+		}
+		return &StringLiteral{Token: d.token(fields, path, typ, value), Value: value}
+	case "InterpolatedString":
+		interp := &InterpolatedString{Parts: as[Expression](d, d.list(fields, "parts", path), path+".parts")}
+		literal := interp.String()
+		interp.Token = d.token(fields, path, token.TEMPLATE, literal[1:len(literal)-1])
+		return interp
+	case "Boolean":
+		var value bool
+		d.value(fields, "value", path, &value)
+		tok := d.token(fields, path, token.FALSE, "false")
+		if value {
+			tok.Type, tok.Literal = token.TRUE, "true"
+		}
+		return &Boolean{Token: tok, Value: value}
+	case "PrefixExpression":
+		operator := d.operator(fields, path)
+		return &PrefixExpression{
+			Token:    d.token(fields, path, token.TokenType(operator), operator),
+			Operator: operator,
+			Right:    need(d, d.expr(fields, "right", path), "right", path),
+		}
+	case "InfixExpression":
+		operator := d.operator(fields, path)
+		return &InfixExpression{
+			Token:    d.token(fields, path, token.TokenType(operator), operator),
+			Left:     need(d, d.expr(fields, "left", path), "left", path),
+			Operator: operator,
+			Right:    need(d, d.expr(fields, "right", path), "right", path),
+		}
+	case "IfExpression":
+		return &IfExpression{
+			Token:     d.token(fields, path, token.IF, "if"),
+			Condition: need(d, d.expr(fields, "condition", path), "condition", path),
+			Then:      need(d, d.block(fields, "then", path), "then", path),
+			Alt:       d.block(fields, "else", path),
+		}
+	case "FuncLiteral":
+		fnc := &FuncLiteral{
+			Token:      d.token(fields, path, token.FUNCTION, "fnc"),
+			Parameters: as[Pattern](d, d.list(fields, "parameters", path), path+".parameters"),
+			ReturnType: d.typ(fields, "returnType", path),
+			Body:       need(d, d.block(fields, "body", path), "body", path),
+		}
+		var paramTypes []json.RawMessage
+		d.value(fields, "parameterTypes", path, &paramTypes)
+		if paramTypes != nil {
+			fnc.ParamTypes = []Type{}
+			if len(paramTypes) != len(fnc.Parameters) {
+				d.fail(path+".parameterTypes", "has %d entries for %d parameters", len(paramTypes), len(fnc.Parameters))
+			}
+		}
+		for i, raw := range paramTypes {
+			typePath := fmt.Sprintf("%s.parameterTypes[%d]", path, i)
+			fnc.ParamTypes = append(fnc.ParamTypes, one[Type](d, d.node(raw, typePath), typePath))
+		}
+		return fnc
+	case "CallExpression":
+		return &CallExpression{
+			Token:     d.token(fields, path, token.PARENL, "("),
+			Function:  need(d, d.expr(fields, "function", path), "function", path),
+			Arguments: as[Expression](d, d.list(fields, "arguments", path), path+".arguments"),
+		}
+	case "ArrayLiteral":
+		return &ArrayLiteral{
+			Token:    d.token(fields, path, token.BRACKETL, "["),
+			Elements: as[Expression](d, d.list(fields, "elements", path), path+".elements"),
+		}
+	case "HashLiteral":
+		hash := &HashLiteral{Token: d.token(fields, path, token.BRACEL, "{"), Pairs: []HashPair{}}
+		for i, pair := range d.pairs(fields, path) {
+			pairPath := fmt.Sprintf("%s.pairs[%d]", path, i)
+			hash.Pairs = append(hash.Pairs, HashPair{
+				Key:   need(d, d.expr(pair, "key", pairPath), "key", pairPath),
+				Value: need(d, d.expr(pair, "value", pairPath), "value", pairPath),
+			})
+		}
+		return hash
+	case "IndexExpression":
+		return &IndexExpression{
+			Token: d.token(fields, path, token.BRACKETL, "["),
+			Left:  need(d, d.expr(fields, "left", path), "left", path),
+			Index: need(d, d.expr(fields, "index", path), "index", path),
+		}
+	case "SliceExpression":
+		return &SliceExpression{
+			Token: d.token(fields, path, token.BRACKETL, "["),
+			Left:  need(d, d.expr(fields, "left", path), "left", path),
+			Start: d.expr(fields, "start", path),
+			End:   d.expr(fields, "end", path),
+		}
+	case "MemberExpression":
+		return &MemberExpression{
+			Token:    d.token(fields, path, token.DOT, "."),
+			Object:   need(d, d.expr(fields, "object", path), "object", path),
+			Property: need(d, d.ident(fields, "property", path), "property", path),
+		}
+
+	case "ArrayPattern":
+		return &ArrayPattern{
+			Token:    d.token(fields, path, token.BRACKETL, "["),
+			Elements: as[Pattern](d, d.list(fields, "elements", path), path+".elements"),
+			Rest:     d.ident(fields, "rest", path),
+		}
+	case "HashPattern":
+		pattern := &HashPattern{Token: d.token(fields, path, token.BRACEL, "{"), Rest: d.ident(fields, "rest", path)}
+		for i, pair := range d.pairs(fields, path) {
+			pairPath := fmt.Sprintf("%s.pairs[%d]", path, i)
+			pattern.Pairs = append(pattern.Pairs, HashPatternPair{
+				Key:   need(d, d.expr(pair, "key", pairPath), "key", pairPath),
+				Value: need(d, one[Pattern](d, d.child(pair, "value", pairPath), pairPath+".value"), "value", pairPath),
+			})
+		}
+		return pattern
+
+	case "NamedType":
+		name := d.string(fields, "name", path)
+		return &NamedType{Token: d.token(fields, path, token.IDENT, name), Name: name}
+	case "ArrayType":
+		return &ArrayType{Token: d.token(fields, path, token.BRACKETL, "["), Element: need(d, d.typ(fields, "element", path), "element", path)}
+	case "HashType":
+		return &HashType{
+			Token: d.token(fields, path, token.BRACEL, "{"),
+			Key:   need(d, d.typ(fields, "key", path), "key", path),
+			Value: need(d, d.typ(fields, "value", path), "value", path),
+		}
+	case "RecordType":
+		record := &RecordType{Token: d.token(fields, path, token.BRACEL, "{"), Fields: []RecordField{}}
+		var raws []json.RawMessage
+		d.value(fields, "fields", path, &raws)
+		for i, raw := range raws {
+			fieldPath := fmt.Sprintf("%s.fields[%d]", path, i)
+			field := d.fields(raw, fieldPath)
+			record.Fields = append(record.Fields, RecordField{
+				Key:  need(d, d.expr(field, "key", fieldPath), "key", fieldPath),
+				Type: need(d, d.typ(field, "type", fieldPath), "type", fieldPath),
+			})
+		}
+		return record
+	case "FuncType":
+		return &FuncType{
+			Token:      d.token(fields, path, token.FUNCTION, "fnc"),
+			Parameters: as[Type](d, d.list(fields, "parameters", path), path+".parameters"),
+			Return:     d.typ(fields, "return", path),
+		}
+	default:
+		d.fail(at, "unknown node kind %q", kind)
+		return nil
+	}
+}
+
+func (d *decoder) pairs(fields map[string]json.RawMessage, path string) []map[string]json.RawMessage {
+	var raws []json.RawMessage
+	d.value(fields, "pairs", path, &raws)
+	pairs := []map[string]json.RawMessage{}
+	for i, raw := range raws {
+		pairs = append(pairs, d.fields(raw, fmt.Sprintf("%s.pairs[%d]", path, i)))
+	}
+	return pairs
+}
+
+func (d *decoder) expr(fields map[string]json.RawMessage, key, path string) Expression {
+	return one[Expression](d, d.child(fields, key, path), path+"."+key)
+}
+
+func (d *decoder) ident(fields map[string]json.RawMessage, key, path string) *Identifier {
+	return one[*Identifier](d, d.child(fields, key, path), path+"."+key)
+}
+
+func (d *decoder) typ(fields map[string]json.RawMessage, key, path string) Type {
+	return one[Type](d, d.child(fields, key, path), path+"."+key)
+}
+
+func (d *decoder) block(fields map[string]json.RawMessage, key, path string) *BlockStatement {
+	return one[*BlockStatement](d, d.child(fields, key, path), path+"."+key)
+}
+
+// operator decodes the operator of a node, which every operator node has.
+func (d *decoder) operator(fields map[string]json.RawMessage, path string) string {
+	operator := d.string(fields, "operator", path)
+	if operator == "" {
+		d.fail(path, "missing operator")
+	}
+	return operator
+}
+
+// need fails if node, a child that every node of its kind has, is missing.
+func need[T Node](d *decoder, node T, key, path string) T {
+	if isNil(node) {
+		d.fail(path, "missing %s", key)
+	}
+	return node
+}
+
+// one converts a decoded node to the type its field needs.
+func one[T Node](d *decoder, node Node, path string) T {
+	var zero T
+	if node == nil {
+		return zero
+	}
+	t, ok := node.(T)
+	if !ok {
+		d.fail(path, "%s is not a %s", strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."), strings.TrimPrefix(reflect.TypeFor[T]().String(), "ast."))
+	}
+	return t
+}
+
+func as[T Node](d *decoder, nodes []Node, path string) []T {
+	list := make([]T, 0, len(nodes))
+	for i, node := range nodes {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+		if node == nil {
+			d.fail(elementPath, "missing node")
+			continue
+		}
+		list = append(list, one[T](d, node, elementPath))
+	}
+	return list
+}
@@ -0,0 +1,164 @@
+package ast_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+func TestToJSON(t *testing.T) {
+	program := parse(t, "const x = -y + 12345678901234567890;")
+
+	expected := `{"kind":"Program","statements":[` +
+		`{"kind":"LetStatement","line":1,"column":1,"const":true,` +
+		`"name":{"kind":"Identifier","line":1,"column":7,"value":"x"},"pattern":null,"type":null,` +
+		`"value":{"kind":"InfixExpression","line":1,"column":14,"operator":"+",` +
+		`"left":{"kind":"PrefixExpression","line":1,"column":11,"operator":"-",` +
+		`"right":{"kind":"Identifier","line":1,"column":12,"value":"y"}},` +
+		`"right":{"kind":"IntegerLiteral","line":1,"column":16,"value":"12345678901234567890","literal":"12345678901234567890"}}}]}`
+
+	data, err := ast.ToJSON(program)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(data) != expected {
+		t.Errorf("wrong JSON.\nexpected=%s\ngot=     %s", expected, data)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	inputs := []string{
+		`let {a, "b c": [d, ...e], ...f} = {"k": g(1)[2:], true: -h}; a += b.c;`,
+		`import "lib" as m; import {} from "x"; import {p as q, r} from "y"; export {m, q};`,
+		`let f = fnc(x, [y]) { while (x > 0) { x--; } if (x) { "v${x + 1}\${w}" } else { 0x1F } }; f(1)[:2];`,
+	}
+	gen := &generator{rand: rand.New(rand.NewSource(3))}
+	for i := 0; i < 200; i++ {
+		inputs = append(inputs, gen.program().String())
+	}
+
+	for _, input := range inputs {
+		program := parse(t, input)
+		data, err := ast.ToJSON(program)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", input, err)
+		}
+		decoded, err := ast.FromJSON(data)
+		if err != nil {
+			t.Fatalf("unexpected error decoding %q: %s", input, err)
+		}
+		if decoded.String() != program.String() {
+			t.Fatalf("wrong program.\nexpected=%s\ngot=     %s", program, decoded)
+		}
+		again, _ := ast.ToJSON(decoded)
+		if !bytes.Equal(again, data) {
+			t.Fatalf("encoding changed after decoding %q.\nbefore=%s\nafter= %s", input, data, again)
+		}
+	}
+}
+
+func TestFromJSONRebuildsTokens(t *testing.T) {
+	data := `{"kind": "Program", "statements": [
+		{"kind": "LetStatement", "const": true, "name": {"kind": "Identifier", "value": "x"},
+		 "value": {"kind": "InfixExpression", "operator": "<<", "line": 2, "column": 3,
+		           "left": {"kind": "Boolean", "value": true},
+		           "right": {"kind": "IntegerLiteral", "value": "99999999999999999999"}}}]}`
+
+	node, err := ast.FromJSON([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	let := node.(*ast.Program).Statements[0].(*ast.LetStatement)
+	if !let.IsConst() || let.String() != "const x = (true << 99999999999999999999);" {
+		t.Fatalf("wrong statement. got=%q", let.String())
+	}
+	infix := let.Value.(*ast.InfixExpression)
+	expectedToken := token.Token{Type: token.SHL, Literal: "<<", Line: 2, Column: 3}
+	if infix.Token != expectedToken {
+		t.Errorf("wrong token. expected=%+v got=%+v", expectedToken, infix.Token)
+	}
+	if infix.Left.(*ast.Boolean).Token.Type != token.TRUE {
+		t.Errorf("wrong boolean token. got=%+v", infix.Left.(*ast.Boolean).Token)
+	}
+	if big := infix.Right.(*ast.IntegerLiteral).Big; big == nil || big.String() != "99999999999999999999" {
+		t.Errorf("wrong big integer. got=%v", big)
+	}
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1]`, "ast: root: json: cannot unmarshal array"},
+		{`{"kind": "Nope"}`, `ast: root: unknown node kind "Nope"`},
+		{`{"kind": "Program", "statements": [{"kind": "Identifier", "value": "x"}]}`,
+			"ast: Program.statements[0]: Identifier is not a Statement"},
+		{`{"kind": "ArrayLiteral", "elements": [null]}`, "ast: ArrayLiteral.elements[0]: missing node"},
+		{`{"kind": "IntegerLiteral", "value": "1.5"}`, `ast: IntegerLiteral: invalid integer "1.5"`},
+		{`{"kind": "LetStatement", "value": {"kind": "Boolean", "value": true}}`,
+			"ast: LetStatement: needs exactly one of name and pattern"},
+		{`{"kind": "PrefixExpression", "operator": "-", "right": {"kind": "ExportStatement"}}`,
+			"ast: PrefixExpression.right: ExportStatement is not a Expression"},
+	}
+
+	for _, tt := range tests {
+		_, err := ast.FromJSON([]byte(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("wrong error for %s.\nexpected=%s\ngot=     %v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestFromJSONMissingChildren(t *testing.T) {
+	x := `{"kind": "Identifier", "value": "x"}`
+	block := `{"kind": "BlockStatement", "statements": []}`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"kind": "LetStatement", "name": ` + x + `}`, "ast: LetStatement: missing value"},
+		{`{"kind": "ReturnStatement"}`, "ast: ReturnStatement: missing value"},
+		{`{"kind": "ExpressionStatement"}`, "ast: ExpressionStatement: missing expression"},
+		{`{"kind": "ReassignmentStatement", "operator": "=", "value": ` + x + `}`, "ast: ReassignmentStatement: missing left"},
+		{`{"kind": "ReassignmentStatement", "operator": "+=", "left": ` + x + `}`, "ast: ReassignmentStatement: missing value"},
+		{`{"kind": "ReassignmentStatement", "left": ` + x + `, "value": ` + x + `}`, "ast: ReassignmentStatement: missing operator"},
+		{`{"kind": "WhileStatement", "body": ` + block + `}`, "ast: WhileStatement: missing condition"},
+		{`{"kind": "WhileStatement", "condition": ` + x + `}`, "ast: WhileStatement: missing body"},
+		{`{"kind": "ImportStatement", "path": {"kind": "StringLiteral", "value": "m"}, "names": [{}]}`,
+			"ast: ImportStatement.names[0]: missing name"},
+		{`{"kind": "PrefixExpression", "operator": "-"}`, "ast: PrefixExpression: missing right"},
+		{`{"kind": "PrefixExpression", "right": ` + x + `}`, "ast: PrefixExpression: missing operator"},
+		{`{"kind": "InfixExpression", "operator": "+", "right": ` + x + `}`, "ast: InfixExpression: missing left"},
+		{`{"kind": "InfixExpression", "operator": "+", "left": ` + x + `}`, "ast: InfixExpression: missing right"},
+		{`{"kind": "IfExpression", "then": ` + block + `}`, "ast: IfExpression: missing condition"},
+		{`{"kind": "IfExpression", "condition": ` + x + `}`, "ast: IfExpression: missing then"},
+		{`{"kind": "FuncLiteral", "parameters": []}`, "ast: FuncLiteral: missing body"},
+		{`{"kind": "CallExpression", "arguments": []}`, "ast: CallExpression: missing function"},
+		{`{"kind": "HashLiteral", "pairs": [{"value": ` + x + `}]}`, "ast: HashLiteral.pairs[0]: missing key"},
+		{`{"kind": "HashLiteral", "pairs": [{"key": ` + x + `}]}`, "ast: HashLiteral.pairs[0]: missing value"},
+		{`{"kind": "IndexExpression", "index": ` + x + `}`, "ast: IndexExpression: missing left"},
+		{`{"kind": "IndexExpression", "left": ` + x + `}`, "ast: IndexExpression: missing index"},
+		{`{"kind": "SliceExpression"}`, "ast: SliceExpression: missing left"},
+		{`{"kind": "MemberExpression", "property": ` + x + `}`, "ast: MemberExpression: missing object"},
+		{`{"kind": "MemberExpression", "object": ` + x + `}`, "ast: MemberExpression: missing property"},
+		{`{"kind": "HashPattern", "pairs": [{"key": ` + x + `}]}`, "ast: HashPattern.pairs[0]: missing value"},
+		{`{"kind": "ArrayType"}`, "ast: ArrayType: missing element"},
+		{`{"kind": "HashType", "key": {"kind": "NamedType", "name": "int"}}`, "ast: HashType: missing value"},
+		{`{"kind": "RecordType", "fields": [{"key": ` + x + `}]}`, "ast: RecordType.fields[0]: missing type"},
+		{`{"kind": "Program", "statements": [{"kind": "ExpressionStatement", "expression": ` +
+			`{"kind": "CallExpression", "function": ` + x + `, "arguments": [{"kind": "InfixExpression", "operator": "*"}]}}]}`,
+			"ast: Program.statements[0].expression.arguments[0]: missing left"},
+	}
+
+	for _, tt := range tests {
+		_, err := ast.FromJSON([]byte(tt.input))
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error for %s.\nexpected=%s\ngot=     %v", tt.input, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package ast
+
+import "slices"
+
+// An ApplyFunc is called by Apply for each node. Returning false from the
+// pre function skips the node's children, returning false from the post
+// function stops the traversal.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses the tree rooted at root in the order of Walk, calling pre
+// before and post after the children of each node. Either may be nil.
+// Nodes can be replaced, deleted or surrounded by new nodes through the
+// cursor; replaced nodes are traversed instead of the original and
+// inserted nodes are not traversed. Apply returns the possibly replaced
+// root.
+func Apply(root Node, pre, post ApplyFunc) (result Node) {
+	parent := &struct{ Node }{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+
+	a := &application{pre: pre, post: post}
+	a.apply(nil, "", -1, root, func(n Node) { parent.Node = n })
+	return parent.Node
+}
+
+var abort = new(int)
+
+// A Cursor describes the node being visited by Apply.
+type Cursor struct {
+	parent Node
+	name   string
+	index  int
+	node   Node
+	set    func(Node)
+	list   *listEditor // nil unless the node is an element of a list
+}
+
+// Node returns the current node.
+func (c *Cursor) Node() Node { return c.node }
+
+// Parent returns the parent of the current node, or nil for the root.
+func (c *Cursor) Parent() Node { return c.parent }
+
+// Name returns the name of the parent's field holding the current node,
+// e.g. "Statements" or "Condition". Keys and values of hash pairs are
+// named "Key" and "Value", keys and types of record fields "Key" and
+// "Type".
+func (c *Cursor) Name() string { return c.name }
+
+// Index returns the position of the current node in its parent's list, or
+// of its pair or field for hash keys and values and record fields. It is
+// -1 otherwise.
+func (c *Cursor) Index() int {
+	if c.list != nil {
+		return c.list.index
+	}
+	return c.index
+}
+
+// Replace replaces the current node with n. It panics if n does not fit
+// the parent's field, e.g. an expression in a list of statements.
+func (c *Cursor) Replace(n Node) {
+	c.set(n)
+	c.node = n
+}
+
+// Delete removes the current node from its list. It panics if the node is
+// not an element of a list.
+func (c *Cursor) Delete() {
+	c.mustBeInList("Delete")
+	c.list.delete(c.list.index)
+	c.list.step--
+}
+
+// InsertBefore inserts n before the current node in its list. The new node
+// is not traversed.
+func (c *Cursor) InsertBefore(n Node) {
+	c.mustBeInList("InsertBefore")
+	c.list.insert(c.list.index, n)
+	c.list.index++
+}
+
+// InsertAfter inserts n after the current node in its list. The new node
+// is not traversed.
+func (c *Cursor) InsertAfter(n Node) {
+	c.mustBeInList("InsertAfter")
+	c.list.insert(c.list.index+1, n)
+	c.list.step++
+}
+
+func (c *Cursor) mustBeInList(op string) {
+	if c.list == nil {
+		panic(op + " on a node that is not in a list")
+	}
+}
+
+// listEditor edits the list being traversed. index is the position of the
+// current element and step is added to it to reach the next one.
+type listEditor struct {
+	index, step int
+	delete      func(i int)
+	insert      func(i int, n Node)
+}
+
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+}
+
+func (a *application) apply(parent Node, name string, index int, n Node, set func(Node)) {
+	a.visit(Cursor{parent: parent, name: name, index: index, node: n, set: set})
+}
+
+func (a *application) visit(c Cursor) {
+	saved := a.cursor
+	a.cursor = c
+	defer func() { a.cursor = saved }()
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		return
+	}
+
+	switch n := a.cursor.node.(type) {
+	case *Program:
+		applyList(a, n, "Statements", &n.Statements)
+	case *LetStatement:
+		if n.Pattern != nil {
+			a.apply(n, "Pattern", -1, n.Pattern, func(x Node) { n.Pattern = x.(Pattern) })
+		} else if n.Name != nil {
+			a.apply(n, "Name", -1, n.Name, func(x Node) { n.Name = x.(*Identifier) })
+		}
+		a.applyType(n, "Type", -1, &n.Type)
+		a.applyExpr(n, "Value", -1, &n.Value)
+	case *ReturnStatement:
+		a.applyExpr(n, "ReturnValue", -1, &n.ReturnValue)
+	case *ExpressionStatement:
+		a.applyExpr(n, "Expression", -1, &n.Expression)
+	case *ReassignmentStatement:
+		a.applyExpr(n, "Left", -1, &n.Left)
+		a.applyExpr(n, "Value", -1, &n.Value)
+	case *WhileStatement:
+		a.applyExpr(n, "Condition", -1, &n.Condition)
+		a.applyBlock(n, "Body", &n.Body)
+	case *BlockStatement:
+		applyList(a, n, "Statements", &n.Statements)
+	case *ImportStatement:
+		if n.Path != nil {
+			a.apply(n, "Path", -1, n.Path, func(x Node) { n.Path = x.(*StringLiteral) })
+		}
+		a.applyIdent(n, "Alias", -1, &n.Alias)
+		for i := range n.Names {
+			a.applyIdent(n, "Name", i, &n.Names[i].Name)
+			a.applyIdent(n, "Alias", i, &n.Names[i].Alias)
+		}
+	case *ExportStatement:
+		applyList(a, n, "Names", &n.Names)
+
+	case *InterpolatedString:
+		applyList(a, n, "Parts", &n.Parts)
+	case *PrefixExpression:
+		a.applyExpr(n, "Right", -1, &n.Right)
+	case *InfixExpression:
+		a.applyExpr(n, "Left", -1, &n.Left)
+		a.applyExpr(n, "Right", -1, &n.Right)
+	case *IfExpression:
+		a.applyExpr(n, "Condition", -1, &n.Condition)
+		a.applyBlock(n, "Then", &n.Then)
+		a.applyBlock(n, "Alt", &n.Alt)
+	case *FuncLiteral:
+		applyList(a, n, "Parameters", &n.Parameters)
+		for i := range n.ParamTypes {
+			a.applyType(n, "ParamTypes", i, &n.ParamTypes[i])
+		}
+		a.applyType(n, "ReturnType", -1, &n.ReturnType)
+		a.applyBlock(n, "Body", &n.Body)
+	case *CallExpression:
+		a.applyExpr(n, "Function", -1, &n.Function)
+		applyList(a, n, "Arguments", &n.Arguments)
+	case *ArrayLiteral:
+		applyList(a, n, "Elements", &n.Elements)
+	case *HashLiteral:
+		for i := range n.Pairs {
+			a.applyExpr(n, "Key", i, &n.Pairs[i].Key)
+			a.applyExpr(n, "Value", i, &n.Pairs[i].Value)
+		}
+	case *IndexExpression:
+		a.applyExpr(n, "Left", -1, &n.Left)
+		a.applyExpr(n, "Index", -1, &n.Index)
+	case *SliceExpression:
+		a.applyExpr(n, "Left", -1, &n.Left)
+		a.applyExpr(n, "Start", -1, &n.Start)
+		a.applyExpr(n, "End", -1, &n.End)
+	case *MemberExpression:
+		a.applyExpr(n, "Object", -1, &n.Object)
+		a.applyIdent(n, "Property", -1, &n.Property)
+
+	case *ArrayPattern:
+		applyList(a, n, "Elements", &n.Elements)
+		a.applyIdent(n, "Rest", -1, &n.Rest)
+	case *HashPattern:
+		for i := range n.Pairs {
+			a.applyExpr(n, "Key", i, &n.Pairs[i].Key)
+			pair := &n.Pairs[i]
+			a.apply(n, "Value", i, pair.Value, func(x Node) { pair.Value = x.(Pattern) })
+		}
+		a.applyIdent(n, "Rest", -1, &n.Rest)
+
+	case *ArrayType:
+		a.applyType(n, "Element", -1, &n.Element)
+	case *HashType:
+		a.applyType(n, "Key", -1, &n.Key)
+		a.applyType(n, "Value", -1, &n.Value)
+	case *RecordType:
+		for i := range n.Fields {
+			a.applyExpr(n, "Key", i, &n.Fields[i].Key)
+			a.applyType(n, "Type", i, &n.Fields[i].Type)
+		}
+	case *FuncType:
+		applyList(a, n, "Parameters", &n.Parameters)
+		a.applyType(n, "Return", -1, &n.Return)
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+}
+
+func (a *application) applyExpr(parent Node, name string, index int, field *Expression) {
+	if *field != nil {
+		a.apply(parent, name, index, *field, func(x Node) { *field = x.(Expression) })
+	}
+}
+
+func (a *application) applyType(parent Node, name string, index int, field *Type) {
+	if *field != nil {
+		a.apply(parent, name, index, *field, func(x Node) { *field = x.(Type) })
+	}
+}
+
+func (a *application) applyIdent(parent Node, name string, index int, field **Identifier) {
+	if *field != nil {
+		a.apply(parent, name, index, *field, func(x Node) { *field = x.(*Identifier) })
+	}
+}
+
+func (a *application) applyBlock(parent Node, name string, field **BlockStatement) {
+	if *field != nil {
+		a.apply(parent, name, -1, *field, func(x Node) { *field = x.(*BlockStatement) })
+	}
+}
+
+// applyList visits the elements of list, which may be edited through the
+// cursor while it is traversed.
+func applyList[T Node](a *application, parent Node, name string, list *[]T) {
+	editor := &listEditor{
+		delete: func(i int) { *list = slices.Delete(*list, i, i+1) },
+		insert: func(i int, n Node) { *list = slices.Insert(*list, i, n.(T)) },
+	}
+	for editor.index < len(*list) {
+		editor.step = 1
+		a.visit(Cursor{
+			parent: parent,
+			name:   name,
+			node:   (*list)[editor.index],
+			set:    func(x Node) { (*list)[editor.index] = x.(T) },
+			list:   editor,
+		})
+		editor.index += editor.step
+	}
+}
@@ -0,0 +1,117 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+// Type is a type annotation: a *NamedType, *ArrayType, *HashType,
+// *RecordType or *FuncType. Annotations are checked before evaluation and
+// ignored by the evaluator.
+type Type interface {
+	Node
+	typeNode()
+}
+
+// NamedType is a basic type such as int, string, bool, null or any.
+type NamedType struct {
+	Token token.Token
+	Name  string
+}
+
+func (nt *NamedType) typeNode() {}
+func (nt *NamedType) TokenLiteral() string {
+	return nt.Token.Literal
+}
+func (nt *NamedType) String() string {
+	return nt.Name
+}
+
+// ArrayType is an array whose elements have type Element, e.g. [int].
+type ArrayType struct {
+	Token   token.Token
+	Element Type
+}
+
+func (at *ArrayType) typeNode() {}
+func (at *ArrayType) TokenLiteral() string {
+	return at.Token.Literal
+}
+func (at *ArrayType) String() string {
+	return "[" + at.Element.String() + "]"
+}
+
+// HashType is a hash with keys of type Key and values of type Value, e.g.
+// {[string]: int}.
+type HashType struct {
+	Token token.Token
+	Key   Type
+	Value Type
+}
+
+func (ht *HashType) typeNode() {}
+func (ht *HashType) TokenLiteral() string {
+	return ht.Token.Literal
+}
+func (ht *HashType) String() string {
+	return "{[" + ht.Key.String() + "]: " + ht.Value.String() + "}"
+}
+
+// RecordField is a string key of a record type, written as an *Identifier
+// or a *StringLiteral, and the type of its value.
+type RecordField struct {
+	Key  Expression
+	Type Type
+}
+
+// KeyName returns the hash key of the field.
+func (field RecordField) KeyName() string {
+	if str, ok := field.Key.(*StringLiteral); ok {
+		return str.Value
+	}
+	return field.Key.String()
+}
+
+// RecordType is a hash with at least the given string keys, e.g.
+// {name: string, "zip code": int}. Other keys may be present.
+type RecordType struct {
+	Token  token.Token
+	Fields []RecordField
+}
+
+func (rt *RecordType) typeNode() {}
+func (rt *RecordType) TokenLiteral() string {
+	return rt.Token.Literal
+}
+func (rt *RecordType) String() string {
+	fields := []string{}
+	for _, field := range rt.Fields {
+		fields = append(fields, field.Key.String()+": "+field.Type.String())
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// FuncType is a function type, e.g. fnc(int, string): bool. Without Return
+// the function may return anything.
+type FuncType struct {
+	Token      token.Token
+	Parameters []Type
+	Return     Type
+}
+
+func (ft *FuncType) typeNode() {}
+func (ft *FuncType) TokenLiteral() string {
+	return ft.Token.Literal
+}
+func (ft *FuncType) String() string {
+	params := []string{}
+	for _, param := range ft.Parameters {
+		params = append(params, param.String())
+	}
+	out := "fnc(" + strings.Join(params, ", ") + ")"
+	if ft.Return != nil {
+		out += ": " + ft.Return.String()
+	}
+	return out
+}
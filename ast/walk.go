@@ -0,0 +1,160 @@
+package ast
+
+// A Visitor's Visit method is called by Walk for each node. If it returns a
+// non-nil visitor w, Walk visits the children of the node with w and then
+// calls w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first, source order.
+// Keys and values of hash literals and hash patterns are visited pair by
+// pair.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkList(v, n.Statements)
+	case *LetStatement:
+		if n.Pattern != nil {
+			Walk(v, n.Pattern)
+		} else if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		walkOptional(v, n.Type)
+		walkOptional(v, n.Value)
+	case *ReturnStatement:
+		walkOptional(v, n.ReturnValue)
+	case *ExpressionStatement:
+		walkOptional(v, n.Expression)
+	case *ReassignmentStatement:
+		Walk(v, n.Left)
+		walkOptional(v, n.Value)
+	case *WhileStatement:
+		Walk(v, n.Condition)
+		Walk(v, n.Body)
+	case *BlockStatement:
+		walkList(v, n.Statements)
+	case *ImportStatement:
+		Walk(v, n.Path)
+		if n.Alias != nil {
+			Walk(v, n.Alias)
+		}
+		for _, name := range n.Names {
+			Walk(v, name.Name)
+			if name.Alias != nil {
+				Walk(v, name.Alias)
+			}
+		}
+	case *ExportStatement:
+		walkList(v, n.Names)
+
+	case *Identifier, *IntegerLiteral, *StringLiteral, *Boolean:
+		// no children
+	case *InterpolatedString:
+		walkList(v, n.Parts)
+	case *PrefixExpression:
+		Walk(v, n.Right)
+	case *InfixExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *IfExpression:
+		Walk(v, n.Condition)
+		Walk(v, n.Then)
+		if n.Alt != nil {
+			Walk(v, n.Alt)
+		}
+	case *FuncLiteral:
+		walkList(v, n.Parameters)
+		for _, typ := range n.ParamTypes {
+			walkOptional(v, typ)
+		}
+		walkOptional(v, n.ReturnType)
+		Walk(v, n.Body)
+	case *CallExpression:
+		Walk(v, n.Function)
+		walkList(v, n.Arguments)
+	case *ArrayLiteral:
+		walkList(v, n.Elements)
+	case *HashLiteral:
+		for _, pair := range n.Pairs {
+			Walk(v, pair.Key)
+			Walk(v, pair.Value)
+		}
+	case *IndexExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Index)
+	case *SliceExpression:
+		Walk(v, n.Left)
+		walkOptional(v, n.Start)
+		walkOptional(v, n.End)
+	case *MemberExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Property)
+
+	case *ArrayPattern:
+		walkList(v, n.Elements)
+		if n.Rest != nil {
+			Walk(v, n.Rest)
+		}
+	case *HashPattern:
+		for _, pair := range n.Pairs {
+			Walk(v, pair.Key)
+			Walk(v, pair.Value)
+		}
+		if n.Rest != nil {
+			Walk(v, n.Rest)
+		}
+
+	case *NamedType:
+		// no children
+	case *ArrayType:
+		Walk(v, n.Element)
+	case *HashType:
+		Walk(v, n.Key)
+		Walk(v, n.Value)
+	case *RecordType:
+		for _, field := range n.Fields {
+			Walk(v, field.Key)
+			Walk(v, field.Type)
+		}
+	case *FuncType:
+		walkList(v, n.Parameters)
+		walkOptional(v, n.Return)
+	}
+
+	v.Visit(nil)
+}
+
+func walkList[T Node](v Visitor, list []T) {
+	for _, node := range list {
+		Walk(v, node)
+	}
+}
+
+// walkOptional walks node unless it is missing, which is the case for
+// example for the value of an x++ statement or an omitted annotation.
+func walkOptional[T Node](v Visitor, node T) {
+	if Node(node) != nil {
+		Walk(v, node)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node like Walk, calling f for each
+// node and f(nil) after a node's children. If f returns false the children
+// of the node are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
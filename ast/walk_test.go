@@ -0,0 +1,217 @@
+package ast_test
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+func TestInspect(t *testing.T) {
+	program := parse(t, `let {a, "b": [c]} = {"k": f(x)[1:], 2: -y}; z += m.n; while (i) { if (j) {} else { k++ } }`)
+
+	var visited []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node != nil {
+			visited = append(visited, strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."))
+		}
+		return true
+	})
+
+	expected := []string{
+		"Program",
+		"LetStatement", "HashPattern", "Identifier", "Identifier", "StringLiteral", "ArrayPattern", "Identifier",
+		"HashLiteral", "StringLiteral", "SliceExpression", "CallExpression", "Identifier", "Identifier", "IntegerLiteral",
+		"IntegerLiteral", "PrefixExpression", "Identifier",
+		"ReassignmentStatement", "Identifier", "MemberExpression", "Identifier", "Identifier",
+		"WhileStatement", "Identifier", "BlockStatement", "ExpressionStatement", "IfExpression", "Identifier",
+		"BlockStatement", "BlockStatement", "ReassignmentStatement", "Identifier",
+	}
+	if strings.Join(visited, " ") != strings.Join(expected, " ") {
+		t.Errorf("wrong traversal.\nexpected=%v\ngot=     %v", expected, visited)
+	}
+}
+
+func TestInspectSkipsChildren(t *testing.T) {
+	program := parse(t, "let f = fnc(a) { a + b }; f(c)")
+
+	var idents []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			idents = append(idents, ident.Value)
+		}
+		_, isFunc := node.(*ast.FuncLiteral)
+		return !isFunc
+	})
+	if strings.Join(idents, " ") != "f f c" {
+		t.Errorf("wrong identifiers. got=%v", idents)
+	}
+}
+
+type depthVisitor struct {
+	depth    int
+	maxDepth *int
+	closed   *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		*v.closed++
+		return nil
+	}
+	*v.maxDepth = max(*v.maxDepth, v.depth)
+	return depthVisitor{v.depth + 1, v.maxDepth, v.closed}
+}
+
+func TestWalk(t *testing.T) {
+	program := parse(t, "x = [1, [2, 3]];")
+
+	maxDepth, closed := 0, 0
+	ast.Walk(depthVisitor{maxDepth: &maxDepth, closed: &closed}, program)
+
+	// Program > ReassignmentStatement > ArrayLiteral > ArrayLiteral > IntegerLiteral
+	if maxDepth != 4 {
+		t.Errorf("wrong depth. expected=4 got=%d", maxDepth)
+	}
+	// One for each node, as no Visit returned nil.
+	if closed != 8 {
+		t.Errorf("wrong number of Visit(nil) calls. expected=8 got=%d", closed)
+	}
+}
+
+func TestApplyReplace(t *testing.T) {
+	program := parse(t, "let x = a; a = {a: a}; f(a, a[a:]);")
+
+	result := ast.Apply(program, func(c *ast.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Identifier); ok && ident.Value == "a" {
+			c.Replace(&ast.Identifier{Value: "b"})
+		}
+		return true
+	}, nil)
+
+	expected := "let x = b;\nb = {b: b};\nf(b, (b[b:]));"
+	if result.String() != expected {
+		t.Errorf("wrong program.\nexpected=%q\ngot=     %q", expected, result.String())
+	}
+}
+
+func TestApplyEditsLists(t *testing.T) {
+	program := parse(t, "puts(1); debug(2); puts(3); debug(4);")
+
+	ast.Apply(program, func(c *ast.Cursor) bool {
+		stmt, ok := c.Node().(*ast.ExpressionStatement)
+		if !ok || c.Name() != "Statements" {
+			return true
+		}
+		switch stmt.Expression.(*ast.CallExpression).Function.String() {
+		case "debug":
+			c.Delete()
+		case "puts":
+			c.InsertBefore(&ast.ExpressionStatement{Expression: &ast.Identifier{Value: fmt.Sprintf("before%d", c.Index())}})
+			c.InsertAfter(&ast.ExpressionStatement{Expression: &ast.Identifier{Value: "after"}})
+		}
+		return false
+	}, nil)
+
+	expected := "before0;\nputs(1);\nafter;\nbefore3;\nputs(3);\nafter;"
+	if program.String() != expected {
+		t.Errorf("wrong program.\nexpected=%q\ngot=     %q", expected, program.String())
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	program := parse(t, `h[k] = {"a": 1, "b": 2};`)
+
+	var got []string
+	ast.Apply(program, func(c *ast.Cursor) bool {
+		parent := "nil"
+		if c.Parent() != nil {
+			parent = strings.TrimPrefix(fmt.Sprintf("%T", c.Parent()), "*ast.")
+		}
+		got = append(got, fmt.Sprintf("%s.%s[%d]", parent, c.Name(), c.Index()))
+		return true
+	}, nil)
+
+	expected := []string{
+		"nil.[-1]",
+		"Program.Statements[0]",
+		"ReassignmentStatement.Left[-1]",
+		"IndexExpression.Left[-1]",
+		"IndexExpression.Index[-1]",
+		"ReassignmentStatement.Value[-1]",
+		"HashLiteral.Key[0]",
+		"HashLiteral.Value[0]",
+		"HashLiteral.Key[1]",
+		"HashLiteral.Value[1]",
+	}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("wrong cursors.\nexpected=%v\ngot=     %v", expected, got)
+	}
+}
+
+func TestApplyStopsAndReplacesRoot(t *testing.T) {
+	program := parse(t, "a; b; c;")
+
+	var seen []string
+	ast.Apply(program, nil, func(c *ast.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Identifier); ok {
+			seen = append(seen, ident.Value)
+			return ident.Value != "b"
+		}
+		return true
+	})
+	if strings.Join(seen, " ") != "a b" {
+		t.Errorf("Apply did not stop. saw=%v", seen)
+	}
+
+	replacement := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "r"}, Value: "r"}
+	result := ast.Apply(program, func(c *ast.Cursor) bool {
+		c.Replace(replacement)
+		return false
+	}, nil)
+	if result != replacement {
+		t.Errorf("root was not replaced. got=%v", result)
+	}
+}
+
+func TestApplyDeleteOutsideList(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected Delete on a single node to panic")
+		}
+	}()
+	ast.Apply(parse(t, "-x"), func(c *ast.Cursor) bool {
+		if _, ok := c.Node().(*ast.Identifier); ok {
+			c.Delete()
+		}
+		return true
+	}, nil)
+}
+
+// Walk and Apply must reach the same nodes in the same order.
+func TestWalkAndApplyAgree(t *testing.T) {
+	gen := &generator{rand: rand.New(rand.NewSource(2))}
+	for i := 0; i < 500; i++ {
+		program := gen.program()
+
+		var walked, applied []ast.Node
+		ast.Inspect(program, func(node ast.Node) bool {
+			if node != nil {
+				walked = append(walked, node)
+			}
+			return true
+		})
+		ast.Apply(program, func(c *ast.Cursor) bool {
+			applied = append(applied, c.Node())
+			return true
+		}, nil)
+
+		if !slices.Equal(walked, applied) {
+			t.Fatalf("program %d: Walk visited %d nodes, Apply %d:\n%s", i, len(walked), len(applied), program)
+		}
+	}
+}
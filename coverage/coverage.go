@@ -0,0 +1,260 @@
+// Package coverage measures which code of chimp scripts runs. A Recorder
+// counts the statements an evaluator runs and the way the conditions of if
+// expressions and while loops go. Its Profile relates the counts to the
+// source of the scripts and can be written as an lcov file or as an HTML
+// report.
+package coverage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/stdlib"
+)
+
+// pos is where a statement or branch starts in a file.
+type pos struct {
+	line, column int
+}
+
+func posOf(node ast.Node) pos {
+	tok := ast.Start(node)
+	return pos{tok.Line, tok.Column}
+}
+
+type counts struct {
+	stmts    map[pos]int
+	branches map[pos][2]int // times the condition was true and false
+}
+
+// Recorder records the coverage of the scripts an evaluator runs, leaving
+// out the standard library modules. Set it with Evaluator.SetCoverage.
+type Recorder struct {
+	files map[string]*counts
+}
+
+// New returns an empty recorder.
+func New() *Recorder {
+	return &Recorder{files: map[string]*counts{}}
+}
+
+func (r *Recorder) counts(file string) *counts {
+	c, ok := r.files[file]
+	if !ok {
+		c = &counts{stmts: map[pos]int{}, branches: map[pos][2]int{}}
+		r.files[file] = c
+	}
+	return c
+}
+
+// recorded reports whether the coverage of file is recorded: code that
+// is not from a file and standard library modules, which have no file on
+// disk, are not.
+func recorded(file string) bool {
+	return file != "" && !strings.HasPrefix(file, stdlib.Prefix)
+}
+
+// Statement implements evaluator.Coverage.
+func (r *Recorder) Statement(file string, stmt ast.Statement) {
+	if recorded(file) {
+		r.counts(file).stmts[posOf(stmt)]++
+	}
+}
+
+// Branch implements evaluator.Coverage.
+func (r *Recorder) Branch(file string, node ast.Node, cond bool) {
+	if !recorded(file) {
+		return
+	}
+	c := r.counts(file)
+	p := posOf(node)
+	taken := c.branches[p]
+	if cond {
+		taken[0]++
+	} else {
+		taken[1]++
+	}
+	c.branches[p] = taken
+}
+
+// Statement is a statement of a file and how often it ran.
+type Statement struct {
+	Line, Column int
+	Count        int
+}
+
+// Branch is an if expression or while loop and how often its condition
+// was true and false.
+type Branch struct {
+	Line, Column int
+	// Kind is "if" or "while".
+	Kind        string
+	True, False int
+}
+
+// File is the coverage of a script.
+type File struct {
+	Name string
+	// Lines is the source of the script.
+	Lines []string
+	// Statements and Branches are in the order they appear in the source.
+	Statements []Statement
+	Branches   []Branch
+}
+
+// Profile is the coverage of the scripts that ran.
+type Profile struct {
+	// Files are sorted by name.
+	Files []*File
+}
+
+// Profile reads and parses the scripts that ran and returns their
+// coverage.
+func (r *Recorder) Profile() (*Profile, error) {
+	prof := &Profile{}
+	for name, c := range r.files {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		p := parser.New(lexer.New(string(src)))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			return nil, fmt.Errorf("%s: %s", name, p.Errors()[0])
+		}
+
+		file := &File{Name: name, Lines: strings.Split(string(src), "\n")}
+		ast.Inspect(program, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.BlockStatement, *ast.ExportStatement:
+				// Blocks run as their statements, exports are not run.
+			case *ast.WhileStatement:
+				file.addBranch(node, "while", c)
+				file.addStatement(node, c)
+			case ast.Statement:
+				file.addStatement(node, c)
+			case *ast.IfExpression:
+				file.addBranch(node, "if", c)
+			}
+			return true
+		})
+		sort.Slice(file.Statements, func(i, j int) bool {
+			return before(file.Statements[i].Line, file.Statements[i].Column, file.Statements[j].Line, file.Statements[j].Column)
+		})
+		sort.Slice(file.Branches, func(i, j int) bool {
+			return before(file.Branches[i].Line, file.Branches[i].Column, file.Branches[j].Line, file.Branches[j].Column)
+		})
+		prof.Files = append(prof.Files, file)
+	}
+	sort.Slice(prof.Files, func(i, j int) bool { return prof.Files[i].Name < prof.Files[j].Name })
+	return prof, nil
+}
+
+func before(line1, col1, line2, col2 int) bool {
+	return line1 < line2 || line1 == line2 && col1 < col2
+}
+
+func (f *File) addStatement(stmt ast.Node, c *counts) {
+	p := posOf(stmt)
+	f.Statements = append(f.Statements, Statement{Line: p.line, Column: p.column, Count: c.stmts[p]})
+}
+
+func (f *File) addBranch(node ast.Node, kind string, c *counts) {
+	p := posOf(node)
+	taken := c.branches[p]
+	f.Branches = append(f.Branches, Branch{Line: p.line, Column: p.column, Kind: kind, True: taken[0], False: taken[1]})
+}
+
+// StatementsCovered returns how many statements ran and how many there are.
+func (prof *Profile) StatementsCovered() (covered, total int) {
+	for _, f := range prof.Files {
+		for _, stmt := range f.Statements {
+			if stmt.Count > 0 {
+				covered++
+			}
+		}
+		total += len(f.Statements)
+	}
+	return covered, total
+}
+
+// BranchesCovered returns how many branches were taken and how many there
+// are. An if or while has two: its condition being true and being false.
+func (prof *Profile) BranchesCovered() (covered, total int) {
+	for _, f := range prof.Files {
+		for _, branch := range f.Branches {
+			if branch.True > 0 {
+				covered++
+			}
+			if branch.False > 0 {
+				covered++
+			}
+		}
+		total += 2 * len(f.Branches)
+	}
+	return covered, total
+}
+
+// Percent returns covered as a percentage of total, which is 100 if there
+// is nothing to cover.
+func Percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(covered) / float64(total)
+}
+
+// Line is the coverage of a line of a file.
+type Line struct {
+	// Statements is the number of statements starting on the line and Run
+	// the number of them that ran.
+	Statements, Run int
+	// Count is how often the line ran, the most any of its statements did.
+	Count int
+	// Branches is the number of branches on the line and Taken the number
+	// of them that were taken.
+	Branches, Taken int
+}
+
+// Covered reports whether all statements and branches of the line ran.
+func (l Line) Covered() bool {
+	return l.Run == l.Statements && l.Taken == l.Branches
+}
+
+// LineCoverage returns the coverage of the lines of f that statements
+// start on, by line number.
+func (f *File) LineCoverage() map[int]*Line {
+	lines := map[int]*Line{}
+	line := func(n int) *Line {
+		l, ok := lines[n]
+		if !ok {
+			l = &Line{}
+			lines[n] = l
+		}
+		return l
+	}
+	for _, stmt := range f.Statements {
+		l := line(stmt.Line)
+		l.Statements++
+		if stmt.Count > 0 {
+			l.Run++
+		}
+		l.Count = max(l.Count, stmt.Count)
+	}
+	for _, branch := range f.Branches {
+		l := line(branch.Line)
+		l.Branches += 2
+		if branch.True > 0 {
+			l.Taken++
+		}
+		if branch.False > 0 {
+			l.Taken++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,195 @@
+package coverage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+var scripts = map[string]string{
+	"main.chimp": `import { classify } from "./lib";
+let i = 0;
+while (i < 2) {
+	puts(classify(i));
+	i++;
+}
+export { i };`,
+	"lib.chimp": `let classify = fnc(n) {
+	if (n < 0) {
+		"negative"
+	} else {
+		"positive"
+	}
+};
+let unused = fnc() { puts("never") };
+export { classify };`,
+}
+
+// record runs main.chimp of scripts and returns its coverage.
+func record(t *testing.T) (*Profile, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := New()
+	eval := evaluator.NewEval()
+	eval.SetOutput(&bytes.Buffer{})
+	eval.SetCoverage(r)
+	if err, ok := eval.RunFile(filepath.Join(dir, "main.chimp")).(*object.Error); ok {
+		t.Fatalf("unexpected error: %s", err.Message)
+	}
+	prof, err := r.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prof, dir
+}
+
+func TestProfile(t *testing.T) {
+	prof, dir := record(t)
+	if len(prof.Files) != 2 || prof.Files[0].Name != filepath.Join(dir, "lib.chimp") || prof.Files[1].Name != filepath.Join(dir, "main.chimp") {
+		t.Fatalf("wrong files %v", prof.Files)
+	}
+	lib, main := prof.Files[0], prof.Files[1]
+
+	tests := []struct {
+		file     *File
+		expected []Statement
+	}{
+		{lib, []Statement{{1, 1, 1}, {2, 2, 2}, {3, 3, 0}, {5, 3, 2}, {8, 1, 1}, {8, 22, 0}}},
+		{main, []Statement{{1, 1, 1}, {2, 1, 1}, {3, 1, 1}, {4, 2, 2}, {5, 2, 2}}},
+	}
+	for _, tt := range tests {
+		if len(tt.file.Statements) != len(tt.expected) {
+			t.Errorf("%s: wrong statements %v", tt.file.Name, tt.file.Statements)
+			continue
+		}
+		for i, stmt := range tt.expected {
+			if tt.file.Statements[i] != stmt {
+				t.Errorf("%s: statements[%d] is %v, expected %v", tt.file.Name, i, tt.file.Statements[i], stmt)
+			}
+		}
+	}
+
+	branches := []Branch{lib.Branches[0], main.Branches[0]}
+	expected := []Branch{{2, 2, "if", 0, 2}, {3, 1, "while", 2, 1}}
+	if len(lib.Branches) != 1 || len(main.Branches) != 1 || branches[0] != expected[0] || branches[1] != expected[1] {
+		t.Errorf("wrong branches %v and %v", lib.Branches, main.Branches)
+	}
+
+	if covered, total := prof.StatementsCovered(); covered != 9 || total != 11 {
+		t.Errorf("%d of %d statements covered", covered, total)
+	}
+	if covered, total := prof.BranchesCovered(); covered != 3 || total != 4 {
+		t.Errorf("%d of %d branches covered", covered, total)
+	}
+}
+
+func TestProfileSkipsStdlib(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.chimp")
+	src := "import \"std/math\" as m;\nputs(m.max(m.abs(-3), 2));"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	eval := evaluator.NewEval()
+	eval.SetOutput(&bytes.Buffer{})
+	eval.SetCoverage(r)
+	if err, ok := eval.RunFile(path).(*object.Error); ok {
+		t.Fatalf("unexpected error: %s", err.Message)
+	}
+	prof, err := r.Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prof.Files) != 1 || prof.Files[0].Name != path {
+		t.Fatalf("wrong files %v", prof.Files)
+	}
+	if covered, total := prof.StatementsCovered(); covered != 2 || total != 2 {
+		t.Errorf("%d of %d statements covered", covered, total)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		covered, total int
+		expected       float64
+	}{
+		{1, 4, 25},
+		{3, 3, 100},
+		{0, 0, 100},
+	}
+	for _, tt := range tests {
+		if got := Percent(tt.covered, tt.total); got != tt.expected {
+			t.Errorf("Percent(%d, %d) = %v, expected %v", tt.covered, tt.total, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteLcov(t *testing.T) {
+	prof, dir := record(t)
+	var out bytes.Buffer
+	if err := prof.WriteLcov(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `TN:
+SF:` + filepath.Join(dir, "lib.chimp") + `
+BRDA:2,0,0,0
+BRDA:2,0,1,2
+BRF:2
+BRH:1
+DA:1,1
+DA:2,2
+DA:3,0
+DA:5,2
+DA:8,1
+LF:5
+LH:4
+end_of_record
+SF:` + filepath.Join(dir, "main.chimp") + `
+BRDA:3,0,0,2
+BRDA:3,0,1,1
+BRF:2
+BRH:2
+DA:1,1
+DA:2,1
+DA:3,1
+DA:4,2
+DA:5,2
+LF:5
+LH:5
+end_of_record
+`
+	if out.String() != expected {
+		t.Errorf("wrong lcov.\nexpected=%s\ngot=%s", expected, out.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	prof, _ := record(t)
+	var out bytes.Buffer
+	if err := prof.WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`<p>81.8% of statements, 75.0% of branches</p>`,
+		`<tr class="partial"><td class="number">2</td><td class="count">2</td><td class="code" title="1/1 statements, 1/2 branches">	if (n &lt; 0) {</td></tr>`,
+		`<tr class="uncovered"><td class="number">3</td><td class="count">0</td>`,
+		`<tr class=""><td class="number">4</td><td class="count"></td><td class="code" title="">	} else {</td></tr>`,
+		`<tr class="covered"><td class="number">5</td><td class="count">2</td>`,
+		`<tr class="partial"><td class="number">8</td>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in the report", s)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>chimp coverage</title>
+<style>
+body { font-family: sans-serif; }
+table.source { border-collapse: collapse; font-family: monospace; }
+table.source td { padding: 0 0.5em; white-space: pre; }
+td.number, td.count { text-align: right; color: #888; }
+tr.covered td.code { background: #d7f5d7; }
+tr.partial td.code { background: #fbf1c7; }
+tr.uncovered td.code { background: #f8d4d4; }
+</style>
+</head>
+<body>
+<h1>Coverage</h1>
+<p>{{.Statements}} of statements, {{.Branches}} of branches</p>
+<ul>
+{{- range $i, $f := .Files}}
+<li><a href="#file{{$i}}">{{$f.Name}}</a>: {{$f.Statements}} of statements, {{$f.Branches}} of branches</li>
+{{- end}}
+</ul>
+{{- range $i, $f := .Files}}
+<h2 id="file{{$i}}">{{$f.Name}}</h2>
+<table class="source">
+{{- range $f.Lines}}
+<tr class="{{.Class}}"><td class="number">{{.Number}}</td><td class="count">{{.Count}}</td><td class="code" title="{{.Title}}">{{.Code}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlLine struct {
+	Number int
+	Count  string
+	Class  string
+	Title  string
+	Code   string
+}
+
+type htmlFile struct {
+	Name                 string
+	Statements, Branches string
+	Lines                []htmlLine
+}
+
+func percentOf(covered, total int) string {
+	return fmt.Sprintf("%.1f%%", Percent(covered, total))
+}
+
+// WriteHTML writes a report that shows the source of the scripts with the
+// lines that ran, that partly ran and that did not run highlighted.
+func (prof *Profile) WriteHTML(w io.Writer) error {
+	data := struct {
+		Statements, Branches string
+		Files                []htmlFile
+	}{
+		Statements: percentOf(prof.StatementsCovered()),
+		Branches:   percentOf(prof.BranchesCovered()),
+	}
+	for _, f := range prof.Files {
+		single := &Profile{Files: []*File{f}}
+		hf := htmlFile{
+			Name:       f.Name,
+			Statements: percentOf(single.StatementsCovered()),
+			Branches:   percentOf(single.BranchesCovered()),
+		}
+		lines := f.LineCoverage()
+		for i, code := range f.Lines {
+			hl := htmlLine{Number: i + 1, Code: code}
+			if l, ok := lines[i+1]; ok {
+				hl.Count = fmt.Sprint(l.Count)
+				hl.Title = fmt.Sprintf("%d/%d statements, %d/%d branches", l.Run, l.Statements, l.Taken, l.Branches)
+				switch {
+				case l.Covered():
+					hl.Class = "covered"
+				case l.Run == 0 && l.Taken == 0:
+					hl.Class = "uncovered"
+				default:
+					hl.Class = "partial"
+				}
+			}
+			hf.Lines = append(hf.Lines, hl)
+		}
+		data.Files = append(data.Files, hf)
+	}
+	return htmlReport.Execute(w, data)
+}
@@ -0,0 +1,52 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// WriteLcov writes the profile in the lcov tracefile format. Each if and
+// while is a block with two branches, the condition being true and false.
+func (prof *Profile) WriteLcov(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "TN:")
+	for _, f := range prof.Files {
+		fmt.Fprintf(bw, "SF:%s\n", f.Name)
+
+		hit := 0
+		for block, branch := range f.Branches {
+			for i, taken := range []int{branch.True, branch.False} {
+				count := "-"
+				if branch.True+branch.False > 0 {
+					count = fmt.Sprint(taken)
+				}
+				if taken > 0 {
+					hit++
+				}
+				fmt.Fprintf(bw, "BRDA:%d,%d,%d,%s\n", branch.Line, block, i, count)
+			}
+		}
+		fmt.Fprintf(bw, "BRF:%d\nBRH:%d\n", 2*len(f.Branches), hit)
+
+		lines := f.LineCoverage()
+		numbers := []int{}
+		for n, l := range lines {
+			if l.Statements > 0 {
+				numbers = append(numbers, n)
+			}
+		}
+		sort.Ints(numbers)
+		hit = 0
+		for _, n := range numbers {
+			if lines[n].Count > 0 {
+				hit++
+			}
+			fmt.Fprintf(bw, "DA:%d,%d\n", n, lines[n].Count)
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\n", len(numbers), hit)
+		fmt.Fprintln(bw, "end_of_record")
+	}
+	return bw.Flush()
+}
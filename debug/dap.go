@@ -0,0 +1,485 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+// dapMessage is a Debug Adapter Protocol request, response or event.
+type dapMessage struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Command    string          `json:"command,omitempty"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	RequestSeq int             `json:"request_seq,omitempty"`
+	Success    bool            `json:"success,omitempty"`
+	Message    string          `json:"message,omitempty"`
+	Event      string          `json:"event,omitempty"`
+	Body       any             `json:"body,omitempty"`
+}
+
+// readDAP reads a message framed by a Content-Length header.
+func readDAP(r *bufio.Reader) (*dapMessage, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	msg := &dapMessage{}
+	return msg, json.Unmarshal(body, msg)
+}
+
+// writeDAP writes msg framed by a Content-Length header.
+func writeDAP(w io.Writer, msg *dapMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// The only thread of a chimp program.
+const threadID = 1
+
+type source struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func sourceOf(path string) *source {
+	if path == "" {
+		return nil
+	}
+	return &source{Name: filepath.Base(path), Path: path}
+}
+
+type variable struct {
+	Name               string `json:"name"`
+	Value              string `json:"value"`
+	Type               string `json:"type,omitempty"`
+	VariablesReference int    `json:"variablesReference"`
+}
+
+// heapScope is the variables reference target listing the heap.
+type heapScope struct{}
+
+// dapServer adapts a Debugger to the Debug Adapter Protocol. Requests are
+// handled in the order they arrive while the events of the program are
+// forwarded by another goroutine.
+type dapServer struct {
+	out io.Writer
+	d   *Debugger
+
+	mu  sync.Mutex // guards out, seq and refs
+	seq int
+	// refs are the targets of variables references, which are indexes
+	// into refs plus one. They are valid while the program is stopped.
+	refs []any
+
+	program     string
+	stopOnEntry bool
+	launched    bool
+	configured  bool
+	started     bool
+	exited      chan struct{}
+}
+
+// ServeDAP runs a debug adapter that reads requests from in and writes
+// responses and events to out until the client disconnects. The program to
+// debug is named by the launch request. Its output is sent as output
+// events.
+func ServeDAP(in io.Reader, out io.Writer) error {
+	s := &dapServer{out: out, exited: make(chan struct{})}
+	eval := evaluator.NewEval()
+	eval.SetOutput(writerFunc(func(p []byte) (int, error) {
+		s.event("output", map[string]any{"category": "stdout", "output": string(p)})
+		return len(p), nil
+	}))
+	s.d = New(eval)
+
+	r := bufio.NewReader(in)
+	for {
+		msg, err := readDAP(r)
+		if err == io.EOF {
+			s.terminate()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Type != "request" {
+			continue
+		}
+		body, err := s.handle(msg)
+		if err != nil {
+			s.respond(msg, nil, err)
+			continue
+		}
+		s.respond(msg, body, nil)
+		if msg.Command == "initialize" {
+			s.event("initialized", nil)
+		}
+		if msg.Command == "disconnect" {
+			return nil
+		}
+		if (msg.Command == "launch" || msg.Command == "configurationDone") && s.launched && s.configured && !s.started {
+			s.start()
+		}
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func (s *dapServer) send(msg *dapMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	msg.Seq = s.seq
+	writeDAP(s.out, msg)
+}
+
+func (s *dapServer) respond(req *dapMessage, body any, err error) {
+	msg := &dapMessage{Type: "response", RequestSeq: req.Seq, Command: req.Command, Success: err == nil, Body: body}
+	if err != nil {
+		msg.Message = err.Error()
+	}
+	s.send(msg)
+}
+
+func (s *dapServer) event(event string, body any) {
+	s.send(&dapMessage{Type: "event", Event: event, Body: body})
+}
+
+// start runs the program and forwards its events.
+func (s *dapServer) start() {
+	s.started = true
+	s.d.Run(s.program, s.stopOnEntry)
+	go func() {
+		defer close(s.exited)
+		for ev := range s.d.Events() {
+			if ev.Exited {
+				exitCode := 0
+				if err, ok := ev.Result.(*object.Error); ok {
+					exitCode = 1
+					if err.Message != ErrTerminated.Error() {
+						s.event("output", map[string]any{"category": "stderr", "output": err.Inspect() + "\n"})
+					}
+				}
+				s.event("exited", map[string]any{"exitCode": exitCode})
+				s.event("terminated", nil)
+				return
+			}
+			body := map[string]any{"reason": string(ev.Stop.Reason), "threadId": threadID, "allThreadsStopped": true}
+			if ev.Stop.Breakpoint != nil {
+				body["hitBreakpointIds"] = []int{ev.Stop.Breakpoint.ID}
+			}
+			if ev.Stop.ConditionErr != nil {
+				body["text"] = ev.Stop.ConditionErr.Error()
+			}
+			s.event("stopped", body)
+		}
+	}()
+}
+
+// terminate stops the program if it runs and waits for it to exit.
+func (s *dapServer) terminate() {
+	if !s.started {
+		return
+	}
+	s.d.Terminate()
+	<-s.exited
+}
+
+func decodeArgs[T any](msg *dapMessage) (T, error) {
+	var args T
+	if len(msg.Arguments) == 0 {
+		return args, nil
+	}
+	return args, json.Unmarshal(msg.Arguments, &args)
+}
+
+func (s *dapServer) handle(msg *dapMessage) (any, error) {
+	switch msg.Command {
+	case "initialize":
+		return map[string]any{
+			"supportsConfigurationDoneRequest": true,
+			"supportsConditionalBreakpoints":   true,
+			"supportsEvaluateForHovers":        true,
+			"supportsTerminateRequest":         true,
+		}, nil
+	case "launch":
+		return s.launch(msg)
+	case "configurationDone":
+		s.configured = true
+		return nil, nil
+	case "setBreakpoints":
+		return s.setBreakpoints(msg)
+	case "threads":
+		return map[string]any{"threads": []map[string]any{{"id": threadID, "name": "main"}}}, nil
+	case "stackTrace":
+		return s.stackTrace()
+	case "scopes":
+		return s.scopes(msg)
+	case "variables":
+		return s.variables(msg)
+	case "evaluate":
+		return s.evaluate(msg)
+	case "continue":
+		s.resume(s.d.Continue)
+		return map[string]any{"allThreadsContinued": true}, nil
+	case "next":
+		s.resume(s.d.StepOver)
+		return nil, nil
+	case "stepIn":
+		s.resume(s.d.StepIn)
+		return nil, nil
+	case "stepOut":
+		s.resume(s.d.StepOut)
+		return nil, nil
+	case "pause":
+		s.d.Pause()
+		return nil, nil
+	case "terminate", "disconnect":
+		s.terminate()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported request %s", msg.Command)
+}
+
+func (s *dapServer) launch(msg *dapMessage) (any, error) {
+	args, err := decodeArgs[struct {
+		Program     string `json:"program"`
+		StopOnEntry bool   `json:"stopOnEntry"`
+	}](msg)
+	if err != nil {
+		return nil, err
+	}
+	if args.Program == "" {
+		return nil, errors.New("launch needs a program")
+	}
+	s.program, err = filepath.Abs(args.Program)
+	if err != nil {
+		return nil, err
+	}
+	s.stopOnEntry = args.StopOnEntry
+	s.launched = true
+	return nil, nil
+}
+
+// resume drops the variables references of the stop and runs proceed.
+func (s *dapServer) resume(proceed func()) {
+	s.mu.Lock()
+	s.refs = nil
+	s.mu.Unlock()
+	proceed()
+}
+
+func (s *dapServer) ref(target any) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs = append(s.refs, target)
+	return len(s.refs)
+}
+
+func (s *dapServer) target(ref int) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ref < 1 || ref > len(s.refs) {
+		return nil
+	}
+	return s.refs[ref-1]
+}
+
+func (s *dapServer) setBreakpoints(msg *dapMessage) (any, error) {
+	args, err := decodeArgs[struct {
+		Source      source `json:"source"`
+		Breakpoints []struct {
+			Line      int    `json:"line"`
+			Condition string `json:"condition"`
+		} `json:"breakpoints"`
+	}](msg)
+	if err != nil {
+		return nil, err
+	}
+	file, err := filepath.Abs(args.Source.Path)
+	if err != nil {
+		return nil, err
+	}
+	s.d.ClearBreakpoints(file)
+	set := []map[string]any{}
+	for _, bp := range args.Breakpoints {
+		b, err := s.d.SetBreakpoint(file, bp.Line, bp.Condition)
+		if err != nil {
+			set = append(set, map[string]any{"verified": false, "line": bp.Line, "message": err.Error(), "source": sourceOf(file)})
+			continue
+		}
+		set = append(set, map[string]any{"id": b.ID, "verified": true, "line": b.Line, "source": sourceOf(file)})
+	}
+	return map[string]any{"breakpoints": set}, nil
+}
+
+// stopped returns the stop of the program, which requests about frames and
+// variables need.
+func (s *dapServer) stopped() (*Stop, error) {
+	stop := s.d.Stopped()
+	if stop == nil {
+		return nil, errors.New("the program is not stopped")
+	}
+	return stop, nil
+}
+
+// Frame IDs are indexes into Stop.Frames plus one.
+
+func (s *dapServer) stackTrace() (any, error) {
+	stop, err := s.stopped()
+	if err != nil {
+		return nil, err
+	}
+	frames := []map[string]any{}
+	for i := len(stop.Frames) - 1; i >= 0; i-- {
+		frame := stop.Frames[i]
+		f := map[string]any{"id": i + 1, "name": frame.Name, "line": frameLine(frame), "column": 1}
+		if src := sourceOf(frame.File); src != nil {
+			f["source"] = src
+		}
+		frames = append(frames, f)
+	}
+	return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+}
+
+func (s *dapServer) frame(id int) (evaluator.Frame, error) {
+	stop, err := s.stopped()
+	if err != nil {
+		return evaluator.Frame{}, err
+	}
+	if id < 1 || id > len(stop.Frames) {
+		return evaluator.Frame{}, fmt.Errorf("no frame %d", id)
+	}
+	return stop.Frames[id-1], nil
+}
+
+// scopes lists the environment chain of a frame, the innermost first, and
+// the heap.
+func (s *dapServer) scopes(msg *dapMessage) (any, error) {
+	args, err := decodeArgs[struct {
+		FrameID int `json:"frameId"`
+	}](msg)
+	if err != nil {
+		return nil, err
+	}
+	frame, err := s.frame(args.FrameID)
+	if err != nil {
+		return nil, err
+	}
+	scopes := []map[string]any{}
+	depth := 0
+	for env := frame.Env; env != nil; env = env.Outer {
+		name := "Locals"
+		switch {
+		case env.Outer == nil:
+			name = "Globals"
+		case depth > 0:
+			name = fmt.Sprintf("Enclosing %d", depth)
+		}
+		scopes = append(scopes, map[string]any{"name": name, "variablesReference": s.ref(env), "expensive": false})
+		depth++
+	}
+	scopes = append(scopes, map[string]any{"name": "Heap", "variablesReference": s.ref(heapScope{}), "expensive": false})
+	return map[string]any{"scopes": scopes}, nil
+}
+
+func (s *dapServer) variables(msg *dapMessage) (any, error) {
+	args, err := decodeArgs[struct {
+		VariablesReference int `json:"variablesReference"`
+	}](msg)
+	if err != nil {
+		return nil, err
+	}
+	vars := []variable{}
+	switch target := s.target(args.VariablesReference).(type) {
+	case *object.Environment:
+		for _, v := range Variables(target) {
+			vars = append(vars, s.variable(v.Name, v.Value))
+		}
+	case heapScope:
+		for _, cell := range s.d.Heap() {
+			vars = append(vars, s.variable(fmt.Sprintf("0x%x", cell.Address), cell.Value))
+		}
+	case *object.Array:
+		for i, elem := range target.Elements {
+			vars = append(vars, s.variable(strconv.Itoa(i), elem))
+		}
+	case *object.Hash:
+		for _, pair := range target.Pairs() {
+			vars = append(vars, s.variable(inspect(pair.Key), pair.Value))
+		}
+	case *object.Pointer:
+		if cell, ok := s.d.eval.Heap[target.Value]; ok {
+			vars = append(vars, s.variable("*"+target.Inspect(), cell.Object))
+		}
+	default:
+		return nil, fmt.Errorf("unknown variables reference %d", args.VariablesReference)
+	}
+	return map[string]any{"variables": vars}, nil
+}
+
+// variable describes val, which can be expanded if it has parts.
+func (s *dapServer) variable(name string, val object.Object) variable {
+	v := variable{Name: name, Value: inspect(val)}
+	if val == nil {
+		return v
+	}
+	v.Type = string(val.Type())
+	switch val.(type) {
+	case *object.Array, *object.Hash, *object.Pointer:
+		v.VariablesReference = s.ref(val)
+	}
+	return v
+}
+
+func (s *dapServer) evaluate(msg *dapMessage) (any, error) {
+	args, err := decodeArgs[struct {
+		Expression string `json:"expression"`
+		FrameID    int    `json:"frameId"`
+	}](msg)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := s.stopped()
+	if err != nil {
+		return nil, err
+	}
+	frame := len(stop.Frames) - 1
+	if args.FrameID != 0 {
+		frame = args.FrameID - 1
+	}
+	val, err := s.d.Evaluate(frame, args.Expression)
+	if err != nil {
+		return nil, err
+	}
+	v := s.variable("", val)
+	return map[string]any{"result": v.Value, "type": v.Type, "variablesReference": v.VariablesReference}, nil
+}
@@ -0,0 +1,271 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// dapClient talks to an adapter running in the same process. Like the
+// language server tests, its requests are written by a separate goroutine
+// so the adapter can send events at any time.
+type dapClient struct {
+	t      *testing.T
+	in     chan *dapMessage
+	out    *bufio.Reader
+	seq    int
+	events []*dapMessage
+	output []string // of the program, in the events waitEvent dropped
+	done   chan error
+}
+
+func newDAPClient(t *testing.T) *dapClient {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &dapClient{t: t, in: make(chan *dapMessage, 100), out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		err := ServeDAP(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	go func() {
+		for msg := range c.in {
+			if writeDAP(inW, msg) != nil {
+				break
+			}
+		}
+		inW.Close()
+	}()
+	t.Cleanup(func() { close(c.in) })
+	return c
+}
+
+func (c *dapClient) read() *dapMessage {
+	c.t.Helper()
+	msg, err := readDAP(c.out)
+	if err != nil {
+		c.t.Fatalf("read: %s", err)
+	}
+	return msg
+}
+
+// call sends a request and returns its response. Events that arrive in the
+// meantime are collected.
+func (c *dapClient) call(command string, args any) *dapMessage {
+	c.t.Helper()
+	c.seq++
+	data, _ := json.Marshal(args)
+	c.in <- &dapMessage{Seq: c.seq, Type: "request", Command: command, Arguments: data}
+	for {
+		msg := c.read()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+		if msg.RequestSeq != c.seq {
+			c.t.Fatalf("response to %d, expected %d", msg.RequestSeq, c.seq)
+		}
+		return msg
+	}
+}
+
+// body calls command, fails unless it succeeds and decodes the body into v.
+func (c *dapClient) body(command string, args, v any) {
+	c.t.Helper()
+	resp := c.call(command, args)
+	if !resp.Success {
+		c.t.Fatalf("%s failed: %s", command, resp.Message)
+	}
+	if v != nil {
+		data, _ := json.Marshal(resp.Body)
+		if err := json.Unmarshal(data, v); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+}
+
+// waitEvent returns the first event named event, reading more messages if
+// it did not arrive yet. Earlier events are dropped.
+func (c *dapClient) waitEvent(event string) map[string]any {
+	c.t.Helper()
+	for {
+		for len(c.events) > 0 {
+			msg := c.events[0]
+			c.events = c.events[1:]
+			if msg.Event == event {
+				body, _ := msg.Body.(map[string]any)
+				return body
+			}
+			if msg.Event == "output" {
+				c.output = append(c.output, msg.Body.(map[string]any)["output"].(string))
+			}
+		}
+		c.events = append(c.events, c.read())
+	}
+}
+
+type stackFrame struct {
+	ID     int
+	Name   string
+	Line   int
+	Source struct{ Path string }
+}
+
+type dapVariable struct {
+	Name               string
+	Value              string
+	VariablesReference int
+}
+
+func TestDAP(t *testing.T) {
+	path := writeScript(t, script)
+	c := newDAPClient(t)
+
+	var caps map[string]bool
+	c.body("initialize", map[string]any{"adapterID": "chimp"}, &caps)
+	if !caps["supportsConditionalBreakpoints"] || !caps["supportsConfigurationDoneRequest"] {
+		t.Errorf("missing capabilities %v", caps)
+	}
+	c.waitEvent("initialized")
+
+	c.body("launch", map[string]any{"program": path}, nil)
+	var bps struct {
+		Breakpoints []struct {
+			ID, Line int
+			Verified bool
+		}
+	}
+	c.body("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": path},
+		"breakpoints": []map[string]any{{"line": 10}, {"line": 99}},
+	}, &bps)
+	if len(bps.Breakpoints) != 2 || !bps.Breakpoints[0].Verified || bps.Breakpoints[0].Line != 11 ||
+		bps.Breakpoints[1].Verified || bps.Breakpoints[1].Line != 99 {
+		t.Fatalf("wrong breakpoints %v", bps)
+	}
+	c.body("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": path},
+		"breakpoints": []map[string]any{{"line": 2, "condition": "b == 2"}},
+	}, &bps)
+	if len(bps.Breakpoints) != 1 || !bps.Breakpoints[0].Verified || bps.Breakpoints[0].Line != 2 {
+		t.Fatalf("wrong breakpoints %v", bps)
+	}
+	c.body("configurationDone", nil, nil)
+
+	stopped := c.waitEvent("stopped")
+	if stopped["reason"] != "breakpoint" {
+		t.Errorf("wrong stop %v", stopped)
+	}
+	var threads struct{ Threads []struct{ ID int } }
+	c.body("threads", nil, &threads)
+	if len(threads.Threads) != 1 {
+		t.Errorf("wrong threads %v", threads)
+	}
+
+	var trace struct{ StackFrames []stackFrame }
+	c.body("stackTrace", map[string]any{"threadId": 1}, &trace)
+	if len(trace.StackFrames) != 2 {
+		t.Fatalf("wrong stack %v", trace)
+	}
+	top, main := trace.StackFrames[0], trace.StackFrames[1]
+	if top.Name != "add" || top.Line != 2 || top.Source.Path != path || main.Name != "main.chimp" || main.Line != 8 {
+		t.Errorf("wrong stack %v", trace)
+	}
+
+	var scopes struct {
+		Scopes []struct {
+			Name               string
+			VariablesReference int
+		}
+	}
+	c.body("scopes", map[string]any{"frameId": top.ID}, &scopes)
+	names := []string{}
+	for _, scope := range scopes.Scopes {
+		names = append(names, scope.Name)
+	}
+	if strings.Join(names, ",") != "Locals,Globals,Heap" {
+		t.Errorf("wrong scopes %v", names)
+	}
+	var vars struct{ Variables []dapVariable }
+	c.body("variables", map[string]any{"variablesReference": scopes.Scopes[0].VariablesReference}, &vars)
+	if len(vars.Variables) != 2 || vars.Variables[0].Name != "a" || vars.Variables[0].Value != "1" || vars.Variables[1].Value != "2" {
+		t.Errorf("wrong locals %v", vars)
+	}
+
+	var result struct{ Result string }
+	c.body("evaluate", map[string]any{"expression": "total * 10", "frameId": main.ID}, &result)
+	if result.Result != "10" {
+		t.Errorf("wrong evaluation %q", result.Result)
+	}
+	if resp := c.call("evaluate", map[string]any{"expression": "nope", "frameId": top.ID}); resp.Success {
+		t.Errorf("expected evaluating an unknown name to fail")
+	}
+
+	c.body("next", map[string]any{"threadId": 1}, nil)
+	if stopped := c.waitEvent("stopped"); stopped["reason"] != "step" {
+		t.Errorf("wrong stop %v", stopped)
+	}
+	c.body("stepOut", map[string]any{"threadId": 1}, nil)
+	c.waitEvent("stopped")
+	c.body("stackTrace", map[string]any{"threadId": 1}, &trace)
+	if len(trace.StackFrames) != 1 || trace.StackFrames[0].Line != 9 {
+		t.Errorf("wrong stack after stepping out %v", trace)
+	}
+
+	c.body("continue", map[string]any{"threadId": 1}, nil)
+	exited := c.waitEvent("exited")
+	if exited["exitCode"] != 0.0 {
+		t.Errorf("wrong exit %v", exited)
+	}
+	if strings.Join(c.output, "") != "3\n" {
+		t.Errorf("wrong output %q", c.output)
+	}
+	c.waitEvent("terminated")
+	c.body("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("adapter failed: %s", err)
+	}
+}
+
+func TestDAPHeapAndDisconnect(t *testing.T) {
+	path := writeScript(t, `let p = &[1, [2]];
+puts(*p);
+`)
+	c := newDAPClient(t)
+	c.body("initialize", nil, nil)
+	c.body("launch", map[string]any{"program": path, "stopOnEntry": true}, nil)
+	if resp := c.call("stackTrace", nil); resp.Success {
+		t.Errorf("expected a stack trace before the program started to fail")
+	}
+	c.body("configurationDone", nil, nil)
+	if stopped := c.waitEvent("stopped"); stopped["reason"] != "entry" {
+		t.Errorf("wrong stop %v", stopped)
+	}
+	c.body("stepIn", map[string]any{"threadId": 1}, nil)
+	c.waitEvent("stopped")
+
+	var scopes struct {
+		Scopes []struct {
+			Name               string
+			VariablesReference int
+		}
+	}
+	c.body("scopes", map[string]any{"frameId": 1}, &scopes)
+	heap := scopes.Scopes[len(scopes.Scopes)-1]
+	var vars struct{ Variables []dapVariable }
+	c.body("variables", map[string]any{"variablesReference": heap.VariablesReference}, &vars)
+	if len(vars.Variables) != 1 || vars.Variables[0].Value != "[1, [2]]" || vars.Variables[0].VariablesReference == 0 {
+		t.Fatalf("wrong heap %v", vars)
+	}
+	c.body("variables", map[string]any{"variablesReference": vars.Variables[0].VariablesReference}, &vars)
+	if len(vars.Variables) != 2 || vars.Variables[1].Name != "1" || vars.Variables[1].Value != "[2]" {
+		t.Errorf("wrong elements %v", vars)
+	}
+
+	c.body("disconnect", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("adapter failed: %s", err)
+	}
+}
@@ -0,0 +1,403 @@
+// Package debug runs chimp programs under the control of a debugger. A
+// Debugger stops a program at line breakpoints, which may have conditions,
+// steps into, over and out of calls, and inspects and evaluates code in the
+// frames of a stopped program. RunREPL and ServeDAP are its front ends.
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/parser"
+)
+
+// ErrTerminated is the error a terminated program fails with.
+var ErrTerminated = errors.New("terminated by the debugger")
+
+// Reason is why a program stopped.
+type Reason string
+
+const (
+	Entry         Reason = "entry"
+	BreakpointHit Reason = "breakpoint"
+	Step          Reason = "step"
+	Pause         Reason = "pause"
+)
+
+// Breakpoint stops the program before the first statement it runs on a
+// line, if Condition is empty or evaluates to a truthy value there.
+type Breakpoint struct {
+	ID        int
+	File      string
+	Line      int
+	Condition string
+	Hits      int
+}
+
+// Stop describes a stopped program.
+type Stop struct {
+	Reason     Reason
+	Breakpoint *Breakpoint // set if Reason is BreakpointHit
+	// ConditionErr is set if evaluating the condition of Breakpoint failed,
+	// which stops the program so the condition can be fixed.
+	ConditionErr error
+	// Frames are the active frames, the outermost first.
+	Frames []evaluator.Frame
+}
+
+// Top returns the innermost frame.
+func (s *Stop) Top() evaluator.Frame {
+	return s.Frames[len(s.Frames)-1]
+}
+
+// Line returns the line of the statement the program stopped at.
+func (s *Stop) Line() int {
+	return frameLine(s.Top())
+}
+
+// frameLine returns the line of the statement frame is evaluating.
+func frameLine(frame evaluator.Frame) int {
+	if frame.Stmt == nil {
+		return 0
+	}
+	return ast.Start(frame.Stmt).Line
+}
+
+// Event is sent when the program stops or exits.
+type Event struct {
+	Stop   *Stop
+	Exited bool
+	// Result is the value the program produced, which is an *object.Error
+	// if it failed.
+	Result object.Object
+}
+
+type command int
+
+const (
+	continueCmd command = iota
+	stepInCmd
+	stepOverCmd
+	stepOutCmd
+	terminateCmd
+)
+
+// location is where a statement runs: a line of a file at a call depth.
+type location struct {
+	file  string
+	line  int
+	depth int
+}
+
+// Debugger controls a program run by an evaluator. The program runs in its
+// own goroutine and blocks while it is stopped. Front ends wait for its
+// Events and resume it with Continue, StepIn, StepOver or StepOut.
+type Debugger struct {
+	eval   *evaluator.Evaluator
+	events chan Event
+	resume chan command
+
+	mu          sync.Mutex // guards breakpoints, nextID and stopped
+	breakpoints []*Breakpoint
+	nextID      int
+	stopped     *Stop
+
+	pause     atomic.Bool
+	terminate atomic.Bool
+
+	// The following are only used by the program's goroutine, and by front
+	// ends while the program is stopped.
+	entry      bool
+	mode       command
+	depth      int // of the frame stepping started in
+	last       location
+	evaluating bool
+}
+
+// New returns a debugger that controls programs run by eval.
+func New(eval *evaluator.Evaluator) *Debugger {
+	d := &Debugger{eval: eval, events: make(chan Event), resume: make(chan command, 1)}
+	eval.SetHook(d.hook)
+	return d
+}
+
+// Run starts the script at path in a new goroutine. With stopOnEntry the
+// program stops before its first statement.
+func (d *Debugger) Run(path string, stopOnEntry bool) {
+	d.entry = stopOnEntry
+	go func() {
+		result := d.eval.RunFile(path)
+		d.events <- Event{Exited: true, Result: result}
+	}()
+}
+
+// Events returns the channel the program reports its stops and its exit
+// on. It must be received from until the program exited.
+func (d *Debugger) Events() <-chan Event {
+	return d.events
+}
+
+// Stopped returns where the program is stopped, or nil if it is running.
+func (d *Debugger) Stopped() *Stop {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stopped
+}
+
+// SetBreakpoint adds a breakpoint at line of file. A line no statement
+// starts on moves the breakpoint to the next line one does, and it is an
+// error if there is none.
+func (d *Debugger) SetBreakpoint(file string, line int, condition string) (*Breakpoint, error) {
+	line, err := statementLine(file, line)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	bp := &Breakpoint{ID: d.nextID, File: file, Line: line, Condition: condition}
+	d.breakpoints = append(d.breakpoints, bp)
+	return bp, nil
+}
+
+// statementLine returns the first line from line on that a statement of
+// file starts on, which is where the program can stop.
+func statementLine(file string, line int) (int, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return 0, fmt.Errorf("%s: %s", filepath.Base(file), p.Errors()[0])
+	}
+	found := 0
+	ast.Inspect(program, func(node ast.Node) bool {
+		if stmt, ok := node.(ast.Statement); ok {
+			if n := ast.Start(stmt).Line; n >= line && (found == 0 || n < found) {
+				found = n
+			}
+		}
+		return true
+	})
+	if found == 0 {
+		return 0, fmt.Errorf("no statement at or after line %d of %s", line, filepath.Base(file))
+	}
+	return found, nil
+}
+
+// ClearBreakpoint removes the breakpoint with the given ID and reports
+// whether there was one.
+func (d *Debugger) ClearBreakpoint(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, bp := range d.breakpoints {
+		if bp.ID == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearBreakpoints removes the breakpoints in file.
+func (d *Debugger) ClearBreakpoints(file string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	kept := []*Breakpoint{}
+	for _, bp := range d.breakpoints {
+		if bp.File != file {
+			kept = append(kept, bp)
+		}
+	}
+	d.breakpoints = kept
+}
+
+// Breakpoints returns the breakpoints in the order they were set.
+func (d *Debugger) Breakpoints() []*Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*Breakpoint{}, d.breakpoints...)
+}
+
+// Continue resumes the program until it hits a breakpoint.
+func (d *Debugger) Continue() { d.proceed(continueCmd) }
+
+// StepIn resumes the program until the next statement.
+func (d *Debugger) StepIn() { d.proceed(stepInCmd) }
+
+// StepOver resumes the program until the next statement of the current
+// frame or of a frame it returns to.
+func (d *Debugger) StepOver() { d.proceed(stepOverCmd) }
+
+// StepOut resumes the program until it returns from the current frame.
+func (d *Debugger) StepOut() { d.proceed(stepOutCmd) }
+
+// Pause stops the running program before its next statement.
+func (d *Debugger) Pause() {
+	d.pause.Store(true)
+}
+
+// Terminate stops the program, which fails with ErrTerminated.
+func (d *Debugger) Terminate() {
+	d.terminate.Store(true)
+	d.proceed(terminateCmd)
+}
+
+func (d *Debugger) proceed(cmd command) {
+	d.mu.Lock()
+	stopped := d.stopped
+	d.stopped = nil
+	d.mu.Unlock()
+	if stopped == nil {
+		return
+	}
+	d.mode = cmd
+	d.depth = len(stopped.Frames)
+	d.resume <- cmd
+}
+
+func (d *Debugger) hook(stmt ast.Statement, env *object.Environment) *object.Error {
+	if d.evaluating {
+		return nil
+	}
+	if d.terminate.Load() {
+		return &object.Error{Message: ErrTerminated.Error()}
+	}
+
+	frames := d.eval.Stack()
+	if len(frames) == 0 {
+		return nil
+	}
+	loc := location{file: frames[len(frames)-1].File, line: ast.Start(stmt).Line, depth: len(frames)}
+	newLine := loc != d.last
+	d.last = loc
+
+	stop := &Stop{Frames: frames}
+	switch {
+	case d.entry:
+		d.entry = false
+		stop.Reason = Entry
+	case d.pause.Swap(false):
+		stop.Reason = Pause
+	case d.mode == stepInCmd,
+		d.mode == stepOverCmd && loc.depth <= d.depth,
+		d.mode == stepOutCmd && loc.depth < d.depth:
+		stop.Reason = Step
+	case newLine:
+		stop.Breakpoint, stop.ConditionErr = d.breakpointAt(loc, env)
+		if stop.Breakpoint == nil {
+			return nil
+		}
+		stop.Reason = BreakpointHit
+	default:
+		return nil
+	}
+
+	d.mu.Lock()
+	d.stopped = stop
+	d.mu.Unlock()
+	d.events <- Event{Stop: stop}
+	if <-d.resume == terminateCmd {
+		return &object.Error{Message: ErrTerminated.Error()}
+	}
+	return nil
+}
+
+// breakpointAt returns the breakpoint that stops the program at loc.
+func (d *Debugger) breakpointAt(loc location, env *object.Environment) (*Breakpoint, error) {
+	for _, bp := range d.Breakpoints() {
+		if bp.File != loc.file || bp.Line != loc.line {
+			continue
+		}
+		if bp.Condition == "" {
+			bp.Hits++
+			return bp, nil
+		}
+		val, err := d.evaluate(bp.Condition, env)
+		if err != nil {
+			bp.Hits++
+			return bp, fmt.Errorf("condition %s: %w", bp.Condition, err)
+		}
+		if val != evaluator.FALSE && val != evaluator.NULL {
+			bp.Hits++
+			return bp, nil
+		}
+	}
+	return nil, nil
+}
+
+// Evaluate evaluates src in the frame of the stopped program with the given
+// index into Stop.Frames. Errors of the evaluation are returned as errors.
+func (d *Debugger) Evaluate(frame int, src string) (object.Object, error) {
+	stopped := d.Stopped()
+	if stopped == nil {
+		return nil, errors.New("the program is not stopped")
+	}
+	if frame < 0 || frame >= len(stopped.Frames) {
+		return nil, fmt.Errorf("no frame %d", frame)
+	}
+	return d.evaluate(src, stopped.Frames[frame].Env)
+}
+
+func (d *Debugger) evaluate(src string, env *object.Environment) (object.Object, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "; "))
+	}
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+	val := d.eval.Eval(program, env)
+	if err, ok := val.(*object.Error); ok {
+		return nil, errors.New(err.Message)
+	}
+	if val == nil {
+		val = evaluator.NULL
+	}
+	return val, nil
+}
+
+// Variable is a name bound in an environment.
+type Variable struct {
+	Name  string
+	Value object.Object
+}
+
+// Variables returns the bindings of env, without those of enclosing
+// environments, sorted by name.
+func Variables(env *object.Environment) []Variable {
+	vars := []Variable{}
+	for name, val := range env.State {
+		vars = append(vars, Variable{Name: name, Value: val})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// Cell is a heap allocated value.
+type Cell struct {
+	Address uint64
+	Value   object.Object
+}
+
+// Heap returns the heap of the program sorted by address.
+func (d *Debugger) Heap() []Cell {
+	cells := []Cell{}
+	for addr, obj := range d.eval.Heap {
+		cells = append(cells, Cell{Address: addr, Value: obj.Object})
+	}
+	sort.Slice(cells, func(i, j int) bool { return cells[i].Address < cells[j].Address })
+	return cells
+}
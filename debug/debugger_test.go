@@ -0,0 +1,270 @@
+package debug
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+const script = `let add = fnc(a, b) {
+	let sum = a + b;
+	sum
+};
+let total = 0;
+let i = 0;
+while (i < 3) {
+	total = add(total, i);
+	i++;
+}
+puts(total);
+`
+
+// writeScript writes src to a file in a temporary directory and returns
+// its path.
+func writeScript(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "main.chimp")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newDebugger returns a debugger whose program prints nothing.
+func newDebugger() *Debugger {
+	eval := evaluator.NewEval()
+	eval.SetOutput(io.Discard)
+	return New(eval)
+}
+
+// setBreakpoint sets a breakpoint that must be valid.
+func setBreakpoint(t *testing.T, d *Debugger, file string, line int, condition string) *Breakpoint {
+	t.Helper()
+	bp, err := d.SetBreakpoint(file, line, condition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bp
+}
+
+// next waits for the program to stop and returns where.
+func next(t *testing.T, d *Debugger) *Stop {
+	t.Helper()
+	ev := <-d.Events()
+	if ev.Exited {
+		t.Fatalf("program exited with %v", ev.Result)
+	}
+	return ev.Stop
+}
+
+func exit(t *testing.T, d *Debugger) object.Object {
+	t.Helper()
+	ev := <-d.Events()
+	if !ev.Exited {
+		t.Fatalf("program stopped at line %d, expected it to exit", ev.Stop.Line())
+	}
+	return ev.Result
+}
+
+func TestBreakpoints(t *testing.T) {
+	path := writeScript(t, script)
+	d := newDebugger()
+	bp := setBreakpoint(t, d, path, 2, "")
+	d.Run(path, false)
+
+	for i := 0; i < 3; i++ {
+		stop := next(t, d)
+		if stop.Reason != BreakpointHit || stop.Breakpoint != bp || stop.Line() != 2 {
+			t.Fatalf("stop %d: expected breakpoint at line 2, got %s at line %d", i, stop.Reason, stop.Line())
+		}
+		if stop.Top().Name != "add" || len(stop.Frames) != 2 {
+			t.Errorf("stop %d: wrong frames %v", i, stop.Frames)
+		}
+		val, err := d.Evaluate(len(stop.Frames)-1, "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val.Inspect() != string(rune('0'+i)) {
+			t.Errorf("stop %d: b is %s", i, val.Inspect())
+		}
+		d.Continue()
+	}
+	exit(t, d)
+	if bp.Hits != 3 {
+		t.Errorf("expected 3 hits, got %d", bp.Hits)
+	}
+}
+
+func TestConditionalBreakpoints(t *testing.T) {
+	tests := []struct {
+		condition    string
+		expectedI    string
+		expectedErr  bool
+		expectedStop bool
+	}{
+		{"i == 2", "2", false, true},
+		{"i > 5", "", false, false},
+		{"nope", "0", true, true},
+	}
+
+	for _, tt := range tests {
+		path := writeScript(t, script)
+		d := newDebugger()
+		setBreakpoint(t, d, path, 8, tt.condition)
+		d.Run(path, false)
+		if !tt.expectedStop {
+			if err, ok := exit(t, d).(*object.Error); ok {
+				t.Errorf("%s: program failed: %s", tt.condition, err.Message)
+			}
+			continue
+		}
+		stop := next(t, d)
+		if (stop.ConditionErr != nil) != tt.expectedErr {
+			t.Errorf("%s: wrong condition error %v", tt.condition, stop.ConditionErr)
+		}
+		val, err := d.Evaluate(0, "i")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val.Inspect() != tt.expectedI {
+			t.Errorf("%s: stopped with i=%s, expected %s", tt.condition, val.Inspect(), tt.expectedI)
+		}
+		d.Terminate()
+		exit(t, d)
+	}
+}
+
+func TestStepping(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    func(d *Debugger) func()
+		expected []int
+	}{
+		{"step in", func(d *Debugger) func() { return d.StepIn }, []int{1, 5, 6, 7, 8, 2, 3, 9, 8, 2}},
+		{"step over", func(d *Debugger) func() { return d.StepOver }, []int{1, 5, 6, 7, 8, 9, 8, 9, 8, 9, 11}},
+	}
+
+	for _, tt := range tests {
+		path := writeScript(t, script)
+		d := newDebugger()
+		d.Run(path, true)
+		lines := []int{next(t, d).Line()}
+		step := tt.steps(d)
+		for len(lines) < len(tt.expected) {
+			step()
+			lines = append(lines, next(t, d).Line())
+		}
+		for i := range tt.expected {
+			if lines[i] != tt.expected[i] {
+				t.Errorf("%s: wrong lines %v, expected %v", tt.name, lines, tt.expected)
+				break
+			}
+		}
+		d.Terminate()
+		exit(t, d)
+	}
+}
+
+func TestBreakpointLines(t *testing.T) {
+	path := writeScript(t, script)
+	d := newDebugger()
+	tests := []struct {
+		line     int
+		expected int
+		err      string
+	}{
+		{2, 2, ""},
+		{4, 5, ""},
+		{10, 11, ""},
+		{12, 0, "no statement at or after line 12 of main.chimp"},
+		{99, 0, "no statement at or after line 99 of main.chimp"},
+	}
+	for _, tt := range tests {
+		bp, err := d.SetBreakpoint(path, tt.line, "")
+		switch {
+		case tt.err != "":
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("line %d: expected error %q, got %v", tt.line, tt.err, err)
+			}
+		case err != nil:
+			t.Errorf("line %d: unexpected error: %s", tt.line, err)
+		case bp.Line != tt.expected:
+			t.Errorf("line %d: breakpoint moved to line %d, expected %d", tt.line, bp.Line, tt.expected)
+		}
+	}
+	if _, err := d.SetBreakpoint(filepath.Join(filepath.Dir(path), "missing.chimp"), 1, ""); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+
+	d.ClearBreakpoints(path)
+	setBreakpoint(t, d, path, 10, "")
+	d.Run(path, false)
+	if stop := next(t, d); stop.Reason != BreakpointHit || stop.Line() != 11 {
+		t.Errorf("expected a breakpoint at line 11, got %s at line %d", stop.Reason, stop.Line())
+	}
+	d.Continue()
+	exit(t, d)
+}
+
+func TestStepOut(t *testing.T) {
+	path := writeScript(t, script)
+	d := newDebugger()
+	setBreakpoint(t, d, path, 3, "")
+	d.Run(path, false)
+	if stop := next(t, d); stop.Top().Name != "add" {
+		t.Fatalf("expected to stop in add, got %s", stop.Top().Name)
+	}
+	d.StepOut()
+	stop := next(t, d)
+	if stop.Reason != Step || stop.Top().Name != "main.chimp" || stop.Line() != 9 {
+		t.Errorf("expected to step out to line 9 of main.chimp, got %s line %d", stop.Top().Name, stop.Line())
+	}
+	d.Terminate()
+	if err, ok := exit(t, d).(*object.Error); !ok || err.Message != ErrTerminated.Error() {
+		t.Errorf("expected the program to be terminated, got %v", err)
+	}
+}
+
+func TestInspection(t *testing.T) {
+	path := writeScript(t, `let p = &[1, 2];
+let h = {"a": 1};
+let f = fnc(x) {
+	let y = x * 2;
+	y
+};
+f(len(*p));
+`)
+	d := newDebugger()
+	setBreakpoint(t, d, path, 5, "")
+	d.Run(path, false)
+	stop := next(t, d)
+
+	names := []string{}
+	for _, v := range Variables(stop.Top().Env) {
+		names = append(names, v.Name+"="+inspect(v.Value))
+	}
+	if len(names) != 2 || names[0] != "x=2" || names[1] != "y=4" {
+		t.Errorf("wrong locals %v", names)
+	}
+	if globals := Variables(stop.Frames[0].Env); len(globals) != 3 {
+		t.Errorf("wrong globals %v", globals)
+	}
+	heap := d.Heap()
+	if len(heap) != 1 || heap[0].Value.Inspect() != "[1, 2]" {
+		t.Errorf("wrong heap %v", heap)
+	}
+
+	val, err := d.Evaluate(0, `h["a"] + len(*p)`)
+	if err != nil || val.Inspect() != "3" {
+		t.Errorf("wrong evaluation %v, %v", val, err)
+	}
+	if _, err := d.Evaluate(1, "z"); err == nil {
+		t.Errorf("expected an error for an unknown name")
+	}
+	d.Continue()
+	exit(t, d)
+}
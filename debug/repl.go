@@ -0,0 +1,234 @@
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+const prompt = "(chimp) "
+
+const replHelp = `commands:
+  break [file:]line [if condition]  set a breakpoint, b for short
+  clear id                          remove a breakpoint
+  breakpoints                       list the breakpoints
+  continue, c                       run until a breakpoint
+  step, s                           step to the next statement
+  next, n                           step over calls
+  out, o                            step out of the current function
+  stack, bt                         show the call stack
+  frame n                           select frame n of the stack
+  env                               show the environments of the frame
+  heap                              show the heap
+  print expr, p expr                evaluate expr in the frame
+  quit, q                           stop the program
+`
+
+// repl is a command line debugging session.
+type repl struct {
+	d     *Debugger
+	main  string
+	out   io.Writer
+	frame int // selected frame, an index into Stop.Frames
+	lines map[string][]string
+}
+
+// RunREPL debugs the script at path, reading commands from in. The program
+// stops before its first statement. Output of the program and of the
+// debugger goes to out.
+func RunREPL(path string, in io.Reader, out io.Writer) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	eval := evaluator.NewEval()
+	eval.SetOutput(out)
+	r := &repl{d: New(eval), main: abs, out: out, lines: map[string][]string{}}
+	r.d.Run(abs, true)
+	if r.wait() {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			r.d.Terminate()
+			r.wait()
+			return scanner.Err()
+		}
+		if r.command(strings.TrimSpace(scanner.Text())) {
+			return nil
+		}
+	}
+}
+
+// wait waits for the program to stop and reports whether it exited.
+func (r *repl) wait() bool {
+	ev := <-r.d.Events()
+	if ev.Exited {
+		if err, ok := ev.Result.(*object.Error); ok && err.Message != ErrTerminated.Error() {
+			fmt.Fprintln(r.out, err.Inspect())
+		}
+		fmt.Fprintln(r.out, "program exited")
+		return true
+	}
+
+	stop := ev.Stop
+	r.frame = len(stop.Frames) - 1
+	switch {
+	case stop.ConditionErr != nil:
+		fmt.Fprintf(r.out, "breakpoint %d: %s\n", stop.Breakpoint.ID, stop.ConditionErr)
+	case stop.Breakpoint != nil:
+		fmt.Fprintf(r.out, "breakpoint %d hit\n", stop.Breakpoint.ID)
+	}
+	r.printLocation(stop.Top(), stop.Line())
+	return false
+}
+
+func (r *repl) printLocation(frame evaluator.Frame, line int) {
+	fmt.Fprintf(r.out, "%s:%d in %s\n", filepath.Base(frame.File), line, frame.Name)
+	if src := r.line(frame.File, line); src != "" {
+		fmt.Fprintf(r.out, "%5d  %s\n", line, strings.TrimSpace(src))
+	}
+}
+
+// line returns line n of file, or "" if it can not be read.
+func (r *repl) line(file string, n int) string {
+	lines, ok := r.lines[file]
+	if !ok {
+		src, err := os.ReadFile(file)
+		if err == nil {
+			lines = strings.Split(string(src), "\n")
+		}
+		r.lines[file] = lines
+	}
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
+
+// command runs a command and reports whether the program exited.
+func (r *repl) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	stop := r.d.Stopped()
+
+	switch name {
+	case "":
+	case "continue", "c":
+		r.d.Continue()
+		return r.wait()
+	case "step", "s":
+		r.d.StepIn()
+		return r.wait()
+	case "next", "n":
+		r.d.StepOver()
+		return r.wait()
+	case "out", "o":
+		r.d.StepOut()
+		return r.wait()
+	case "quit", "q":
+		r.d.Terminate()
+		return r.wait()
+	case "break", "b":
+		r.setBreakpoint(arg)
+	case "clear":
+		id, err := strconv.Atoi(arg)
+		if err != nil || !r.d.ClearBreakpoint(id) {
+			fmt.Fprintf(r.out, "no breakpoint %s\n", arg)
+		}
+	case "breakpoints":
+		for _, bp := range r.d.Breakpoints() {
+			fmt.Fprintf(r.out, "%d  %s:%d", bp.ID, filepath.Base(bp.File), bp.Line)
+			if bp.Condition != "" {
+				fmt.Fprintf(r.out, " if %s", bp.Condition)
+			}
+			fmt.Fprintf(r.out, "  hits=%d\n", bp.Hits)
+		}
+	case "stack", "bt":
+		for i := len(stop.Frames) - 1; i >= 0; i-- {
+			marker := " "
+			if i == r.frame {
+				marker = "*"
+			}
+			frame := stop.Frames[i]
+			fmt.Fprintf(r.out, "%s%d  %s at %s:%d\n", marker, i, frame.Name, filepath.Base(frame.File), frameLine(frame))
+		}
+	case "frame", "f":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 || n >= len(stop.Frames) {
+			fmt.Fprintf(r.out, "no frame %s\n", arg)
+			break
+		}
+		r.frame = n
+		r.printLocation(stop.Frames[n], frameLine(stop.Frames[n]))
+	case "env":
+		depth := 0
+		for env := stop.Frames[r.frame].Env; env != nil; env = env.Outer {
+			fmt.Fprintf(r.out, "scope %d:\n", depth)
+			for _, v := range Variables(env) {
+				fmt.Fprintf(r.out, "  %s = %s\n", v.Name, inspect(v.Value))
+			}
+			depth++
+		}
+	case "heap":
+		for _, cell := range r.d.Heap() {
+			fmt.Fprintf(r.out, "0x%x = %s\n", cell.Address, inspect(cell.Value))
+		}
+	case "print", "p":
+		val, err := r.d.Evaluate(r.frame, arg)
+		if err != nil {
+			fmt.Fprintf(r.out, "error: %s\n", err)
+			break
+		}
+		fmt.Fprintln(r.out, inspect(val))
+	case "help", "h":
+		fmt.Fprint(r.out, replHelp)
+	default:
+		fmt.Fprintf(r.out, "unknown command %q, try help\n", name)
+	}
+	return false
+}
+
+// setBreakpoint parses "[file:]line [if condition]". Files are relative to
+// the directory of the main script.
+func (r *repl) setBreakpoint(arg string) {
+	location, condition, _ := strings.Cut(arg, " if ")
+	location = strings.TrimSpace(location)
+	file := r.main
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		file = location[:i]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(r.main), file)
+		}
+		location = location[i+1:]
+	}
+	line, err := strconv.Atoi(location)
+	if err != nil || line < 1 {
+		fmt.Fprintf(r.out, "invalid breakpoint location %q\n", arg)
+		return
+	}
+	bp, err := r.d.SetBreakpoint(file, line, strings.TrimSpace(condition))
+	if err != nil {
+		fmt.Fprintf(r.out, "cannot set breakpoint: %s\n", err)
+		return
+	}
+	fmt.Fprintf(r.out, "breakpoint %d at %s:%d\n", bp.ID, filepath.Base(file), bp.Line)
+}
+
+// inspect shows val the way puts does, and null for missing values.
+func inspect(val object.Object) string {
+	if val == nil {
+		return "null"
+	}
+	return val.Inspect()
+}
@@ -0,0 +1,77 @@
+package debug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	path := writeScript(t, script)
+	commands := []string{
+		"break 2 if a > 0",
+		"breakpoints",
+		"continue",
+		"stack",
+		"print a + b",
+		"frame 0",
+		"p total",
+		"env",
+		"next",
+		"clear 1",
+		"out",
+		"c",
+	}
+	var out bytes.Buffer
+	if err := RunREPL(path, strings.NewReader(strings.Join(commands, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `main.chimp:1 in main.chimp
+    1  let add = fnc(a, b) {
+(chimp) breakpoint 1 at main.chimp:2
+(chimp) 1  main.chimp:2 if a > 0  hits=0
+(chimp) breakpoint 1 hit
+main.chimp:2 in add
+    2  let sum = a + b;
+(chimp) *1  add at main.chimp:2
+ 0  main.chimp at main.chimp:8
+(chimp) 3
+(chimp) main.chimp:8 in main.chimp
+    8  total = add(total, i);
+(chimp) 1
+(chimp) scope 0:
+  add = fnc(a, b) { let sum = (a + b); sum; }
+  i = 2
+  total = 1
+(chimp) main.chimp:3 in add
+    3  sum
+(chimp) (chimp) main.chimp:9 in main.chimp
+    9  i++;
+(chimp) 3
+program exited
+`
+	if out.String() != expected {
+		t.Errorf("wrong session.\nexpected=%s\ngot=%s", expected, out.String())
+	}
+}
+
+func TestREPLQuit(t *testing.T) {
+	path := writeScript(t, script)
+	var out bytes.Buffer
+	if err := RunREPL(path, strings.NewReader("b nope\nb 99\nb 4\nstep\nq\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`invalid breakpoint location "nope"`,
+		"cannot set breakpoint: no statement at or after line 99 of main.chimp",
+		"breakpoint 1 at main.chimp:5",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q, got %s", expected, out.String())
+		}
+	}
+	if !strings.HasSuffix(out.String(), "(chimp) program exited\n") {
+		t.Errorf("expected the program to exit, got %s", out.String())
+	}
+}
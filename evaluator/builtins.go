@@ -2,28 +2,38 @@ package evaluator
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Muto1907/interpreterInGo/object"
 )
 
+// maxLength bounds the strings and arrays builtins build, so a typo can not
+// exhaust the memory.
+const maxLength = 1 << 24
+
 var builtIns = map[string]*object.BuiltIn{
 	"len": &object.BuiltIn{
-		Fnc: func(args ...object.Object) object.Object {
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("invalid number of arguments for `len need=%d got=%d", 1, len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
 			default:
 				return newError("invalid argument for `len` got %s", args[0].Type())
 			}
 		},
 	},
 	"head": &object.BuiltIn{
-		Fnc: func(args ...object.Object) object.Object {
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("invalid number of arguments for `head` need=%d got=%d", 1, len(args))
 			}
@@ -39,7 +49,7 @@ var builtIns = map[string]*object.BuiltIn{
 		},
 	},
 	"last": &object.BuiltIn{
-		Fnc: func(args ...object.Object) object.Object {
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("invalid number of arguments for `last` need=%d got=%d", 1, len(args))
 			}
@@ -55,7 +65,7 @@ var builtIns = map[string]*object.BuiltIn{
 		},
 	},
 	"tail": &object.BuiltIn{
-		Fnc: func(args ...object.Object) object.Object {
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
 			if len(args) != 1 {
 				return newError("invalid number of arguments for `tail` need=%d got=%d", 1, len(args))
 			}
@@ -63,7 +73,7 @@ var builtIns = map[string]*object.BuiltIn{
 			case *object.Array:
 				length := len(arg.Elements)
 				if length > 0 {
-					return &object.Array{Elements: arg.Elements[1:]}
+					return allocated(caller, &object.Array{Elements: slices.Clone(arg.Elements[1:])})
 				}
 				return NULL
 			default:
@@ -72,24 +82,674 @@ var builtIns = map[string]*object.BuiltIn{
 		},
 	},
 	"push": &object.BuiltIn{
-		Fnc: func(args ...object.Object) object.Object {
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
 			if len(args) != 2 {
 				return newError("invalid number of arguments for `push` need=%d got=%d", 2, len(args))
 			}
 			switch arg := args[0].(type) {
 			case *object.Array:
-				return &object.Array{Elements: append(arg.Elements, args[1])}
+				return allocated(caller, &object.Array{Elements: append(slices.Clone(arg.Elements), args[1])})
 			default:
 				return newError("invalid argument for `push` expected ARRAY got %s", arg.Type())
 			}
 		},
 	},
 	"puts": &object.BuiltIn{
-		Fnc: func(args ...object.Object) object.Object {
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(output(caller), arg.Inspect())
+			}
+			return NULL
+		},
+	},
+	"split": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("invalid number of arguments for `split` need=1 or 2 got=%d", len(args))
+			}
+			strs, err := stringArgs("split", args)
+			if err != nil {
+				return err
+			}
+			var parts []string
+			if len(strs) == 1 {
+				parts = strings.Fields(strs[0])
+			} else {
+				parts = strings.Split(strs[0], strs[1])
+			}
+			return stringsToArray(caller, parts)
+		},
+	},
+	"join": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `join` need=%d got=%d", 2, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `join` expected ARRAY got %s", args[0].Type())
+			}
+			sep, ok := args[1].(*object.String)
+			if !ok {
+				return newError("invalid argument for `join` expected STRING got %s", args[1].Type())
+			}
+			parts, err := stringArgs("join", arr.Elements)
+			if err != nil {
+				return err
+			}
+			return allocated(caller, &object.String{Value: strings.Join(parts, sep.Value)})
+		},
+	},
+	"trim": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `trim` need=%d got=%d", 1, len(args))
+			}
+			strs, err := stringArgs("trim", args)
+			if err != nil {
+				return err
+			}
+			return allocated(caller, &object.String{Value: strings.TrimSpace(strs[0])})
+		},
+	},
+	"upper": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `upper` need=%d got=%d", 1, len(args))
+			}
+			strs, err := stringArgs("upper", args)
+			if err != nil {
+				return err
+			}
+			return allocated(caller, &object.String{Value: strings.ToUpper(strs[0])})
+		},
+	},
+	"lower": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `lower` need=%d got=%d", 1, len(args))
+			}
+			strs, err := stringArgs("lower", args)
+			if err != nil {
+				return err
+			}
+			return allocated(caller, &object.String{Value: strings.ToLower(strs[0])})
+		},
+	},
+	"contains": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `contains` need=%d got=%d", 2, len(args))
+			}
+			strs, err := stringArgs("contains", args)
+			if err != nil {
+				return err
+			}
+			return nativeBooltoBooleanObject(strings.Contains(strs[0], strs[1]))
+		},
+	},
+	"index_of": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `index_of` need=%d got=%d", 2, len(args))
+			}
+			strs, err := stringArgs("index_of", args)
+			if err != nil {
+				return err
+			}
+			idx := strings.Index(strs[0], strs[1])
+			if idx < 0 {
+				return &object.Integer{Value: -1}
+			}
+			return &object.Integer{Value: int64(utf8.RuneCountInString(strs[0][:idx]))}
+		},
+	},
+	"replace": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("invalid number of arguments for `replace` need=%d got=%d", 3, len(args))
+			}
+			strs, err := stringArgs("replace", args)
+			if err != nil {
+				return err
+			}
+			return allocated(caller, &object.String{Value: strings.ReplaceAll(strs[0], strs[1], strs[2])})
+		},
+	},
+	"starts_with": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `starts_with` need=%d got=%d", 2, len(args))
+			}
+			strs, err := stringArgs("starts_with", args)
+			if err != nil {
+				return err
+			}
+			return nativeBooltoBooleanObject(strings.HasPrefix(strs[0], strs[1]))
+		},
+	},
+	"ends_with": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `ends_with` need=%d got=%d", 2, len(args))
+			}
+			strs, err := stringArgs("ends_with", args)
+			if err != nil {
+				return err
+			}
+			return nativeBooltoBooleanObject(strings.HasSuffix(strs[0], strs[1]))
+		},
+	},
+	"repeat": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `repeat` need=%d got=%d", 2, len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("invalid argument for `repeat` expected STRING got %s", args[0].Type())
+			}
+			count, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("invalid argument for `repeat` expected INTEGER got %s", args[1].Type())
+			}
+			if count.Value < 0 {
+				return newError("negative repeat count for `repeat`: %d", count.Value)
+			}
+			if len(str.Value) > 0 && count.Value > maxLength/int64(len(str.Value)) {
+				return newError("repeat count too large for `repeat`: %d", count.Value)
+			}
+			return allocated(caller, &object.String{Value: strings.Repeat(str.Value, int(count.Value))})
+		},
+	},
+	"chars": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `chars` need=%d got=%d", 1, len(args))
+			}
+			strs, err := stringArgs("chars", args)
+			if err != nil {
+				return err
+			}
+			elements := []object.Object{}
+			for _, char := range strs[0] {
+				elements = append(elements, allocated(caller, &object.String{Value: string(char)}))
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"map": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `map` need=%d got=%d", 2, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `map` expected ARRAY got %s", args[0].Type())
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			for i, element := range arr.Elements {
+				val := caller.CallFunction(args[1], element)
+				if isError(val) {
+					return val
+				}
+				elements[i] = val
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"filter": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `filter` need=%d got=%d", 2, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `filter` expected ARRAY got %s", args[0].Type())
+			}
+			elements := []object.Object{}
+			for _, element := range arr.Elements {
+				val := caller.CallFunction(args[1], element)
+				if isError(val) {
+					return val
+				}
+				if isTruthy(val) {
+					elements = append(elements, element)
+				}
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"reduce": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("invalid number of arguments for `reduce` need=2 or 3 got=%d", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `reduce` expected ARRAY got %s", args[0].Type())
+			}
+			elements := arr.Elements
+			var acc object.Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else {
+				if len(elements) == 0 {
+					return newError("`reduce` of empty ARRAY with no initial value")
+				}
+				acc, elements = elements[0], elements[1:]
+			}
+			for _, element := range elements {
+				acc = caller.CallFunction(args[1], acc, element)
+				if isError(acc) {
+					return acc
+				}
+			}
+			return acc
+		},
+	},
+	"find": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `find` need=%d got=%d", 2, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `find` expected ARRAY got %s", args[0].Type())
+			}
+			for _, element := range arr.Elements {
+				val := caller.CallFunction(args[1], element)
+				if isError(val) {
+					return val
+				}
+				if isTruthy(val) {
+					return element
+				}
 			}
 			return NULL
 		},
 	},
+	"any": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `any` need=%d got=%d", 2, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `any` expected ARRAY got %s", args[0].Type())
+			}
+			for _, element := range arr.Elements {
+				val := caller.CallFunction(args[1], element)
+				if isError(val) {
+					return val
+				}
+				if isTruthy(val) {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+	},
+	"all": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `all` need=%d got=%d", 2, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `all` expected ARRAY got %s", args[0].Type())
+			}
+			for _, element := range arr.Elements {
+				val := caller.CallFunction(args[1], element)
+				if isError(val) {
+					return val
+				}
+				if !isTruthy(val) {
+					return FALSE
+				}
+			}
+			return TRUE
+		},
+	},
+	"sort": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("invalid number of arguments for `sort` need=1 or 2 got=%d", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `sort` expected ARRAY got %s", args[0].Type())
+			}
+			elements := append([]object.Object{}, arr.Elements...)
+			var err object.Object
+			sort.SliceStable(elements, func(i, j int) bool {
+				if err != nil {
+					return false
+				}
+				if len(args) == 1 {
+					return object.Compare(elements[i], elements[j]) < 0
+				}
+				var less bool
+				less, err = callComparator(caller, args[1], elements[i], elements[j])
+				return less
+			})
+			if err != nil {
+				return err
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"reverse": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `reverse` need=%d got=%d", 1, len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Array:
+				length := len(arg.Elements)
+				elements := make([]object.Object, length)
+				for i, element := range arg.Elements {
+					elements[length-1-i] = element
+				}
+				return allocated(caller, &object.Array{Elements: elements})
+			case *object.String:
+				runes := []rune(arg.Value)
+				for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+					runes[i], runes[j] = runes[j], runes[i]
+				}
+				return allocated(caller, &object.String{Value: string(runes)})
+			default:
+				return newError("invalid argument for `reverse` expected ARRAY got %s", arg.Type())
+			}
+		},
+	},
+	"flatten": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `flatten` need=%d got=%d", 1, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("invalid argument for `flatten` expected ARRAY got %s", args[0].Type())
+			}
+			elements := []object.Object{}
+			for _, element := range arr.Elements {
+				if inner, ok := element.(*object.Array); ok {
+					elements = append(elements, inner.Elements...)
+				} else {
+					elements = append(elements, element)
+				}
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"zip": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("invalid number of arguments for `zip` need=%d got=%d", 2, len(args))
+			}
+			arrays := make([]*object.Array, len(args))
+			length := -1
+			for i, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return newError("invalid argument for `zip` expected ARRAY got %s", arg.Type())
+				}
+				if length < 0 || len(arr.Elements) < length {
+					length = len(arr.Elements)
+				}
+				arrays[i] = arr
+			}
+			elements := make([]object.Object, length)
+			for i := range elements {
+				tuple := make([]object.Object, len(arrays))
+				for j, arr := range arrays {
+					tuple[j] = arr.Elements[i]
+				}
+				elements[i] = allocated(caller, &object.Array{Elements: tuple})
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"range": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("invalid number of arguments for `range` need=1 to 3 got=%d", len(args))
+			}
+			bounds := make([]int64, len(args))
+			for i, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("invalid argument for `range` expected INTEGER got %s", arg.Type())
+				}
+				bounds[i] = integer.Value
+			}
+			start, end, step := int64(0), bounds[0], int64(1)
+			if len(bounds) > 1 {
+				start, end = bounds[0], bounds[1]
+			}
+			if len(bounds) > 2 {
+				step = bounds[2]
+			}
+			if step == 0 {
+				return newError("`range` step must not be zero")
+			}
+			length := rangeLength(start, end, step)
+			if length > maxLength {
+				return newError("too many elements for `range`: %d", length)
+			}
+			elements := make([]object.Object, length)
+			for i := range elements {
+				elements[i] = &object.Integer{Value: start + int64(i)*step}
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"concat": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			elements := []object.Object{}
+			for _, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return newError("invalid argument for `concat` expected ARRAY got %s", arg.Type())
+				}
+				elements = append(elements, arr.Elements...)
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"keys": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `keys` need=%d got=%d", 1, len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("invalid argument for `keys` expected HASH got %s", args[0].Type())
+			}
+			elements := []object.Object{}
+			for _, pair := range hash.Pairs() {
+				elements = append(elements, pair.Key)
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"values": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `values` need=%d got=%d", 1, len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("invalid argument for `values` expected HASH got %s", args[0].Type())
+			}
+			elements := []object.Object{}
+			for _, pair := range hash.Pairs() {
+				elements = append(elements, pair.Value)
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"entries": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `entries` need=%d got=%d", 1, len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("invalid argument for `entries` expected HASH got %s", args[0].Type())
+			}
+			elements := []object.Object{}
+			for _, pair := range hash.Pairs() {
+				elements = append(elements, allocated(caller, &object.Array{Elements: []object.Object{pair.Key, pair.Value}}))
+			}
+			return allocated(caller, &object.Array{Elements: elements})
+		},
+	},
+	"has": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `has` need=%d got=%d", 2, len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("invalid argument for `has` expected HASH got %s", args[0].Type())
+			}
+			key, ok := object.AsHashable(args[1])
+			if !ok {
+				return newError("%s can not be used as HashKey", args[1].Type())
+			}
+			_, ok = hash.Get(key)
+			return nativeBooltoBooleanObject(ok)
+		},
+	},
+	"delete": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `delete` need=%d got=%d", 2, len(args))
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("invalid argument for `delete` expected HASH got %s", args[0].Type())
+			}
+			if hash.Frozen {
+				return newError("cannot modify frozen %s", hash.Type())
+			}
+			key, ok := object.AsHashable(args[1])
+			if !ok {
+				return newError("%s can not be used as HashKey", args[1].Type())
+			}
+			pair, ok := hash.Delete(key)
+			if !ok {
+				return NULL
+			}
+			return pair.Value
+		},
+	},
+	"merge": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			merged := allocated(caller, object.NewHash())
+			for _, arg := range args {
+				hash, ok := arg.(*object.Hash)
+				if !ok {
+					return newError("invalid argument for `merge` expected HASH got %s", arg.Type())
+				}
+				for _, pair := range hash.Pairs() {
+					merged.Set(pair.Key.(object.Hashable), pair.Value)
+				}
+			}
+			return merged
+		},
+	},
+	"identical": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `identical` need=%d got=%d", 2, len(args))
+			}
+			switch args[0].(type) {
+			case *object.Array, *object.Hash, *object.Function, *object.BuiltIn:
+				return nativeBooltoBooleanObject(args[0] == args[1])
+			default:
+				// Scalars have no identity of their own.
+				return nativeBooltoBooleanObject(object.Equal(args[0], args[1]))
+			}
+		},
+	},
+	"freeze": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `freeze` need=%d got=%d", 1, len(args))
+			}
+			object.Freeze(args[0])
+			return args[0]
+		},
+	},
+}
+
+// BuiltInNames returns the names of the builtin functions in sorted order.
+func BuiltInNames() []string {
+	names := make([]string, 0, len(builtIns))
+	for name := range builtIns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// callComparator calls a user supplied `sort` comparator. It may return a
+// BOOLEAN telling whether a sorts before b, or an INTEGER that is negative
+// when it does.
+func callComparator(caller object.Caller, cmp, a, b object.Object) (bool, object.Object) {
+	val := caller.CallFunction(cmp, a, b)
+	switch val := val.(type) {
+	case *object.Error:
+		return false, val
+	case *object.Boolean:
+		return val.Value, nil
+	case *object.Integer:
+		return val.Value < 0, nil
+	default:
+		return false, newError("invalid comparator result for `sort` expected BOOLEAN or INTEGER got %s", val.Type())
+	}
+}
+
+// stringArgs unwraps arguments that must all be strings, reporting the first
+// one that is not.
+func stringArgs(name string, args []object.Object) ([]string, *object.Error) {
+	strs := make([]string, len(args))
+	for i, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, newError("invalid argument for `%s` expected STRING got %s", name, arg.Type())
+		}
+		strs[i] = str.Value
+	}
+	return strs, nil
+}
+
+// rangeLength returns how many numbers range yields from start to end. It
+// counts in uint64, where the distance between two int64 always fits.
+func rangeLength(start, end, step int64) uint64 {
+	var distance, stride uint64
+	switch {
+	case step > 0 && start < end:
+		distance, stride = uint64(end)-uint64(start), uint64(step)
+	case step < 0 && start > end:
+		distance, stride = uint64(start)-uint64(end), uint64(-step)
+	default:
+		return 0
+	}
+	length := distance / stride
+	if distance%stride != 0 {
+		length++
+	}
+	return length
+}
+
+func stringsToArray(caller object.Caller, strs []string) *object.Array {
+	elements := make([]object.Object, len(strs))
+	for i, str := range strs {
+		elements[i] = allocated(caller, &object.String{Value: str})
+	}
+	return allocated(caller, &object.Array{Elements: elements})
 }
@@ -0,0 +1,34 @@
+package evaluator
+
+import (
+	"github.com/Muto1907/interpreterInGo/ast"
+)
+
+// Coverage is told which statements of a program run and which way the
+// conditions of if expressions and while loops go. File is the script the
+// code is from, or empty if it was not read from a file.
+type Coverage interface {
+	Statement(file string, stmt ast.Statement)
+	// Branch is called each time the condition of node, an
+	// *ast.IfExpression or an *ast.WhileStatement, is evaluated.
+	Branch(file string, node ast.Node, cond bool)
+}
+
+// SetCoverage makes the evaluator report to c. A nil coverage removes it.
+func (eva *Evaluator) SetCoverage(c Coverage) {
+	eva.coverage = c
+}
+
+// file returns the script the innermost frame runs code from.
+func (eva *Evaluator) file() string {
+	if len(eva.frames) == 0 {
+		return ""
+	}
+	return eva.frames[len(eva.frames)-1].File
+}
+
+func (eva *Evaluator) coverBranch(node ast.Node, cond bool) {
+	if eva.coverage != nil {
+		eva.coverage.Branch(eva.file(), node, cond)
+	}
+}
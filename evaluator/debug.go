@@ -0,0 +1,127 @@
+package evaluator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+// anonymous names the frames of functions that are not called by name.
+const anonymous = "<anonymous>"
+
+// Frame is an active call: a program or module, or a function call.
+type Frame struct {
+	Name string
+	// File is the path of the script the code is from, or empty if the
+	// code was not read from a file.
+	File string
+	// Line is the line the code of the frame starts on.
+	Line int
+	Env  *object.Environment
+	// Stmt is the statement being evaluated, nil before the first one.
+	Stmt ast.Statement
+}
+
+// Hook is called before each statement is evaluated, except for blocks,
+// whose statements are hooked one by one. Returning an error stops the
+// evaluation with that error.
+type Hook func(stmt ast.Statement, env *object.Environment) *object.Error
+
+// SetHook makes the evaluator call hook before each statement. A nil hook
+// removes it.
+func (eva *Evaluator) SetHook(hook Hook) {
+	eva.hook = hook
+}
+
+// SetOutput makes scripts print to w.
+func (eva *Evaluator) SetOutput(w io.Writer) {
+	eva.Output = w
+}
+
+// output returns where the builtins called by caller print to.
+func output(caller object.Caller) io.Writer {
+	if eva, ok := caller.(*Evaluator); ok && eva.Output != nil {
+		return eva.Output
+	}
+	return os.Stdout
+}
+
+// Stack returns the active frames, the outermost first.
+func (eva *Evaluator) Stack() []Frame {
+	stack := make([]Frame, len(eva.frames))
+	for i, frame := range eva.frames {
+		stack[i] = *frame
+	}
+	return stack
+}
+
+func (eva *Evaluator) enterStatement(stmt ast.Statement, env *object.Environment) *object.Error {
+	if _, ok := stmt.(*ast.BlockStatement); ok {
+		return nil
+	}
+	if len(eva.frames) > 0 {
+		eva.frames[len(eva.frames)-1].Stmt = stmt
+	}
+	if eva.profiler != nil {
+		eva.profiler.Statement(stmt)
+	}
+	if eva.coverage != nil {
+		eva.coverage.Statement(eva.file(), stmt)
+	}
+	if eva.hook != nil {
+		return eva.hook(stmt, env)
+	}
+	return nil
+}
+
+func (eva *Evaluator) pushFrame(frame *Frame) {
+	eva.frames = append(eva.frames, frame)
+	if eva.profiler != nil {
+		eva.profiler.Enter(frame)
+	}
+}
+
+func (eva *Evaluator) popFrame() {
+	frame := eva.frames[len(eva.frames)-1]
+	eva.frames = eva.frames[:len(eva.frames)-1]
+	if eva.profiler != nil {
+		eva.profiler.Leave(frame)
+	}
+}
+
+// programFrame returns the frame of a program evaluated in env: the script
+// or module on top of the import stack, or the main program.
+func (eva *Evaluator) programFrame(env *object.Environment) *Frame {
+	if len(eva.importStack) == 0 {
+		return &Frame{Name: "main", Line: 1, Env: env}
+	}
+	path := eva.importStack[len(eva.importStack)-1]
+	return &Frame{Name: filepath.Base(path), File: path, Line: 1, Env: env}
+}
+
+// defineFunction remembers the file fn is defined in, which its calls
+// are from.
+func (eva *Evaluator) defineFunction(fn *ast.FuncLiteral) {
+	file := eva.file()
+	if file == "" || fn.Body == nil {
+		return
+	}
+	if eva.funcFiles == nil {
+		eva.funcFiles = make(map[*ast.BlockStatement]string)
+	}
+	eva.funcFiles[fn.Body] = file
+}
+
+// callName returns the name a function is called by, or anonymous.
+func callName(function ast.Expression) string {
+	switch function := function.(type) {
+	case *ast.Identifier:
+		return function.Value
+	case *ast.MemberExpression:
+		return function.Object.String() + "." + function.Property.Value
+	}
+	return anonymous
+}
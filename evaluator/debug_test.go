@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/parser"
+)
+
+func TestHook(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"main.chimp": `import { twice } from "./lib";
+let add = fnc(a, b) {
+	a + b
+};
+let x = twice(fnc(n) { add(n, 1) }, 1);
+puts(x);`,
+		"lib.chimp": `let twice = fnc(f, x) { f(f(x)) };
+export { twice };`,
+	})
+
+	eval := NewEval()
+	var out bytes.Buffer
+	eval.SetOutput(&out)
+	trace := []string{}
+	eval.SetHook(func(stmt ast.Statement, env *object.Environment) *object.Error {
+		frames := []string{}
+		for _, frame := range eval.Stack() {
+			frames = append(frames, fmt.Sprintf("%s@%s", frame.Name, filepath.Base(frame.File)))
+		}
+		if frame := eval.Stack()[len(eval.Stack())-1]; frame.Stmt != stmt || frame.Env != env {
+			t.Errorf("innermost frame is not at %s", stmt)
+		}
+		trace = append(trace, fmt.Sprintf("%d %s", ast.Start(stmt).Line, strings.Join(frames, " ")))
+		return nil
+	})
+	result := eval.RunFile(filepath.Join(dir, "main.chimp"))
+	if isError(result) {
+		t.Fatalf("unexpected error: %s", result.Inspect())
+	}
+
+	expected := []string{
+		"1 main.chimp@main.chimp",
+		"1 main.chimp@main.chimp lib.chimp@lib.chimp",
+		"2 main.chimp@main.chimp",
+		"5 main.chimp@main.chimp",
+		"1 main.chimp@main.chimp twice@lib.chimp",
+		"5 main.chimp@main.chimp twice@lib.chimp f@main.chimp",
+		"3 main.chimp@main.chimp twice@lib.chimp f@main.chimp add@main.chimp",
+		"5 main.chimp@main.chimp twice@lib.chimp f@main.chimp",
+		"3 main.chimp@main.chimp twice@lib.chimp f@main.chimp add@main.chimp",
+		"6 main.chimp@main.chimp",
+	}
+	if strings.Join(trace, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong trace.\nexpected=%q\ngot=     %q", expected, trace)
+	}
+	if out.String() != "3\n" {
+		t.Errorf("wrong output %q", out.String())
+	}
+	if len(eval.Stack()) != 0 {
+		t.Errorf("frames left after the program: %v", eval.Stack())
+	}
+}
+
+func TestHookStopsEvaluation(t *testing.T) {
+	eval := NewEval()
+	count := 0
+	eval.SetHook(func(stmt ast.Statement, env *object.Environment) *object.Error {
+		count++
+		if count == 3 {
+			return newError("stopped")
+		}
+		return nil
+	})
+	program := parser.New(lexer.New("let x = 0; while (true) { x++; }")).ParseProgram()
+	result := eval.Eval(program, object.NewEnvironment())
+	if err, ok := result.(*object.Error); !ok || err.Message != "stopped" {
+		t.Errorf("expected the hook's error, got %v", result)
+	}
+}
@@ -2,10 +2,16 @@ package evaluator
 
 import (
 	"fmt"
+	"io"
 	"math"
+	"math/big"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Muto1907/interpreterInGo/ast"
 	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/token"
 )
 
 var (
@@ -18,22 +24,52 @@ type Evaluator struct {
 	Heap              object.Heap
 	NextAddress       uint64
 	Threshold         int
+	StrictIntegers    bool
+	SearchPath        []string
 	visitedEnvs       map[*object.Environment]bool
 	pointersAllocated uint64
+	modules           map[string]*object.Module
+	importStack       []string
+	// Output receives what scripts print. It defaults to standard output.
+	Output    io.Writer
+	hook      Hook
+	profiler  Profiler
+	coverage  Coverage
+	frames    []*Frame
+	funcFiles map[*ast.BlockStatement]string
+	// builtinCalls counts the builtins running. The values they hold, like
+	// the results map has collected so far, are in no environment, so the
+	// collection is pending until they return.
+	builtinCalls   int
+	collectPending bool
 }
 
 func NewEval() *Evaluator {
-	return &Evaluator{Heap: make(map[uint64]object.HeapObject), NextAddress: 0, Threshold: 100, pointersAllocated: 0}
+	return &Evaluator{Heap: make(map[uint64]object.HeapObject), NextAddress: 0, Threshold: 100, pointersAllocated: 0,
+		modules: make(map[string]*object.Module)}
 }
 
 func (ev *Evaluator) SetThreshold(threshold int) {
 	ev.Threshold = threshold
 }
 
+// SetStrictIntegers makes integer overflow an error instead of promoting the
+// result to a BigInteger.
+func (ev *Evaluator) SetStrictIntegers(strict bool) {
+	ev.StrictIntegers = strict
+}
+
 func (eva *Evaluator) MarkandSweep(env *object.Environment) {
 	eva.visitedEnvs = make(map[*object.Environment]bool)
 	eva.mark(env)
+	for _, frame := range eva.frames {
+		eva.mark(frame.Env)
+	}
+	for _, mod := range eva.modules {
+		eva.mark(mod.Env)
+	}
 	eva.Sweep()
+	eva.collectPending = false
 }
 
 func (eva *Evaluator) mark(env *object.Environment) {
@@ -63,12 +99,15 @@ func (eva *Evaluator) markValue(obj object.Object) {
 		}
 
 	case *object.Hash:
-		for _, pair := range o.Pairs {
+		for _, pair := range o.Pairs() {
 			eva.markValue(pair.Value)
 		}
 
 	case *object.Function:
 		eva.mark(o.Env)
+
+	case *object.Module:
+		eva.mark(o.Env)
 	}
 }
 
@@ -98,6 +137,11 @@ func (eva *Evaluator) Sweep() {
 }
 
 func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object {
+	if stmt, ok := node.(ast.Statement); ok {
+		if err := eva.enterStatement(stmt, env); err != nil {
+			return err
+		}
+	}
 
 	switch node := node.(type) {
 	case *ast.Program:
@@ -105,9 +149,14 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 	case *ast.ExpressionStatement:
 		return eva.Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return allocated(eva, &object.BigInteger{Value: new(big.Int).Set(node.Big)})
+		}
 		return &object.Integer{Value: node.Value}
 	case *ast.StringLiteral:
-		return &object.String{Value: node.Value}
+		return allocated(eva, &object.String{Value: node.Value})
+	case *ast.InterpolatedString:
+		return eva.evalInterpolatedString(node, env)
 	case *ast.Boolean:
 		return nativeBooltoBooleanObject(node.Value)
 	case *ast.PrefixExpression:
@@ -115,7 +164,11 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 		if isError(right) {
 			return right
 		}
-		return eva.EvalPrefixExpr(node.Operator, right, env)
+		val := eva.EvalPrefixExpr(node.Operator, right, env)
+		if eva.overflowed(val, right) {
+			return newError("integer overflow: %s%s", node.Operator, right.Inspect())
+		}
+		return val
 	case *ast.InfixExpression:
 		left := eva.Eval(node.Left, env)
 		if isError(left) {
@@ -125,7 +178,11 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 		if isError(right) {
 			return right
 		}
-		return EvalInfixExpr(node.Operator, left, right)
+		val := allocated(eva, EvalInfixExpr(node.Operator, left, right))
+		if eva.overflowed(val, left, right) {
+			return newError("integer overflow: %s %s %s", left.Inspect(), node.Operator, right.Inspect())
+		}
+		return val
 	case *ast.BlockStatement:
 		return eva.evalBlockStatement(node, env)
 	case *ast.IfExpression:
@@ -146,20 +203,36 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 		if isError(val) {
 			return val
 		}
-		_, ok := env.GetLocal(node.Name.Value)
-		if ok {
-			return newError("Variable already initialized: %s", node.Name.Value)
+		var pattern ast.Pattern = node.Name
+		if node.Pattern != nil {
+			pattern = node.Pattern
+		}
+		if err := eva.bindPattern(pattern, val, env, node.IsConst()); err != nil {
+			return err
 		}
-		env.Set(node.Name.Value, val)
 	case *ast.ReassignmentStatement:
 		val := eva.evalReassignmentStatement(node, env)
 		if isError(val) {
 			return val
 		}
+	case *ast.ImportStatement:
+		val := eva.evalImportStatement(node, env)
+		if isError(val) {
+			return val
+		}
+	case *ast.ExportStatement:
+		return newPositionedError(node.Token, "export is only allowed at the top level of a module")
+	case *ast.MemberExpression:
+		obj := eva.Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		return evalMemberExpression(node, obj)
 	case *ast.Identifier:
 		return eva.evalIdentifier(node, env)
 	case *ast.FuncLiteral:
-		return &object.Function{Params: node.Parameters, Body: node.Body, Env: env}
+		eva.defineFunction(node)
+		return allocated(eva, &object.Function{Params: node.Parameters, Body: node.Body, Env: env})
 	case *ast.CallExpression:
 		function := eva.Eval(node.Function, env)
 		if isError(function) {
@@ -169,13 +242,13 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return eva.callFunction(function, args)
+		return eva.callFunction(callName(node.Function), function, args)
 	case *ast.ArrayLiteral:
 		elements := eva.evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
-		return &object.Array{Elements: elements}
+		return allocated(eva, &object.Array{Elements: elements})
 	case *ast.HashLiteral:
 		return eva.evalHashLiteral(node, env)
 	case *ast.IndexExpression:
@@ -187,7 +260,13 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 		if isError(index) {
 			return index
 		}
-		return evalIndexExpression(left, index)
+		val := evalIndexExpression(left, index)
+		if left.Type() == object.STRING_OBJ {
+			allocated(eva, val)
+		}
+		return val
+	case *ast.SliceExpression:
+		return eva.evalSliceExpression(node, env)
 	}
 
 	return nil
@@ -195,8 +274,13 @@ func (eva *Evaluator) Eval(node ast.Node, env *object.Environment) object.Object
 
 func (eva *Evaluator) evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var obj object.Object
+	eva.pushFrame(eva.programFrame(env))
+	defer eva.popFrame()
 
 	for _, stmt := range program.Statements {
+		if _, ok := stmt.(*ast.ExportStatement); ok {
+			continue
+		}
 		obj = eva.Eval(stmt, env)
 		switch obj := obj.(type) {
 		case *object.ReturnValue:
@@ -209,6 +293,20 @@ func (eva *Evaluator) evalProgram(program *ast.Program, env *object.Environment)
 	return obj
 }
 
+// overflowed reports whether strict integer mode forbids val, a BigInteger
+// computed from operands that all fit into an int64.
+func (eva *Evaluator) overflowed(val object.Object, operands ...object.Object) bool {
+	if !eva.StrictIntegers || val == nil || val.Type() != object.BIGINT_OBJ {
+		return false
+	}
+	for _, operand := range operands {
+		if operand.Type() == object.BIGINT_OBJ {
+			return false
+		}
+	}
+	return true
+}
+
 func nativeBooltoBooleanObject(b bool) *object.Boolean {
 	if b {
 		return TRUE
@@ -221,11 +319,13 @@ func (eva *Evaluator) EvalPrefixExpr(operator string, right object.Object, env *
 	case "!":
 		return evalBangOperatorExpr(right)
 	case "-":
-		return evalPrefixMinusExpr(right)
+		return allocated(eva, evalPrefixMinusExpr(right))
 	case "&":
 		return eva.evalAmpersandExpr(right, env)
 	case "*":
 		return eva.evalDereference(right)
+	case "~":
+		return allocated(eva, evalBitwiseNotExpr(right))
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -243,10 +343,13 @@ func (eva *Evaluator) evalAmpersandExpr(obj object.Object, env *object.Environme
 		_, ok = eva.Heap[eva.NextAddress]
 	}
 	eva.Heap[eva.NextAddress] = object.NewHeapOject(obj)
-	ptr := &object.Pointer{Value: eva.NextAddress}
+	ptr := allocated(eva, &object.Pointer{Value: eva.NextAddress})
 	eva.pointersAllocated += 1
 	eva.NextAddress += 1
 	if eva.pointersAllocated%uint64(eva.Threshold) == 0 {
+		eva.collectPending = true
+	}
+	if eva.collectPending && eva.builtinCalls == 0 {
 		eva.MarkandSweep(env)
 	}
 	return ptr
@@ -275,23 +378,50 @@ func evalBangOperatorExpr(obj object.Object) object.Object {
 }
 
 func evalPrefixMinusExpr(obj object.Object) object.Object {
-	if obj.Type() != object.INTEGER_OBJ {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		if obj.Value == math.MinInt64 {
+			return object.NewInteger(new(big.Int).Neg(big.NewInt(obj.Value)))
+		}
+		return &object.Integer{Value: -obj.Value}
+	case *object.BigInteger:
+		return object.NewInteger(new(big.Int).Neg(obj.Value))
+	default:
 		return newError("unknown operator: -%s", obj.Type())
 	}
-	value := obj.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+}
+
+func evalBitwiseNotExpr(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^obj.Value}
+	case *object.BigInteger:
+		return object.NewInteger(new(big.Int).Not(obj.Value))
+	default:
+		return newError("unknown operator: ~%s", obj.Type())
+	}
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
 }
 
 func EvalInfixExpr(operator string, left, right object.Object) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntegerInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBooltoBooleanObject(left == right)
+		return nativeBooltoBooleanObject(object.Equal(left, right))
 	case operator == "!=":
-		return nativeBooltoBooleanObject(left != right)
+		return nativeBooltoBooleanObject(!object.Equal(left, right))
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ && operator == "<":
+		return nativeBooltoBooleanObject(object.Compare(left, right) < 0)
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ && operator == ">":
+		return nativeBooltoBooleanObject(object.Compare(left, right) > 0)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -305,16 +435,53 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal.Value + rightVal.Value}
+		sum := leftVal.Value + rightVal.Value
+		if (sum > leftVal.Value) != (rightVal.Value > 0) {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: sum}
 	case "-":
-		return &object.Integer{Value: leftVal.Value - rightVal.Value}
+		diff := leftVal.Value - rightVal.Value
+		if (diff < leftVal.Value) != (rightVal.Value > 0) {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: diff}
 	case "*":
-		return &object.Integer{Value: leftVal.Value * rightVal.Value}
+		product := leftVal.Value * rightVal.Value
+		if leftVal.Value != 0 && (product/leftVal.Value != rightVal.Value ||
+			(leftVal.Value == -1 && rightVal.Value == math.MinInt64)) {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: product}
 	case "/":
-		if rightVal.Value != 0 {
-			return &object.Integer{Value: leftVal.Value / rightVal.Value}
+		if rightVal.Value == 0 {
+			return newError("zero division: %d / %d", rightVal.Value, leftVal.Value)
+		}
+		if leftVal.Value == math.MinInt64 && rightVal.Value == -1 {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: leftVal.Value / rightVal.Value}
+	case "%":
+		if rightVal.Value == 0 {
+			return newError("zero division: %d %% %d", leftVal.Value, rightVal.Value)
+		}
+		return &object.Integer{Value: leftVal.Value % rightVal.Value}
+	case "&":
+		return &object.Integer{Value: leftVal.Value & rightVal.Value}
+	case "|":
+		return &object.Integer{Value: leftVal.Value | rightVal.Value}
+	case "^":
+		return &object.Integer{Value: leftVal.Value ^ rightVal.Value}
+	case "<<":
+		if rightVal.Value < 0 || rightVal.Value >= 63 || bits.LeadingZeros64(absUint(leftVal.Value)) <= int(rightVal.Value) {
+			return evalBigIntegerInfixExpression(operator, left, right)
+		}
+		return &object.Integer{Value: leftVal.Value << rightVal.Value}
+	case ">>":
+		if rightVal.Value < 0 {
+			return newError("negative shift count: %d", rightVal.Value)
 		}
-		return newError("zero division: %d / %d", rightVal.Value, leftVal.Value)
+		return &object.Integer{Value: leftVal.Value >> rightVal.Value}
 	case "<":
 		return nativeBooltoBooleanObject(leftVal.Value < rightVal.Value)
 	case ">":
@@ -329,6 +496,67 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 
 }
 
+// maxShift bounds shift counts so a typo can not allocate huge integers.
+const maxShift = 1 << 20
+
+func absUint(value int64) uint64 {
+	if value < 0 {
+		return uint64(-value)
+	}
+	return uint64(value)
+}
+
+func evalBigIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal, _ := object.ToBigInt(left)
+	rightVal, _ := object.ToBigInt(right)
+
+	switch operator {
+	case "+":
+		return object.NewInteger(new(big.Int).Add(leftVal, rightVal))
+	case "-":
+		return object.NewInteger(new(big.Int).Sub(leftVal, rightVal))
+	case "*":
+		return object.NewInteger(new(big.Int).Mul(leftVal, rightVal))
+	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("zero division: %s / %s", rightVal, leftVal)
+		}
+		return object.NewInteger(new(big.Int).Quo(leftVal, rightVal))
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("zero division: %s %% %s", leftVal, rightVal)
+		}
+		return object.NewInteger(new(big.Int).Rem(leftVal, rightVal))
+	case "&":
+		return object.NewInteger(new(big.Int).And(leftVal, rightVal))
+	case "|":
+		return object.NewInteger(new(big.Int).Or(leftVal, rightVal))
+	case "^":
+		return object.NewInteger(new(big.Int).Xor(leftVal, rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if !rightVal.IsInt64() || rightVal.Int64() > maxShift {
+			return newError("shift count too large: %s", rightVal)
+		}
+		if operator == "<<" {
+			return object.NewInteger(new(big.Int).Lsh(leftVal, uint(rightVal.Int64())))
+		}
+		return object.NewInteger(new(big.Int).Rsh(leftVal, uint(rightVal.Int64())))
+	case "<":
+		return nativeBooltoBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case ">":
+		return nativeBooltoBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case "==":
+		return nativeBooltoBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBooltoBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String)
 	rightVal := right.(*object.String)
@@ -336,6 +564,14 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	switch operator {
 	case "+":
 		return &object.String{Value: leftVal.Value + rightVal.Value}
+	case "<":
+		return nativeBooltoBooleanObject(leftVal.Value < rightVal.Value)
+	case ">":
+		return nativeBooltoBooleanObject(leftVal.Value > rightVal.Value)
+	case "==":
+		return nativeBooltoBooleanObject(leftVal.Value == rightVal.Value)
+	case "!=":
+		return nativeBooltoBooleanObject(leftVal.Value != rightVal.Value)
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
@@ -346,6 +582,7 @@ func (eva *Evaluator) evalIfExpression(ifExpression *ast.IfExpression, env *obje
 	if isError(condition) {
 		return condition
 	}
+	eva.coverBranch(ifExpression, isTruthy(condition))
 	if isTruthy(condition) {
 		return eva.Eval(ifExpression.Then, env)
 	} else if ifExpression.Alt != nil {
@@ -361,6 +598,7 @@ func (eva *Evaluator) evalWhileStatement(while *ast.WhileStatement, env *object.
 		return condition
 	}
 
+	eva.coverBranch(while, isTruthy(condition))
 	for isTruthy(condition) {
 		val := eva.Eval(while.Body, env)
 		if val != nil {
@@ -375,6 +613,7 @@ func (eva *Evaluator) evalWhileStatement(while *ast.WhileStatement, env *object.
 		if isError(condition) {
 			return condition
 		}
+		eva.coverBranch(while, isTruthy(condition))
 	}
 	return NULL
 
@@ -417,6 +656,11 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// newPositionedError prefixes the message with the position of tok.
+func newPositionedError(tok token.Token, format string, a ...interface{}) *object.Error {
+	return newError("line %d, column %d: %s", tok.Line, tok.Column, fmt.Sprintf(format, a...))
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
@@ -448,26 +692,134 @@ func (eva *Evaluator) evalExpressions(expressions []ast.Expression, env *object.
 	return result
 }
 
-func (eva *Evaluator) callFunction(fnc object.Object, args []object.Object) object.Object {
+// CallFunction calls a user defined or builtin function with the given
+// arguments, so builtins can call back into the evaluator.
+func (eva *Evaluator) CallFunction(fnc object.Object, args ...object.Object) object.Object {
+	return eva.callFunction(anonymous, fnc, args)
+}
+
+// callFunction calls fnc, which is known as name at the call site.
+func (eva *Evaluator) callFunction(name string, fnc object.Object, args []object.Object) object.Object {
 	switch fnc := fnc.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnvironment(fnc, args)
+		if len(args) != len(fnc.Params) {
+			return newError("wrong number of arguments: need=%d got=%d", len(fnc.Params), len(args))
+		}
+		extendedEnv, err := eva.extendFunctionEnvironment(fnc, args)
+		if err != nil {
+			return err
+		}
+		eva.pushFrame(&Frame{Name: name, File: eva.funcFiles[fnc.Body], Line: fnc.Body.Token.Line, Env: extendedEnv})
+		defer eva.popFrame()
 		value := eva.Eval(fnc.Body, extendedEnv)
 		return unwrapReturnValue(value)
 	case *object.BuiltIn:
-		return fnc.Fnc(args...)
+		eva.builtinCalls++
+		defer func() { eva.builtinCalls-- }()
+		return fnc.Fnc(eva, args...)
 	default:
 		return newError("not a Function %s", fnc.Type())
 	}
 
 }
 
-func extendFunctionEnvironment(fnc *object.Function, args []object.Object) *object.Environment {
+func (eva *Evaluator) extendFunctionEnvironment(fnc *object.Function, args []object.Object) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(fnc.Env)
 	for paramId, param := range fnc.Params {
-		env.Set(param.Value, args[paramId])
+		if err := eva.bindPattern(param, args[paramId], env, false); err != nil {
+			return nil, err
+		}
 	}
-	return env
+	return env, nil
+}
+
+type binding struct {
+	name  string
+	value object.Object
+}
+
+// bindPattern destructures val into pattern and defines the bound names in
+// env. Nothing is bound if the shape does not match.
+func (eva *Evaluator) bindPattern(pattern ast.Pattern, val object.Object, env *object.Environment, constant bool) *object.Error {
+	bindings := []binding{}
+	if err := eva.destructure(pattern, val, &bindings); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, b := range bindings {
+		if _, ok := env.GetLocal(b.name); ok || seen[b.name] {
+			return newError("Variable already initialized: %s", b.name)
+		}
+		seen[b.name] = true
+	}
+	for _, b := range bindings {
+		if constant {
+			env.SetConst(b.name, b.value)
+		} else {
+			env.Set(b.name, b.value)
+		}
+	}
+	return nil
+}
+
+func (eva *Evaluator) destructure(pattern ast.Pattern, val object.Object, bindings *[]binding) *object.Error {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		*bindings = append(*bindings, binding{pattern.Value, val})
+	case *ast.ArrayPattern:
+		arr, ok := val.(*object.Array)
+		if !ok {
+			return newError("cannot destructure %s as array %s", val.Type(), pattern)
+		}
+		need := len(pattern.Elements)
+		if pattern.Rest == nil && len(arr.Elements) != need {
+			return newError("array pattern %s needs %d elements got %d", pattern, need, len(arr.Elements))
+		}
+		if len(arr.Elements) < need {
+			return newError("array pattern %s needs at least %d elements got %d", pattern, need, len(arr.Elements))
+		}
+		for i, element := range pattern.Elements {
+			if err := eva.destructure(element, arr.Elements[i], bindings); err != nil {
+				return err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(arr.Elements)-need)
+			copy(rest, arr.Elements[need:])
+			*bindings = append(*bindings, binding{pattern.Rest.Value, allocated(eva, &object.Array{Elements: rest})})
+		}
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return newError("cannot destructure %s as hash %s", val.Type(), pattern)
+		}
+		used := map[string]bool{}
+		for _, pair := range pattern.Pairs {
+			key := &object.String{Value: pair.KeyName()}
+			entry, ok := hash.Get(key)
+			if !ok {
+				return newError("key not found for hash pattern: %s", key.Value)
+			}
+			used[key.Value] = true
+			if err := eva.destructure(pair.Value, entry.Value, bindings); err != nil {
+				return err
+			}
+		}
+		if pattern.Rest != nil {
+			rest := allocated(eva, object.NewHash())
+			for _, entry := range hash.Pairs() {
+				str, isString := entry.Key.(*object.String)
+				if isString && used[str.Value] {
+					continue
+				}
+				rest.Set(entry.Key.(object.Hashable), entry.Value)
+			}
+			*bindings = append(*bindings, binding{pattern.Rest.Value, rest})
+		}
+	default:
+		return newError("invalid binding pattern: %T", pattern)
+	}
+	return nil
 }
 
 func unwrapReturnValue(val object.Object) object.Object {
@@ -481,6 +833,8 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpr(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpr(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpr(left, index)
 	default:
@@ -488,6 +842,84 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	}
 }
 
+func evalStringIndexExpr(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	ind := index.(*object.Integer).Value
+	if ind < 0 || ind >= int64(len(runes)) {
+		return NULL
+	}
+	return &object.String{Value: string(runes[ind])}
+}
+
+func (eva *Evaluator) evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := eva.Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	var length int64
+	switch left := left.(type) {
+	case *object.String:
+		length = int64(utf8.RuneCountInString(left.Value))
+	case *object.Array:
+		length = int64(len(left.Elements))
+	default:
+		return newError("Slice Operator not supported for %s", left.Type())
+	}
+
+	start, end := int64(0), length
+	if node.Start != nil {
+		bound := eva.evalSliceBound(node.Start, length, env)
+		if isError(bound) {
+			return bound
+		}
+		start = bound.(*object.Integer).Value
+	}
+	if node.End != nil {
+		bound := eva.evalSliceBound(node.End, length, env)
+		if isError(bound) {
+			return bound
+		}
+		end = bound.(*object.Integer).Value
+	}
+	if start > end {
+		start = end
+	}
+
+	switch left := left.(type) {
+	case *object.String:
+		return allocated(eva, &object.String{Value: string([]rune(left.Value)[start:end])})
+	default:
+		elements := left.(*object.Array).Elements[start:end]
+		return allocated(eva, &object.Array{Elements: append([]object.Object{}, elements...)})
+	}
+}
+
+// evalSliceBound evaluates a slice bound and clamps it to [0, length].
+func (eva *Evaluator) evalSliceBound(node ast.Expression, length int64, env *object.Environment) object.Object {
+	bound := eva.Eval(node, env)
+	if isError(bound) {
+		return bound
+	}
+	if bigBound, ok := bound.(*object.BigInteger); ok {
+		if bigBound.Value.Sign() < 0 {
+			return &object.Integer{Value: 0}
+		}
+		return &object.Integer{Value: length}
+	}
+	integer, ok := bound.(*object.Integer)
+	if !ok {
+		return newError("slice index is not an integer: %s", bound.Type())
+	}
+	switch {
+	case integer.Value < 0:
+		return &object.Integer{Value: 0}
+	case integer.Value > length:
+		return &object.Integer{Value: length}
+	default:
+		return integer
+	}
+}
+
 func evalArrayIndexExpr(array, index object.Object) object.Object {
 	arr := array.(*object.Array)
 	ind := index.(*object.Integer).Value
@@ -501,104 +933,195 @@ func evalArrayIndexExpr(array, index object.Object) object.Object {
 func evalHashIndexExpr(hash, index object.Object) object.Object {
 	hashObj := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
+	key, ok := object.AsHashable(index)
 	if !ok {
 		return newError("%s can not be used as HashKey", index.Type())
 	}
-	pair, ok := hashObj.Pairs[key.HashKey()]
+	pair, ok := hashObj.Get(key)
 	if !ok {
 		return NULL
 	}
 	return pair.Value
 }
 
+func (eva *Evaluator) evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		val := eva.Eval(part, env)
+		if isError(val) {
+			return val
+		}
+		out.WriteString(val.Inspect())
+	}
+	return allocated(eva, &object.String{Value: out.String()})
+}
+
 func (eva *Evaluator) evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := allocated(eva, object.NewHash())
 
-	for keyNode, valNode := range node.Pairs {
-		key := eva.Eval(keyNode, env)
+	for _, pair := range node.Pairs {
+		key := eva.Eval(pair.Key, env)
 		if isError(key) {
 			return key
 		}
 
-		hashKey, ok := key.(object.Hashable)
+		hashKey, ok := object.AsHashable(key)
 		if !ok {
 			return newError("%s can not be used as HashKey", key.Type())
 		}
 
-		val := eva.Eval(valNode, env)
+		val := eva.Eval(pair.Value, env)
 		if isError(val) {
 			return val
 		}
-		hash := hashKey.HashKey()
-		pairs[hash] = object.HashPair{Key: key, Value: val}
+		hash.Set(hashKey, val)
 	}
-	return &object.Hash{Pairs: pairs}
+	return hash
 }
 
 func (eva *Evaluator) evalReassignmentStatement(stmt *ast.ReassignmentStatement, env *object.Environment) object.Object {
-	val := eva.Eval(stmt.Value, env)
-	if isError(val) {
-		return val
+	var val object.Object
+	if stmt.Value != nil {
+		val = eva.Eval(stmt.Value, env)
+		if isError(val) {
+			return val
+		}
+	}
+
+	target := eva.resolveAssignTarget(stmt.Left, env)
+	if target.err != nil {
+		return target.err
+	}
+	if stmt.Operator == "=" {
+		return target.set(val)
+	}
+
+	current := target.get()
+	if isError(current) {
+		return current
+	}
+	operator := strings.TrimSuffix(stmt.Operator, "=")
+	switch stmt.Operator {
+	case "++":
+		operator, val = "+", &object.Integer{Value: 1}
+	case "--":
+		operator, val = "-", &object.Integer{Value: 1}
+	}
+	result := allocated(eva, EvalInfixExpr(operator, current, val))
+	if isError(result) {
+		return result
+	}
+	if eva.overflowed(result, current, val) {
+		return newError("integer overflow: %s %s %s", current.Inspect(), operator, val.Inspect())
+	}
+	return target.set(result)
+}
+
+// assignTarget is an assignment target whose sub-expressions have been
+// evaluated, so compound assignments read and write it without evaluating
+// them twice.
+type assignTarget struct {
+	get func() object.Object
+	set func(val object.Object) object.Object
+	err *object.Error
+}
+
+func (eva *Evaluator) resolveAssignTarget(left ast.Expression, env *object.Environment) assignTarget {
+	failed := func(obj object.Object) assignTarget {
+		return assignTarget{err: obj.(*object.Error)}
 	}
 
-	switch left := stmt.Left.(type) {
+	switch left := left.(type) {
 	case *ast.Identifier:
-		_, localOk := env.GetLocal(left.Value)
-		if localOk {
-			env.Set(left.Value, val)
-			return val
+		return assignTarget{
+			get: func() object.Object { return eva.evalIdentifier(left, env) },
+			set: func(val object.Object) object.Object {
+				if env.IsConst(left.Value) {
+					return newPositionedError(left.Token, "cannot assign to constant %s", left.Value)
+				}
+				_, localOk := env.GetLocal(left.Value)
+				if localOk {
+					env.Set(left.Value, val)
+					return val
+				}
+				return env.SetOuter(left.Value, val)
+			},
 		}
-		val = env.SetOuter(left.Value, val)
-		return val
 
 	case *ast.PrefixExpression:
-		if left.Operator == "*" {
-			pointerObj := eva.Eval(left.Right, env)
-			if isError(pointerObj) {
-				return pointerObj
-			}
-			if pointerObj.Type() != object.POINTER_OBJ {
-				return newError("cannot assign through non-pointer type: %s", pointerObj.Type())
-			}
-
-			ptr := pointerObj.(*object.Pointer)
-
-			eva.Heap[ptr.Value] = object.NewHeapOject(val)
-			return val
+		if left.Operator != "*" {
+			return failed(newError("unsupported prefix operator in assignment: %s", left.Operator))
+		}
+		pointerObj := eva.Eval(left.Right, env)
+		if isError(pointerObj) {
+			return failed(pointerObj)
+		}
+		ptr, ok := pointerObj.(*object.Pointer)
+		if !ok {
+			return failed(newError("cannot assign through non-pointer type: %s", pointerObj.Type()))
+		}
+		return assignTarget{
+			get: func() object.Object { return eva.evalDereference(ptr) },
+			set: func(val object.Object) object.Object {
+				eva.Heap[ptr.Value] = object.NewHeapOject(val)
+				return val
+			},
 		}
 
-		return newError("unsupported prefix operator in assignment: %s", left.Operator)
 	case *ast.IndexExpression:
 		arrayObj := eva.Eval(left.Left, env)
 		if isError(arrayObj) {
-			return arrayObj
+			return failed(arrayObj)
 		}
-
 		indexObj := eva.Eval(left.Index, env)
 		if isError(indexObj) {
-			return indexObj
+			return failed(indexObj)
 		}
-		arr, ok := arrayObj.(*object.Array)
-		if ok {
+
+		switch container := arrayObj.(type) {
+		case *object.Array:
 			intIdx, ok := indexObj.(*object.Integer)
 			if !ok {
-				return newError("array index is not an integer: %s", indexObj.Type())
+				return failed(newError("array index is not an integer: %s", indexObj.Type()))
 			}
-
 			idx := intIdx.Value
-			if idx < 0 || idx >= int64(len(arr.Elements)) {
-				return newError("array index out of bounds: %d", idx)
+			if idx < 0 || idx >= int64(len(container.Elements)) {
+				return failed(newError("array index out of bounds: %d", idx))
+			}
+			return assignTarget{
+				get: func() object.Object { return container.Elements[idx] },
+				set: func(val object.Object) object.Object {
+					if container.Frozen {
+						return newPositionedError(left.Token, "cannot modify frozen %s", container.Type())
+					}
+					container.Elements[idx] = val
+					return val
+				},
+			}
+		case *object.Hash:
+			key, ok := object.AsHashable(indexObj)
+			if !ok {
+				return failed(newError("%s can not be used as HashKey", indexObj.Type()))
+			}
+			return assignTarget{
+				get: func() object.Object {
+					if pair, ok := container.Get(key); ok {
+						return pair.Value
+					}
+					return NULL
+				},
+				set: func(val object.Object) object.Object {
+					if container.Frozen {
+						return newPositionedError(left.Token, "cannot modify frozen %s", container.Type())
+					}
+					container.Set(key, val)
+					return val
+				},
 			}
-
-			arr.Elements[idx] = val
-			return val
 		}
-
-		return newError("index assignment not supported for %s", arrayObj.Type())
+		return failed(newError("index assignment not supported for %s", arrayObj.Type()))
 
 	default:
-		return newError("invalid assignment target: %T", left)
+		return failed(newError("invalid assignment target: %T", left))
 	}
-
 }
@@ -36,6 +36,119 @@ func TestEvalIntExpr(t *testing.T) {
 	}
 }
 
+func TestEvalBigIntegerExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-9223372036854775808 / -1", "9223372036854775808"},
+		{"-(-9223372036854775808)", "9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 * 10 / 10", "123456789012345678901234567890"},
+		{"99999999999999999999 - 99999999999999999998", "1"},
+		{"99999999999999999999 / 0", "zero division: 0 / 99999999999999999999"},
+		{"99999999999999999999 + true", "type mismatch: BIG_INTEGER + BOOLEAN"},
+		{`{99999999999999999999: "big"}[99999999999999999999]`, "big"},
+		{`"hello"[1:99999999999999999999]`, "ello"},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		if val.Inspect() != tcase.expected && val.Inspect() != "ERROR: "+tcase.expected {
+			t.Errorf("Wrong result for %q. Expected=%s got=%s", tcase.input, tcase.expected, val.Inspect())
+		}
+	}
+
+	// Results that fit into an int64 again are plain integers.
+	testIntegerObject(t, testEval("9223372036854775807 + 1 - 1"), 9223372036854775807)
+	testIntegerObject(t, testEval("-9223372036854775808"), -9223372036854775808)
+	testBooleanObject(t, testEval("99999999999999999999 > 5"), true)
+	testBooleanObject(t, testEval("-99999999999999999999 < -5"), true)
+	testBooleanObject(t, testEval("9223372036854775807 + 1 == 9223372036854775808"), true)
+	testBooleanObject(t, testEval("[99999999999999999999] == [99999999999999999999]"), true)
+}
+
+func TestStrictIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1", "integer overflow: 9223372036854775807 + 1"},
+		{"let x = 4294967296; x * x", "integer overflow: 4294967296 * 4294967296"},
+		{"-(-9223372036854775808)", "integer overflow: --9223372036854775808"},
+		{"9223372036854775806 + 1", 9223372036854775807},
+		{"99999999999999999999 - 99999999999999999998", 1},
+		{`import "std/math" as m; m.pow(2, 64)`, "integer overflow: pow(2, 64)"},
+		{`import "std/math" as m; m.pow(2, 62)`, 4611686018427387904},
+	}
+
+	for _, tcase := range tests {
+		program := parser.New(lexer.New(tcase.input)).ParseProgram()
+		evaluator := NewEval()
+		evaluator.SetStrictIntegers(true)
+		val := evaluator.Eval(program, object.NewEnvironment())
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			err, ok := val.(*object.Error)
+			if !ok {
+				t.Errorf("Object is not Error got %T(%v)", val, val)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("Unexpected ErrorMessage expected=%s got=%s", expected, err.Message)
+			}
+		}
+	}
+}
+
+func TestEvalBitwiseExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0b1100 & 0b1010", 8},
+		{"0b1100 | 0b1010", 14},
+		{"0b1100 ^ 0b1010", 6},
+		{"~0", -1},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"1024 >> 3", 128},
+		{"-16 >> 2", -4},
+		{"-1 >> 100", -1},
+		{"0xFF & 0x0F << 4", 240},
+		{"let x = 5; &x == &x", false},
+		{"1 << 63", "9223372036854775808"},
+		{"-1 << 63", -9223372036854775808},
+		{"3 << 64", "55340232221128654848"},
+		{"(1 << 64) >> 63", 2},
+		{"(1 << 64) | 1", "18446744073709551617"},
+		{"~(1 << 64)", "-18446744073709551617"},
+		{"1 << -1", "negative shift count: -1"},
+		{"1 >> -1", "negative shift count: -1"},
+		{`~"a"`, "unknown operator: ~STRING"},
+		{"true | false", "unknown operator: BOOLEAN | BOOLEAN"},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case bool:
+			testBooleanObject(t, val, expected)
+		case string:
+			if val.Inspect() != expected && val.Inspect() != "ERROR: "+expected {
+				t.Errorf("Wrong result for %q. Expected=%s got=%s", tcase.input, expected, val.Inspect())
+			}
+		}
+	}
+}
+
 func TestEvalStringExpr(t *testing.T) {
 	input := `"whats up"`
 	val := testEval(input)
@@ -62,6 +175,24 @@ func TestEvalConcatenation(t *testing.T) {
 
 }
 
+func TestEvalInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = 2; let b = 3; "total: ${a + b}"`, "total: 5"},
+		{`"n=${5}"`, "n=5"},
+		{`let name = "chimp"; "hi ${name}, ${upper(name)}!"`, "hi chimp, CHIMP!"},
+		{`"${[1, 2]} ${true} ${if (false) { 1 }}"`, "[1, 2] true null"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`"price: \${x}"`, "price: ${x}"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestEvalBoolExpr(t *testing.T) {
 	tests := []struct {
 		input       string
@@ -94,6 +225,51 @@ func TestEvalBoolExpr(t *testing.T) {
 	}
 }
 
+func TestEvalDeepEquality(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedVal bool
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[] == []", true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} != {"a": 1, "b": 2}`, true},
+		{"if (false) { 1 } == if (false) { 2 }", true},
+		{"1 == true", false},
+		{`1 == "1"`, false},
+		{"[1] == 1", false},
+		{"let a = 3; &a == &a", false},
+		{"let p = &3; p == p", true},
+		{"let f = fnc() { 1 }; f == f", true},
+		{"fnc() { 1 } == fnc() { 1 }", false},
+		{"[1, 2] < [1, 3]", true},
+		{"[1] < [1, 0]", true},
+		{"[2] > [1, 5]", true},
+		{`["a", 1] < ["a", 2]`, true},
+		{`[1] < ["a"]`, true},
+		{"[1, 2] < [1, 2]", false},
+		{"let a = [1]; identical(a, a)", true},
+		{"identical([1], [1])", false},
+		{"identical({}, {})", false},
+		{`identical("a", "a")`, true},
+		{"identical(len, len)", true},
+		{"let a = [1]; a[0] = a; a == a", true},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b", true},
+		{"let a = [1, 1]; a[0] = a; let b = [1, 2]; b[0] = b; a != b", true},
+		{`let h = {}; h["self"] = h; h == h`, true},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a < b", false},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		testBooleanObject(t, val, tcase.expectedVal)
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input       string
@@ -241,6 +417,7 @@ func TestErrorHandling(t *testing.T) {
 		ExpectedErrorMsg string
 	}{
 		{"3 + false", "type mismatch: INTEGER + BOOLEAN"},
+		{`"a${1 + true}b"`, "type mismatch: INTEGER + BOOLEAN"},
 		{"false + true", "unknown operator: BOOLEAN + BOOLEAN"},
 		{"3 + true; 3", "type mismatch: INTEGER + BOOLEAN"},
 		{"-false", "unknown operator: -BOOLEAN"},
@@ -306,6 +483,193 @@ func TestAssignmentStatements(t *testing.T) {
 	}
 }
 
+func TestCompoundAssignmentStatements(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Expected interface{}
+	}{
+		{"let x = 5; x += 2; x", 7},
+		{"let x = 5; x -= 7; x", -2},
+		{"let x = 5; x *= 3; x", 15},
+		{"let x = 17; x /= 5; x", 3},
+		{"let x = 17; x %= 5; x", 2},
+		{"let x = -17; x %= 5; x", -2},
+		{"let i = 0; while (i < 10) { i++; } i", 10},
+		{"let i = 0; i--; i--; i", -2},
+		{"let a = 5--3; a", 8},
+		{"let x = 5; let y = 3; x--y", 8},
+		{"let s = \"ab\"; s += \"cd\"; s", "abcd"},
+		{"let arr = [1, 2, 3]; arr[1] += 10; arr[1]", 12},
+		{"let h = {\"n\": 1}; h[\"n\"] *= 5; h[\"n\"]", 5},
+		{"let x = 1; let p = &x; *p += 4; *p", 5},
+		{"let x = 1; if (true) { x += 1; } x", 2},
+		{"let calls = 0; let arr = [0, 0]; let i = fnc() { calls++; 1 }; arr[i()] += 5; arr[1] + calls", 6},
+		{"let x = 9223372036854775807; x++; x", "9223372036854775808"},
+		{"let x = 5; x /= 0", "zero division: 0 / 5"},
+		{"5 % 0", "zero division: 5 % 0"},
+		{"let h = {}; h[\"n\"] += 1", "type mismatch: NULL + INTEGER"},
+		{"let x = true; x++", "type mismatch: BOOLEAN + INTEGER"},
+		{"y += 1", "identifier not found: y"},
+		{"let arr = [1]; arr[3] -= 1", "array index out of bounds: 3"},
+	}
+	for _, tt := range tests {
+		val := testEval(tt.Input)
+		switch expected := tt.Expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			switch val := val.(type) {
+			case *object.String:
+				testStringObject(t, val, expected)
+			case *object.BigInteger:
+				if val.Inspect() != expected {
+					t.Errorf("wrong big integer. expected=%s got=%s", expected, val.Inspect())
+				}
+			case *object.Error:
+				if val.Message != expected {
+					t.Errorf("wrong error message. expected=%q got=%q", expected, val.Message)
+				}
+			default:
+				t.Errorf("unexpected object for %q: %T(%+v)", tt.Input, val, val)
+			}
+		}
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [first, ...rest] = [1, 2, 3]; first + len(rest) * 10 + rest[1]", 24},
+		{"let [x, ...rest] = [1]; len(rest)", 0},
+		{"let [[a, b], c] = [[1, 2], 3]; a + b + c", 6},
+		{`let {name, age: years} = {"name": "ada", "age": 36}; "${name} ${years}"`, "ada 36"},
+		{`let {"full name": n} = {"full name": "ada"}; n`, "ada"},
+		{`let {a, ...others} = {"a": 1, "b": 2, "c": 3}; keys(others)`, "[b, c]"},
+		{`let {pos: [x, y]} = {"pos": [3, 4]}; x * y`, 12},
+		{"let swap = fnc([a, b]) { [b, a] }; let [x, y] = swap([1, 2]); x * 10 + y", 21},
+		{`let greet = fnc({name}, greeting) { "${greeting} ${name}" }; greet({"name": "bob"}, "hi")`, "hi bob"},
+		{"let divmod = fnc(a, b) { [a / b, a % b] }; let [q, r] = divmod(17, 5); q * 10 + r", 32},
+		{"let [a, b] = [1, 2, 3];", "array pattern [a, b] needs 2 elements got 3"},
+		{"let [a, b, ...c] = [1];", "array pattern [a, b, ...c] needs at least 2 elements got 1"},
+		{"let [a] = 5;", "cannot destructure INTEGER as array [a]"},
+		{`let {a} = [1];`, "cannot destructure ARRAY as hash {a}"},
+		{`let {a, b} = {"a": 1};`, "key not found for hash pattern: b"},
+		{"let [a, a] = [1, 2];", "Variable already initialized: a"},
+		{"let a = 0; let [b, a] = [1, 2];", "Variable already initialized: a"},
+		{"let f = fnc([a, b]) { a }; f([1])", "array pattern [a, b] needs 2 elements got 1"},
+	}
+
+	for _, tt := range tests {
+		val := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			switch val := val.(type) {
+			case *object.String:
+				testStringObject(t, val, expected)
+			case *object.Array:
+				if val.Inspect() != expected {
+					t.Errorf("wrong array. expected=%s got=%s", expected, val.Inspect())
+				}
+			case *object.Error:
+				if val.Message != expected {
+					t.Errorf("wrong error message. expected=%q got=%q", expected, val.Message)
+				}
+			default:
+				t.Errorf("unexpected object for %q: %T(%+v)", tt.input, val, val)
+			}
+		}
+	}
+}
+
+func TestDestructuringFailureBindsNothing(t *testing.T) {
+	env := object.NewEnvironment()
+	eva := NewEval()
+	for _, input := range []string{"let [a, b] = [1, 2, 3];", "let a = 5;"} {
+		eva.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+	}
+	val, _ := env.Get("a")
+	testIntegerObject(t, val, 5)
+	if _, ok := env.Get("b"); ok {
+		t.Errorf("failed destructuring bound b")
+	}
+}
+
+func TestConstBindings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const x = 5; x * 2", 10},
+		{"const x = 5; let f = fnc() { let x = 1; x = 2; x }; f() + x", 7},
+		{"const [a, b] = [1, 2]; a + b", 3},
+		{"const x = 5; x = 6;", "line 1, column 14: cannot assign to constant x"},
+		{"const x = 5;\nx += 1;", "line 2, column 1: cannot assign to constant x"},
+		{"const x = 5; x++", "line 1, column 14: cannot assign to constant x"},
+		{"const x = 5; let f = fnc() { x = 1 }; f()", "line 1, column 30: cannot assign to constant x"},
+		{"const {a} = {\"a\": 1}; a = 2", "line 1, column 23: cannot assign to constant a"},
+		{"const x = 5; let x = 6;", "Variable already initialized: x"},
+	}
+
+	for _, tt := range tests {
+		val := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			err, ok := val.(*object.Error)
+			if !ok {
+				t.Errorf("expected error for %q. got=%T(%+v)", tt.input, val, val)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("wrong error message. expected=%q got=%q", expected, err.Message)
+			}
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = freeze([1, 2]); a[0] + a[1]", 3},
+		{"let a = [1, [2]]; freeze(a); let b = a[1:]; b[0] = 5; b[0]", 5},
+		{"let a = freeze([1, 2, 3]); let t = tail(a); t[0] = 99; a[1]", 2},
+		{"let a = freeze(push(push(push([], 1), 2), 3)); let p = push(a, 4); p[0] = 99; a[0]", 1},
+		{"let a = push(push(push([], 1), 2), 3); let b = push(a, 4); let c = push(a, 5); b[3]", 4},
+		{"let a = freeze([1, 2]); a[0] = 3;", "line 1, column 26: cannot modify frozen ARRAY"},
+		{"let a = freeze([1, [2]]); a[1][0] += 1;", "line 1, column 31: cannot modify frozen ARRAY"},
+		{"let h = freeze({\"k\": 1}); h[\"k\"] = 2;", "line 1, column 28: cannot modify frozen HASH"},
+		{"let h = freeze({\"k\": {\"n\": 1}}); h[\"k\"][\"n\"] = 2;", "line 1, column 40: cannot modify frozen HASH"},
+		{"let h = freeze({\"k\": 1}); delete(h, \"k\")", "cannot modify frozen HASH"},
+		{"const cfg = freeze({\"n\": 1}); let h = merge(cfg, {\"m\": 2}); h[\"n\"] = 5; h[\"n\"]", 5},
+		{"freeze(1, 2)", "invalid number of arguments for `freeze` need=1 got=2"},
+	}
+
+	for _, tt := range tests {
+		val := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			err, ok := val.(*object.Error)
+			if !ok {
+				t.Errorf("expected error for %q. got=%T(%+v)", tt.input, val, val)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("wrong error message. expected=%q got=%q", expected, err.Message)
+			}
+		}
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		Input       string
@@ -315,6 +679,9 @@ func TestLetStatements(t *testing.T) {
 		{"let x = 5 * 5; x", 25},
 		{"let x = 5; let y = x; y", 5},
 		{"let x = 5; let y = x; let z = x + y + 4; z", 14},
+		{"let item2 = 5; let x1 = item2 * 2; x1", 10},
+		{"let größe = 3; let 高さ = 4; größe * 高さ", 12},
+		{"let x: int = 5; let f = fnc(a: int, b: [int]): int { a + len(b) }; f(x, [1, 2])", 7},
 	}
 
 	for _, tcase := range tests {
@@ -336,7 +703,7 @@ func TestFunctionObjects(t *testing.T) {
 	if fnc.Params[0].String() != "x" {
 		t.Fatalf("incorrect Parameter Identifier. Expected = x got = %s", fnc.Params[0].String())
 	}
-	expectedBody := "(x * 3)"
+	expectedBody := "{ (x * 3); }"
 	if fnc.Body.String() != expectedBody {
 		t.Fatalf("Incorrect body. Expected= %s. got= %s", expectedBody, fnc.Body.String())
 	}
@@ -401,6 +768,88 @@ func TestBuiltInFunction(t *testing.T) {
 	}
 }
 
+func TestHigherOrderBuiltInFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`map([1, 2, 3], fnc(x) { x * 2 })`, []int{2, 4, 6}},
+		{`map([], fnc(x) { x * 2 })`, []int{}},
+		{`map([1, true], fnc(x) { -x })`, "unknown operator: -BOOLEAN"},
+		{`map(1, fnc(x) { x })`, "invalid argument for `map` expected ARRAY got INTEGER"},
+		{`map([1], fnc(x, y) { x })`, "wrong number of arguments: need=2 got=1"},
+		{`map([1, 2], len)`, "invalid argument for `len` got INTEGER"},
+		{`filter([1, 2, 3, 4], fnc(x) { x > 2 })`, []int{3, 4}},
+		{`reduce([1, 2, 3, 4], fnc(acc, x) { acc + x }, 10)`, 20},
+		{`reduce([1, 2, 3, 4], fnc(acc, x) { acc * x })`, 24},
+		{`reduce([], fnc(acc, x) { acc * x })`, "`reduce` of empty ARRAY with no initial value"},
+		{`reduce([1])`, "invalid number of arguments for `reduce` need=2 or 3 got=1"},
+		{`sort()`, "invalid number of arguments for `sort` need=1 or 2 got=0"},
+		{`range()`, "invalid number of arguments for `range` need=1 to 3 got=0"},
+		{`find([1, 2, 3, 4], fnc(x) { x > 2 })`, 3},
+		{`find([1, 2], fnc(x) { x > 2 })`, nil},
+		{`any([1, 2, 3], fnc(x) { x == 2 })`, true},
+		{`any([], fnc(x) { true })`, false},
+		{`all([1, 2, 3], fnc(x) { x > 0 })`, true},
+		{`all([1, 2, 3], fnc(x) { x > 1 })`, false},
+		{`sort([3, 1, 2])`, []int{1, 2, 3}},
+		{`sort([3, 1, 2], fnc(a, b) { a > b })`, []int{3, 2, 1}},
+		{`sort([3, 1, 2], fnc(a, b) { a - b })`, []int{1, 2, 3}},
+		{`sort([[2], [1, 5], [1]])[0]`, []int{1}},
+		{`sort(["b", 2, "a", 1])[1]`, 2},
+		{`sort([1, 2], fnc(a, b) { "a" })`, "invalid comparator result for `sort` expected BOOLEAN or INTEGER got STRING"},
+		{`let arr = [2, 1]; sort(arr); arr`, []int{2, 1}},
+		{`reverse([1, 2, 3])`, []int{3, 2, 1}},
+		{`flatten([1, [2, 3], [], [4]])`, []int{1, 2, 3, 4}},
+		{`len(zip([1, 2, 3], [4, 5]))`, 2},
+		{`zip([1, 2, 3], [4, 5])[1]`, []int{2, 5}},
+		{`range(4)`, []int{0, 1, 2, 3}},
+		{`range(2, 5)`, []int{2, 3, 4}},
+		{`range(5, 0, -2)`, []int{5, 3, 1}},
+		{`range(1, 2, 0)`, "`range` step must not be zero"},
+		{`range(100000000000000)`, "too many elements for `range`: 100000000000000"},
+		{`range(-9223372036854775807 - 1, 9223372036854775807, 4611686018427387904)`,
+			[]int{-9223372036854775808, -4611686018427387904, 0, 4611686018427387904}},
+		{`range(9223372036854775806, 9223372036854775807, 5)`, []int{9223372036854775806}},
+		{`range(3, 5, -1)`, []int{}},
+		{`concat([1], [], [2, 3])`, []int{1, 2, 3}},
+	}
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case bool:
+			testBooleanObject(t, val, expected)
+		case string:
+			err, ok := val.(*object.Error)
+			if !ok {
+				t.Errorf("Object is not Error got %T(%v)", val, val)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("Unexpected ErrorMessage expected=%s got=%s", expected, err.Message)
+			}
+		case []int:
+			arr, ok := val.(*object.Array)
+			if !ok {
+				t.Errorf("Object is not Array got=%T(%v)", val, val)
+				continue
+			}
+			if len(arr.Elements) != len(expected) {
+				t.Errorf("Wrong number of elements expected=%d got=%d", len(expected), len(arr.Elements))
+				continue
+			}
+			for i, integer := range expected {
+				testIntegerObject(t, arr.Elements[i], int64(integer))
+			}
+		default:
+			testNullObject(t, val)
+		}
+	}
+}
+
 func TestArrayEval(t *testing.T) {
 	input := "[1, 3 + 6, 0 * 5, 7 - 0]"
 	val := testEval(input)
@@ -476,6 +925,131 @@ func TestArrayIndexExpr(t *testing.T) {
 	}
 }
 
+func TestStringIndexAndSliceExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"hello"[0]`, "h"},
+		{`"hello"[4]`, "o"},
+		{`"hello"[5]`, nil},
+		{`"hello"[-1]`, nil},
+		{`"größe"[2]`, "ö"},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[:2]`, "he"},
+		{`"hello"[3:]`, "lo"},
+		{`"hello"[:]`, "hello"},
+		{`"hello"[4:2]`, ""},
+		{`"hello"[-3:99]`, "hello"},
+		{`"größe"[1:4]`, "röß"},
+		{`len([1, 2, 3, 4][1:3])`, 2},
+		{`[1, 2, 3, 4][2:][0]`, 3},
+		{`"hello"["a":]`, "slice index is not an integer: STRING"},
+		{`5[1:2]`, "Slice Operator not supported for INTEGER"},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			if err, ok := val.(*object.Error); ok {
+				if err.Message != expected {
+					t.Errorf("Unexpected ErrorMessage expected=%s got=%s", expected, err.Message)
+				}
+				continue
+			}
+			testStringObject(t, val, expected)
+		default:
+			testNullObject(t, val)
+		}
+	}
+}
+
+func TestEvalStringComparison(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedVal bool
+	}{
+		{`"abc" == "abc"`, true},
+		{`"abc" == "abd"`, false},
+		{`"abc" != "abd"`, true},
+		{`"ab" + "c" == "abc"`, true},
+		{`"abc" < "abd"`, true},
+		{`"b" > "abc"`, true},
+		{`"b" < "abc"`, false},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		testBooleanObject(t, val, tcase.expectedVal)
+	}
+}
+
+func TestStringBuiltInFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("größe")`, 5},
+		{`split("a,b,c", ",")`, []string{"a", "b", "c"}},
+		{`split("  a  b c ")`, []string{"a", "b", "c"}},
+		{`split(1, ",")`, "invalid argument for `split` expected STRING got INTEGER"},
+		{`split("a", ",", "b")`, "invalid number of arguments for `split` need=1 or 2 got=3"},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join([], "-")`, ""},
+		{`join(["a", 1], "-")`, "invalid argument for `join` expected STRING got INTEGER"},
+		{`trim("  hi there  ")`, "hi there"},
+		{`upper("größe")`, "GRÖßE"},
+		{`lower("ÄBC")`, "äbc"},
+		{`contains("hello", "ell")`, true},
+		{`contains("hello", "xyz")`, false},
+		{`index_of("größe", "e")`, 4},
+		{`index_of("hello", "z")`, -1},
+		{`replace("a-b-c", "-", "+")`, "a+b+c"},
+		{`starts_with("hello", "he")`, true},
+		{`ends_with("hello", "he")`, false},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", -1)`, "negative repeat count for `repeat`: -1"},
+		{`repeat("ab", 100000000000000)`, "repeat count too large for `repeat`: 100000000000000"},
+		{`len(repeat("", 100000000000000))`, 0},
+		{`chars("añb")`, []string{"a", "ñ", "b"}},
+		{`upper("a", "b")`, "invalid number of arguments for `upper` need=1 got=2"},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case bool:
+			testBooleanObject(t, val, expected)
+		case string:
+			if err, ok := val.(*object.Error); ok {
+				if err.Message != expected {
+					t.Errorf("Unexpected ErrorMessage expected=%s got=%s", expected, err.Message)
+				}
+				continue
+			}
+			testStringObject(t, val, expected)
+		case []string:
+			arr, ok := val.(*object.Array)
+			if !ok {
+				t.Errorf("Object is not Array got=%T(%v)", val, val)
+				continue
+			}
+			if len(arr.Elements) != len(expected) {
+				t.Errorf("Wrong number of elements expected=%d got=%d", len(expected), len(arr.Elements))
+				continue
+			}
+			for i, str := range expected {
+				testStringObject(t, arr.Elements[i], str)
+			}
+		}
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let hi = "hiya"
 	{
@@ -491,25 +1065,59 @@ func TestHashLiterals(t *testing.T) {
 	if !ok {
 		t.Fatalf("Wrong Object Type expected Hash got=%T (%v)", val, val)
 	}
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "age"}).HashKey():  23,
-		(&object.String{Value: "year"}).HashKey(): 2024,
-		(&object.String{Value: "hiya"}).HashKey(): 45,
-		(&object.Integer{Value: 24}).HashKey():    24,
-		FALSE.HashKey():                           8,
-		TRUE.HashKey():                            23,
+	expected := []struct {
+		key object.Hashable
+		val int64
+	}{
+		{&object.String{Value: "age"}, 23},
+		{&object.String{Value: "year"}, 2024},
+		{&object.String{Value: "hiya"}, 45},
+		{&object.Integer{Value: 24}, 24},
+		{FALSE, 8},
+		{TRUE, 23},
 	}
 
-	if len(hash.Pairs) != len(expected) {
-		t.Fatalf("Incorrect Number of Pairs in Hash. Expected %d got %d", len(hash.Pairs), len(expected))
+	if hash.Len() != len(expected) {
+		t.Fatalf("Incorrect Number of Pairs in Hash. Expected %d got %d", len(expected), hash.Len())
 	}
 
-	for expKey, expVal := range expected {
-		pair, ok := hash.Pairs[expKey]
+	for i, exp := range expected {
+		pair, ok := hash.Get(exp.key)
 		if !ok {
 			t.Errorf("Missing Pair for given Key in Pairs")
 		}
-		testIntegerObject(t, pair.Value, expVal)
+		testIntegerObject(t, pair.Value, exp.val)
+		if ordered := hash.Pairs()[i]; ordered.Key.Inspect() != exp.key.Inspect() {
+			t.Errorf("Pair %d out of insertion order. Expected key %s got %s", i, exp.key.Inspect(), ordered.Key.Inspect())
+		}
+	}
+}
+
+func TestHashInsertionOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"b": 1, "a": 2, 3: 3, true: 4}`, "{b: 1, a: 2, 3: 3, true: 4}"},
+		{`{"b": 1, "a": 2, "b": 3}`, "{b: 3, a: 2}"},
+		{`let h = {"z": 1}; h["y"] = 2; h["x"] = 3; h["z"] = 4; h`, "{z: 4, y: 2, x: 3}"},
+		{`let h = {"a": 1, "b": 2, "c": 3}; delete(h, "a"); h["a"] = 5; h`, "{b: 2, c: 3, a: 5}"},
+		{`keys({"c": 1, "a": 2, "b": 3})`, "[c, a, b]"},
+		{`values(merge({"c": 1, "a": 2}, {"b": 3, "c": 4}))`, "[4, 2, 3]"},
+		{`let h = {"m": 1, "c": 2, "x": 3, "a": 4}; delete(h, "c"); delete(h, "m"); h["c"] = 5; h["m"] = 6; [h, keys(h)]`,
+			"[{x: 3, a: 4, c: 5, m: 6}, [x, a, c, m]]"},
+		{`let h = {"b": 1, "a": 2}; delete(h, "b"); h["b"] = 3; entries(h)`, "[[a, 2], [b, 3]]"},
+		{`let h = {}; let i = 20; while (i > 0) { h[i] = i * i; i -= 1; } keys(h)`,
+			"[20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1]"},
+		{`let h = {}; let i = 20; while (i > 0) { h[i] = i; i -= 1; } let j = 20; while (j > 0) { delete(h, j); j -= 2; } h[20] = 0; h`,
+			"{19: 19, 17: 17, 15: 15, 13: 13, 11: 11, 9: 9, 7: 7, 5: 5, 3: 3, 1: 1, 20: 0}"},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		if val.Inspect() != tcase.expected {
+			t.Errorf("wrong Inspect output for %s.\nexpected=%s\ngot=     %s", tcase.input, tcase.expected, val.Inspect())
+		}
 	}
 }
 
@@ -546,6 +1154,22 @@ func TestHashIndexExpr(t *testing.T) {
 			"{false: 9}[false]",
 			9,
 		},
+		{
+			"{-1: 1, -2: 2}[-2]",
+			2,
+		},
+		{
+			`{[1, "a"]: 5}[[1, "a"]]`,
+			5,
+		},
+		{
+			`{[1, "a"]: 5}[["a", 1]]`,
+			nil,
+		},
+		{
+			`{[]: 7}[[]]`,
+			7,
+		},
 	}
 
 	for _, tt := range tests {
@@ -559,6 +1183,91 @@ func TestHashIndexExpr(t *testing.T) {
 	}
 }
 
+func TestHashIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let h = {}; h["a"] = 1; h["a"]`, 1},
+		{`let h = {"a": 1}; h["a"] = 5; h["a"]`, 5},
+		{`let h = {"a": 1}; h["b"] = 2; len(h)`, 2},
+		{`let h = {}; h[3] = 4; h[3]`, 4},
+		{`let h = {}; h[{}] = 4;`, "HASH can not be used as HashKey"},
+		{`let h = {}; h[[1, len]] = 4;`, "ARRAY can not be used as HashKey"},
+		{`let a = [1]; a[0] = a; let h = {}; h[a] = 1;`, "ARRAY can not be used as HashKey"},
+		{`let a = [1]; a[0] = [a]; {a: 1}`, "ARRAY can not be used as HashKey"},
+		{`let h = {}; h[[1, "a"]] = 4; h[[1, "a"]]`, 4},
+		{`let h = {}; let k = [1, 2]; h[k] = 4; k[0] = 9; h[[1, 2]]`, 4},
+		{`let h = {}; let p = &h; (*p)["k"] = 9; h["k"]`, 9},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			err, ok := val.(*object.Error)
+			if !ok {
+				t.Errorf("Object is not Error got %T(%v)", val, val)
+				continue
+			}
+			if err.Message != expected {
+				t.Errorf("Unexpected ErrorMessage expected=%s got=%s", expected, err.Message)
+			}
+		}
+	}
+}
+
+func TestHashBuiltInFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len({"a": 1, "b": 2})`, 2},
+		{`len(keys({"a": 1, "b": 2}))`, 2},
+		{`keys({"a": 1})[0]`, "a"},
+		{`values({"a": 1})[0]`, 1},
+		{`reduce(values({"a": 1, "b": 2, "c": 3}), fnc(acc, x) { acc + x })`, 6},
+		{`entries({"a": 1})[0][0]`, "a"},
+		{`entries({"a": 1})[0][1]`, 1},
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`has({"a": 1}, {})`, "HASH can not be used as HashKey"},
+		{`has({[1, [2, 3]]: 1}, [1, [2, 3]])`, true},
+		{`has({[1, [2, 3]]: 1}, [1, [2]])`, false},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a")`, 1},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a"); has(h, "a")`, false},
+		{`let h = {"a": 1, "b": 2}; delete(h, "a"); len(h)`, 1},
+		{`delete({}, "a")`, nil},
+		{`merge({"a": 1, "b": 2}, {"b": 3})["b"]`, 3},
+		{`len(merge({"a": 1}, {"b": 3}, {}))`, 2},
+		{`let h = {"a": 1}; merge(h, {"b": 2}); len(h)`, 1},
+		{`merge({}, 1)`, "invalid argument for `merge` expected HASH got INTEGER"},
+		{`keys([])`, "invalid argument for `keys` expected HASH got ARRAY"},
+	}
+
+	for _, tcase := range tests {
+		val := testEval(tcase.input)
+		switch expected := tcase.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case bool:
+			testBooleanObject(t, val, expected)
+		case string:
+			if err, ok := val.(*object.Error); ok {
+				if err.Message != expected {
+					t.Errorf("Unexpected ErrorMessage expected=%s got=%s", expected, err.Message)
+				}
+				continue
+			}
+			testStringObject(t, val, expected)
+		default:
+			testNullObject(t, val)
+		}
+	}
+}
+
 func TestGarbageCollection(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -678,6 +1387,23 @@ func TestGC_ClosureCapturingPointer(t *testing.T) {
 	}
 }
 
+// The pointers map has collected are only in its result, so they must
+// survive the collections their callbacks start.
+func TestGC_BuiltinCallbacks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let ps = map(range(150), fnc(i) { &i }); *ps[0]", 0},
+		{"let ps = map(range(250), fnc(i) { &i }); *ps[0] + *ps[249]", 249},
+		{"let ps = reduce(range(150), fnc(acc, i) { push(acc, &i) }, []); *ps[0]", 0},
+		{"let ps = map(range(150), fnc(i) { map([i], fnc(j) { &j }) }); *ps[0][0]", 0},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func testEval(input string) object.Object {
 	lex := lexer.New(input)
 	parser := parser.New(lex)
@@ -713,6 +1439,19 @@ func testIntegerObject(t *testing.T, integerObject object.Object, expectedInt in
 	return true
 }
 
+func testStringObject(t *testing.T, stringObject object.Object, expected string) bool {
+	res, ok := stringObject.(*object.String)
+	if !ok {
+		t.Errorf("Object is not stringObject. got=%T (%v)", stringObject, stringObject)
+		return false
+	}
+	if res.Value != expected {
+		t.Errorf("Unexpected Value of StringObject. Expected=%q, got=%q", expected, res.Value)
+		return false
+	}
+	return true
+}
+
 func testNullObject(t *testing.T, nullObject object.Object) bool {
 	if nullObject != NULL {
 		t.Errorf("Nullobject is not NULL. got=%T (%v)", nullObject, nullObject)
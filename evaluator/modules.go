@@ -0,0 +1,191 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/stdlib"
+)
+
+// ModuleExtension is appended to import paths that have no extension.
+const ModuleExtension = ".chimp"
+
+// SetSearchPath sets the directories searched for imports that are not
+// relative to the importing file.
+func (ev *Evaluator) SetSearchPath(dirs ...string) {
+	ev.SearchPath = dirs
+}
+
+// RunFile evaluates the script at path in a fresh environment. Relative
+// imports in it resolve against its directory.
+func (eva *Evaluator) RunFile(path string) object.Object {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return newError("%s", err)
+	}
+	src, err := os.ReadFile(abs)
+	if err != nil {
+		return newError("%s", err)
+	}
+	program, errObj := parseModule(abs, string(src))
+	if errObj != nil {
+		return errObj
+	}
+	eva.importStack = append(eva.importStack, abs)
+	defer func() { eva.importStack = eva.importStack[:len(eva.importStack)-1] }()
+	return eva.Eval(program, object.NewEnvironment())
+}
+
+func parseModule(path, src string) (*ast.Program, *object.Error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, newError("parse errors in %s: %s", filepath.Base(path), strings.Join(p.Errors(), "; "))
+	}
+	return program, nil
+}
+
+func (eva *Evaluator) evalImportStatement(stmt *ast.ImportStatement, env *object.Environment) object.Object {
+	path, errObj := eva.resolveModule(stmt.Path.Value)
+	if errObj != nil {
+		return errObj
+	}
+	mod, errObj := eva.loadModule(path)
+	if errObj != nil {
+		return errObj
+	}
+
+	bindings := []binding{}
+	if stmt.Alias != nil {
+		bindings = append(bindings, binding{stmt.Alias.Value, mod})
+	}
+	for _, name := range stmt.Names {
+		val, ok := mod.Get(name.Name.Value)
+		if !ok {
+			return newPositionedError(name.Name.Token, "module %s has no export %s", stmt.Path.Value, name.Name.Value)
+		}
+		bindings = append(bindings, binding{name.Binding().Value, val})
+	}
+	for _, b := range bindings {
+		if _, ok := env.GetLocal(b.name); ok {
+			return newError("Variable already initialized: %s", b.name)
+		}
+	}
+	for _, b := range bindings {
+		env.SetConst(b.name, b.value)
+	}
+	return nil
+}
+
+// resolveModule finds the file an import refers to. Paths starting with ./
+// or ../ are relative to the importing file, std/ paths name standard
+// library modules and others are looked up next to the importing file and
+// then in the search path.
+func (eva *Evaluator) resolveModule(spec string) (string, *object.Error) {
+	if strings.HasPrefix(spec, stdlib.Prefix) {
+		if _, ok := stdlib.Source(spec); !ok {
+			return "", newError("module not found: %s", spec)
+		}
+		return strings.TrimSuffix(spec, ModuleExtension), nil
+	}
+	name := filepath.FromSlash(spec)
+	if filepath.Ext(name) == "" {
+		name += ModuleExtension
+	}
+	if filepath.IsAbs(name) {
+		return name, nil
+	}
+
+	dir := "."
+	if len(eva.importStack) > 0 {
+		dir = filepath.Dir(eva.importStack[len(eva.importStack)-1])
+	}
+	candidates := []string{filepath.Join(dir, name)}
+	if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+		for _, searchDir := range eva.SearchPath {
+			candidates = append(candidates, filepath.Join(searchDir, name))
+		}
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			abs, err := filepath.Abs(candidate)
+			if err != nil {
+				return "", newError("%s", err)
+			}
+			return abs, nil
+		}
+	}
+	return "", newError("module not found: %s", spec)
+}
+
+// loadModule evaluates the module at path once and caches it. Importing a
+// module that is still being evaluated is an import cycle.
+func (eva *Evaluator) loadModule(path string) (*object.Module, *object.Error) {
+	if mod, ok := eva.modules[path]; ok {
+		return mod, nil
+	}
+	for i, importing := range eva.importStack {
+		if importing == path {
+			cycle := []string{}
+			for _, p := range eva.importStack[i:] {
+				cycle = append(cycle, filepath.Base(p))
+			}
+			cycle = append(cycle, filepath.Base(path))
+			return nil, newError("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	mod := &object.Module{Path: path, Env: object.NewEnvironment()}
+	src, isStd := stdlib.Source(path)
+	if isStd {
+		mod.Env.SetConst("native", nativeModule())
+	} else {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, newError("%s", err)
+		}
+		src = string(file)
+	}
+	program, errObj := parseModule(path, src)
+	if errObj != nil {
+		return nil, errObj
+	}
+	eva.importStack = append(eva.importStack, path)
+	defer func() { eva.importStack = eva.importStack[:len(eva.importStack)-1] }()
+
+	result := eva.Eval(program, mod.Env)
+	if err, ok := result.(*object.Error); ok {
+		return nil, err
+	}
+	for _, stmt := range program.Statements {
+		export, ok := stmt.(*ast.ExportStatement)
+		if !ok {
+			continue
+		}
+		for _, name := range export.Names {
+			if _, ok := mod.Env.GetLocal(name.Value); !ok {
+				return nil, newPositionedError(name.Token, "module %s exports undefined name %s", filepath.Base(path), name.Value)
+			}
+			mod.Exports = append(mod.Exports, name.Value)
+		}
+	}
+	eva.modules[path] = mod
+	return mod, nil
+}
+
+func evalMemberExpression(node *ast.MemberExpression, obj object.Object) object.Object {
+	mod, ok := obj.(*object.Module)
+	if !ok {
+		return newPositionedError(node.Token, "member access not supported for %s", obj.Type())
+	}
+	val, ok := mod.Get(node.Property.Value)
+	if !ok {
+		return newPositionedError(node.Property.Token, "module %s has no export %s", filepath.Base(mod.Path), node.Property.Value)
+	}
+	return val
+}
@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+// writeModules creates the given files below a temporary directory.
+func writeModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestImports(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"lib/math.chimp": `
+			let square = fnc(x) { x * x };
+			let secret = 42;
+			let cube = fnc(x) { x * square(x) };
+			export { square, cube };`,
+		"lib/counter.chimp": `
+			let count = 0;
+			let next = fnc() { count++; count };
+			export { next };`,
+		"lib/uses_counter.chimp": `
+			import { next } from "./counter";
+			let first = next();
+			export { first };`,
+		"vendor/greet.chimp": `
+			let hello = fnc(name) { "hello ${name}" };
+			export { hello };`,
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "lib/math.chimp" as m; m.square(4) + m.cube(2)`, 24},
+		{`import "lib/math" as m; m.square(3)`, 9},
+		{`import { square, cube as c } from "lib/math"; square(2) + c(3)`, 31},
+		{`import "lib/counter" as a; import "lib/counter" as b; a.next(); b.next()`, 2},
+		{`import "lib/counter" as c; import "lib/uses_counter" as u; c.next(); u.first`, 1},
+		{`import "greet" as g; g.hello("chimp")`, "hello chimp"},
+		{`import "lib/math" as m; m.secret`, "line 1, column 27: module math.chimp has no export secret"},
+		{`import { secret } from "lib/math";`, "line 1, column 10: module lib/math has no export secret"},
+		{`import "lib/missing" as m;`, "module not found: lib/missing"},
+		{`import "./greet" as g;`, "module not found: ./greet"},
+		{`let m = 1; import "lib/math" as m;`, "Variable already initialized: m"},
+		{`import "lib/math" as m; m = 1;`, "line 1, column 25: cannot assign to constant m"},
+		{`let h = {}; h.x`, "line 1, column 14: member access not supported for HASH"},
+		{`if (true) { export { x }; }`, "line 1, column 13: export is only allowed at the top level of a module"},
+	}
+
+	for _, tt := range tests {
+		script := filepath.Join(dir, "main.chimp")
+		if err := os.WriteFile(script, []byte(tt.input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		eva := NewEval()
+		eva.SetSearchPath(filepath.Join(dir, "vendor"))
+		val := eva.RunFile(script)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, val, int64(expected))
+		case string:
+			switch val := val.(type) {
+			case *object.String:
+				testStringObject(t, val, expected)
+			case *object.Error:
+				if val.Message != expected {
+					t.Errorf("wrong error message. expected=%q got=%q", expected, val.Message)
+				}
+			default:
+				t.Errorf("unexpected object for %q: %T(%+v)", tt.input, val, val)
+			}
+		}
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"a.chimp":      `import "b" as b; let x = 1; export { x };`,
+		"b.chimp":      `import "a" as a; let y = 2; export { y };`,
+		"self.chimp":   `import "self" as s;`,
+		"broken.chimp": `let = 5;`,
+		"undef.chimp":  `export { nothing };`,
+		"fails.chimp":  `let x = 1 + true; export { x };`,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "a" as a;`, "import cycle: a.chimp -> b.chimp -> a.chimp"},
+		{`import "self" as s;`, "import cycle: self.chimp -> self.chimp"},
+		{`import "broken" as b;`, "parse errors in broken.chimp: "},
+		{`import "undef" as u;`, "line 1, column 10: module undef.chimp exports undefined name nothing"},
+		{`import "fails" as f;`, "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		eva := NewEval()
+		script := filepath.Join(dir, "main.chimp")
+		if err := os.WriteFile(script, []byte(tt.input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err, ok := eva.RunFile(script).(*object.Error)
+		if !ok {
+			t.Errorf("expected error for %q", tt.input)
+			continue
+		}
+		if !strings.HasPrefix(err.Message, tt.expected) {
+			t.Errorf("wrong error message. expected prefix %q got=%q", tt.expected, err.Message)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package evaluator
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+// natives are Go implementations backing the standard library. Std modules
+// see them as the module native; user scripts can not import them.
+var natives = map[string]*object.BuiltIn{
+	"pow": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `pow` need=%d got=%d", 2, len(args))
+			}
+			base, ok := object.ToBigInt(args[0])
+			if !ok {
+				return newError("invalid argument for `pow` expected INTEGER got %s", args[0].Type())
+			}
+			exp, ok := object.ToBigInt(args[1])
+			if !ok {
+				return newError("invalid argument for `pow` expected INTEGER got %s", args[1].Type())
+			}
+			if exp.Sign() < 0 {
+				return newError("negative exponent for `pow`: %s", exp)
+			}
+			if exp.BitLen() > 32 && base.CmpAbs(big.NewInt(1)) > 0 {
+				return newError("exponent too large for `pow`: %s", exp)
+			}
+			result := object.NewInteger(new(big.Int).Exp(base, exp, nil))
+			if eva, ok := caller.(*Evaluator); ok && eva.overflowed(result, args...) {
+				return newError("integer overflow: pow(%s, %s)", args[0].Inspect(), args[1].Inspect())
+			}
+			return allocated(caller, result)
+		},
+	},
+	"isqrt": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `isqrt` need=%d got=%d", 1, len(args))
+			}
+			n, ok := object.ToBigInt(args[0])
+			if !ok {
+				return newError("invalid argument for `isqrt` expected INTEGER got %s", args[0].Type())
+			}
+			if n.Sign() < 0 {
+				return newError("square root of negative number: %s", n)
+			}
+			return allocated(caller, object.NewInteger(new(big.Int).Sqrt(n)))
+		},
+	},
+	"gcd": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `gcd` need=%d got=%d", 2, len(args))
+			}
+			a, ok := object.ToBigInt(args[0])
+			if !ok {
+				return newError("invalid argument for `gcd` expected INTEGER got %s", args[0].Type())
+			}
+			b, ok := object.ToBigInt(args[1])
+			if !ok {
+				return newError("invalid argument for `gcd` expected INTEGER got %s", args[1].Type())
+			}
+			a, b = new(big.Int).Abs(a), new(big.Int).Abs(b)
+			return allocated(caller, object.NewInteger(new(big.Int).GCD(nil, nil, a, b)))
+		},
+	},
+	"count": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `count` need=%d got=%d", 2, len(args))
+			}
+			strs, err := stringArgs("count", args)
+			if err != nil {
+				return err
+			}
+			if strs[1] == "" {
+				return newError("`count` needs a non-empty substring")
+			}
+			return &object.Integer{Value: int64(strings.Count(strs[0], strs[1]))}
+		},
+	},
+	"parse_int": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("invalid number of arguments for `parse_int` need=1 or 2 got=%d", len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("invalid argument for `parse_int` expected STRING got %s", args[0].Type())
+			}
+			base := 10
+			if len(args) == 2 {
+				b, ok := args[1].(*object.Integer)
+				if !ok || b.Value < 2 || b.Value > 36 {
+					return newError("invalid base for `parse_int`: %s", args[1].Inspect())
+				}
+				base = int(b.Value)
+			}
+			n, ok := new(big.Int).SetString(strings.TrimSpace(str.Value), base)
+			if !ok {
+				return NULL
+			}
+			return allocated(caller, object.NewInteger(n))
+		},
+	},
+	"format_int": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("invalid number of arguments for `format_int` need=%d got=%d", 2, len(args))
+			}
+			n, ok := object.ToBigInt(args[0])
+			if !ok {
+				return newError("invalid argument for `format_int` expected INTEGER got %s", args[0].Type())
+			}
+			b, ok := args[1].(*object.Integer)
+			if !ok || b.Value < 2 || b.Value > 36 {
+				return newError("invalid base for `format_int`: %s", args[1].Inspect())
+			}
+			return allocated(caller, &object.String{Value: n.Text(int(b.Value))})
+		},
+	},
+	"fail": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `fail` need=%d got=%d", 1, len(args))
+			}
+			if str, ok := args[0].(*object.String); ok {
+				return newError("%s", str.Value)
+			}
+			return newError("%s", args[0].Inspect())
+		},
+	},
+	// try calls fnc without arguments and returns [result, null], or
+	// [null, message] if it failed.
+	"try": &object.BuiltIn{
+		Fnc: func(caller object.Caller, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("invalid number of arguments for `try` need=%d got=%d", 1, len(args))
+			}
+			result := caller.CallFunction(args[0])
+			if err, ok := result.(*object.Error); ok {
+				return allocated(caller, &object.Array{Elements: []object.Object{NULL, allocated(caller, &object.String{Value: err.Message})}})
+			}
+			if result == nil {
+				result = NULL
+			}
+			return allocated(caller, &object.Array{Elements: []object.Object{result, NULL}})
+		},
+	},
+}
+
+// nativeModule exposes natives to a standard library module.
+func nativeModule() *object.Module {
+	env := object.NewEnvironment()
+	exports := []string{}
+	for name, fnc := range natives {
+		env.Set(name, fnc)
+		exports = append(exports, name)
+	}
+	sort.Strings(exports)
+	return &object.Module{Path: "native", Env: env, Exports: exports}
+}
@@ -0,0 +1,37 @@
+package evaluator
+
+import (
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+// Profiler is told about the frames, statements and allocations of a
+// program as they happen. Enter and Leave bracket each frame on the stack
+// returned by Stack, and Statement and Allocate happen in the innermost one.
+// Allocate reports every array, hash, string, big integer, function and
+// pointer created, whether by an expression or a builtin.
+type Profiler interface {
+	Enter(frame *Frame)
+	Leave(frame *Frame)
+	Statement(stmt ast.Statement)
+	Allocate(obj object.Object)
+}
+
+// allocated reports obj to the profiler of caller and returns it. Integers,
+// booleans and null are values rather than allocations and are left out.
+func allocated[T object.Object](caller object.Caller, obj T) T {
+	eva, ok := caller.(*Evaluator)
+	if !ok || eva.profiler == nil {
+		return obj
+	}
+	switch any(obj).(type) {
+	case *object.Array, *object.Hash, *object.String, *object.BigInteger, *object.Function, *object.Pointer:
+		eva.profiler.Allocate(obj)
+	}
+	return obj
+}
+
+// SetProfiler makes the evaluator report to p. A nil profiler removes it.
+func (eva *Evaluator) SetProfiler(p Profiler) {
+	eva.profiler = p
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/parser"
+)
+
+func TestStdlibModules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "std/math" as m; [m.abs(-3), m.sign(-9), m.min(2, 1), m.max(2, 1), m.clamp(15, 0, 10)]`, "[3, -1, 1, 2, 10]"},
+		{`import "std/math" as m; [m.sum([1, 2, 3]), m.product([2, 3, 4]), m.factorial(5), m.is_even(4), m.is_odd(4)]`, "[6, 24, 120, true, false]"},
+		{`import "std/math" as m; [m.pow(2, 10), m.isqrt(17), m.gcd(-12, 18), m.lcm(4, 6)]`, "[1024, 4, 6, 12]"},
+		{`import { pow } from "std/math"; pow(2, 100)`, "1267650600228229401496703205376"},
+		{`import "std/math" as m; m.pow(2, -1)`, "ERROR: negative exponent for `pow`: -1"},
+		{`import "std/strings" as s; [s.is_empty(""), s.capitalize("chimp"), s.title("hello big world"), s.words(" a  b ")]`, "[true, Chimp, Hello Big World, [a, b]]"},
+		{`import "std/strings" as s; [s.pad_left("7", 3, "0"), s.pad_right("ab", 4, "."), s.pad_left("long", 2, " ")]`, "[007, ab.., long]"},
+		{`import "std/strings" as s; [s.count("banana", "an"), s.parse_int("ff", 16), s.parse_int(" 42 "), s.parse_int("x"), s.format_int(255, 2)]`, "[2, 255, 42, null, 11111111]"},
+		{`import "std/strings" as s; s.parse_int()`, "ERROR: invalid number of arguments for `parse_int` need=1 or 2 got=0"},
+		{`import "std/collections" as c; [c.take([1, 2, 3], 2), c.drop([1, 2, 3], 2), c.take([1], 5), c.uniq([3, 1, 3, 2, 1])]`, "[[1, 2], [3], [1], [3, 1, 2]]"},
+		{`import "std/collections" as c; c.frequencies(["a", "b", "a"])`, "{a: 2, b: 1}"},
+		{`import "std/collections" as c; c.group_by([1, 2, 3, 4, 5], fnc(x) { x % 2 })`, "{1: [1, 3, 5], 0: [2, 4]}"},
+		{`import "std/collections" as c; c.index_by(["ab", "c"], len)`, "{2: ab, 1: c}"},
+		{`import "std/collections" as c; [c.partition([1, 2, 3, 4], fnc(x) { x > 2 }), c.chunk([1, 2, 3, 4, 5], 2)]`, "[[[3, 4], [1, 2]], [[1, 2], [3, 4], [5]]]"},
+		{`import "std/collections" as c; c.chunk([1], 0)`, "ERROR: chunk size must be positive, got 0"},
+		{`import "std/iter" as it; let total = 0; it.each([1, 2, 3], fnc(x) { total += x }); [total, it.enumerate(["a", "b"])]`, "[6, [[0, a], [1, b]]]"},
+		{`import "std/iter" as it; [it.times(3, fnc(i) { i * i }), it.iterate(fnc(x) { x * 2 }, 1, 5)]`, "[[0, 1, 4], [1, 2, 4, 8, 16]]"},
+		{`import "std/iter" as it; let small = fnc(x) { x < 3 }; [it.take_while([1, 2, 3, 1], small), it.drop_while([1, 2, 3, 1], small), it.drop_while([1], small)]`, "[[1, 2], [3, 1], []]"},
+		{`import "std/iter" as it; [it.scan([1, 2, 3], fnc(a, x) { a + x }, 0), it.window([1, 2, 3], 2), it.window([1], 2)]`, "[[1, 3, 6], [[1, 2], [2, 3]], []]"},
+		{`import "std/testing" as t; [t.assert(true, "x"), t.assert_eq([1], [1]), t.assert_ne(1, 2)]`, "[true, true, true]"},
+		{`import "std/testing" as t; t.assert_eq(1 + 1, 3)`, "ERROR: expected 3, got 2"},
+		{`import "std/testing" as t; t.assert(false, "must hold")`, "ERROR: assertion failed: must hold"},
+		{`import "std/testing" as t;
+		  t.run({"ok": fnc() { t.assert_eq(2, 2) }, "bad": fnc() { t.assert_eq(2, 3) }, "boom": fnc() { 1 + true }})`,
+			"{passed: 1, failed: 2, failures: [bad: expected 3, got 2, boom: type mismatch: INTEGER + BOOLEAN]}"},
+		{`import "std/missing" as m;`, "ERROR: module not found: std/missing"},
+		{`import "std/math" as m; m.native`, "ERROR: line 1, column 27: module math has no export native"},
+		{`native.pow(2, 2)`, "ERROR: identifier not found: native"},
+	}
+
+	for _, tt := range tests {
+		val := testEval(tt.input)
+		if val == nil {
+			t.Errorf("no result for %q", tt.input)
+			continue
+		}
+		if val.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q.\nexpected=%s\ngot=%s", tt.input, tt.expected, val.Inspect())
+		}
+	}
+}
+
+func TestStdlibModulesAreCached(t *testing.T) {
+	eva := NewEval()
+	env := object.NewEnvironment()
+	for _, input := range []string{`import "std/math" as a;`, `import "std/math.chimp" as b;`} {
+		eva.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+	}
+	a, _ := env.Get("a")
+	b, _ := env.Get("b")
+	if a != b {
+		t.Errorf("std/math was loaded twice")
+	}
+}
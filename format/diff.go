@@ -0,0 +1,114 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change.
+const diffContext = 3
+
+type diffLine struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// Diff returns a unified diff that turns a into b, or "" if they are
+// equal. name is used in the file headers.
+func Diff(name, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s.orig\n+++ %s\n", name, name)
+	for start := 0; start < len(lines); {
+		if lines[start].kind == ' ' {
+			start++
+			continue
+		}
+		// Grow the hunk until the gap to the next change exceeds twice the
+		// context.
+		end := start
+		for i := start; i < len(lines); i++ {
+			if lines[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from, to := max(start-diffContext, 0), min(end+diffContext, len(lines))
+		writeHunk(&out, lines, from, to)
+		start = to
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, lines []diffLine, from, to int) {
+	aStart, bStart := 1, 1
+	for _, line := range lines[:from] {
+		if line.kind != '+' {
+			aStart++
+		}
+		if line.kind != '-' {
+			bStart++
+		}
+	}
+	aLen, bLen := 0, 0
+	for _, line := range lines[from:to] {
+		if line.kind != '+' {
+			aLen++
+		}
+		if line.kind != '-' {
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+	for _, line := range lines[from:to] {
+		out.WriteByte(line.kind)
+		out.WriteString(line.text + "\n")
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines aligns a and b along their longest common subsequence.
+func diffLines(a, b []string) []diffLine {
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,492 @@
+// Package format prints programs in the canonical chimp layout: tab
+// indentation, one statement per line, semicolons after statements and
+// long literals and argument lists broken one element per line.
+package format
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+const (
+	// MaxWidth is the line length after which lists are broken up.
+	MaxWidth = 100
+	// tabWidth is used to measure indentation against MaxWidth.
+	tabWidth = 4
+)
+
+// Source formats src, keeping its comments and single blank lines between
+// statements. It fails if src does not parse.
+func Source(src string) (string, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return "", errors.New(strings.Join(p.Errors(), "\n"))
+	}
+
+	pr := &printer{comments: l.Comments()}
+	pr.marks = append(pr.marks, l.Comments()...)
+	all := lexer.New(src)
+	for tok := all.NextToken(); tok.Type != token.EOF; tok = all.NextToken() {
+		pr.marks = append(pr.marks, tok)
+	}
+	sort.Slice(pr.marks, func(i, j int) bool { return before(pr.marks[i], pr.marks[j]) })
+	return pr.program(program), nil
+}
+
+// Program formats a program that has no source, e.g. one built by a code
+// generator. Its nodes need no positions.
+func Program(program *ast.Program) string {
+	return (&printer{}).program(program)
+}
+
+type printer struct {
+	comments []token.Token // not yet printed
+	marks    []token.Token // all tokens and comments of the source, in order
+	depth    int
+
+	// Layout of the current statement: expandBlocks puts the blocks in it on
+	// separate lines and lists nested less than breakLists deep are broken
+	// one element per line.
+	expandBlocks          bool
+	listDepth, breakLists int
+}
+
+func before(a, b token.Token) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// prevLine returns the line of the last token or comment before tok.
+func (p *printer) prevLine(tok token.Token) int {
+	i := sort.Search(len(p.marks), func(i int) bool { return !before(p.marks[i], tok) })
+	if i == 0 {
+		return 0
+	}
+	return p.marks[i-1].Line
+}
+
+// blankBefore reports whether tok was preceded by an empty line.
+func (p *printer) blankBefore(tok token.Token) bool {
+	return len(p.marks) > 0 && tok.Line-p.prevLine(tok) > 1
+}
+
+func (p *printer) program(program *ast.Program) string {
+	lines := p.statements(program.Statements, nil, false)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// statements formats stmts one per line. Comments up to end, or all that
+// are left if end is nil, are printed as well.
+func (p *printer) statements(stmts []ast.Statement, end *token.Token, isBlock bool) []string {
+	lines := []string{}
+	for i, stmt := range stmts {
+		start := ast.Start(stmt)
+		lines = p.flushComments(lines, &start)
+		if len(lines) > 0 && p.blankBefore(start) {
+			lines = append(lines, "")
+		}
+		last := isBlock && i == len(stmts)-1
+		lines = append(lines, strings.Split(p.statement(stmt, last), "\n")...)
+	}
+	return p.flushComments(lines, end)
+}
+
+// flushComments prints the pending comments before end.
+func (p *printer) flushComments(lines []string, end *token.Token) []string {
+	lines, p.comments = p.placeComments(lines, p.comments, end)
+	return lines
+}
+
+// placeComments prints the comments before end, or all of them if end is
+// nil, and returns the rest. A comment that follows code on its line stays
+// at the end of the last printed line.
+func (p *printer) placeComments(lines []string, comments []token.Token, end *token.Token) ([]string, []token.Token) {
+	for len(comments) > 0 && (end == nil || before(comments[0], *end)) {
+		comment := comments[0]
+		comments = comments[1:]
+		switch {
+		case len(lines) > 0 && lines[len(lines)-1] != "" && p.prevLine(comment) == comment.Line:
+			lines[len(lines)-1] += " " + comment.Literal
+		case len(lines) > 0 && p.blankBefore(comment):
+			lines = append(lines, "", comment.Literal)
+		default:
+			lines = append(lines, comment.Literal)
+		}
+	}
+	return lines, comments
+}
+
+// hasComments reports whether a pending comment lies before end.
+func (p *printer) hasComments(end token.Token) bool {
+	return len(p.comments) > 0 && before(p.comments[0], end)
+}
+
+// statement formats stmt. If its lines do not fit into MaxWidth, its
+// blocks are expanded and then its outermost lists broken up until they do
+// or breaking more lists does not help.
+func (p *printer) statement(stmt ast.Statement, last bool) string {
+	expandBlocks, listDepth, breakLists := p.expandBlocks, p.listDepth, p.breakLists
+	defer func() { p.expandBlocks, p.listDepth, p.breakLists = expandBlocks, listDepth, breakLists }()
+
+	p.expandBlocks, p.listDepth, p.breakLists = false, 0, 0
+	comments := p.comments
+	out := p.simpleStatement(stmt, last)
+	for !p.fits(out) {
+		p.comments = comments
+		prev := out
+		if p.expandBlocks {
+			p.breakLists++
+		}
+		p.expandBlocks = true
+		out = p.simpleStatement(stmt, last)
+		if out == prev && p.breakLists > 0 {
+			break
+		}
+	}
+	return out
+}
+
+func (p *printer) fits(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		width := len(strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth)))
+		if width+p.depth*tabWidth > MaxWidth {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *printer) simpleStatement(stmt ast.Statement, last bool) string {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		keyword := "let "
+		if stmt.IsConst() {
+			keyword = "const "
+		}
+		var target string
+		if stmt.Pattern != nil {
+			target = p.pattern(stmt.Pattern)
+		} else {
+			target = stmt.Name.Value
+		}
+		if stmt.Type != nil {
+			target += ": " + stmt.Type.String()
+		}
+		return keyword + target + " = " + p.expr(stmt.Value) + ";"
+	case *ast.ReturnStatement:
+		return "return " + p.expr(stmt.ReturnValue) + ";"
+	case *ast.ReassignmentStatement:
+		if stmt.Value == nil {
+			return p.expr(stmt.Left) + stmt.Operator + ";"
+		}
+		return p.expr(stmt.Left) + " " + stmt.Operator + " " + p.expr(stmt.Value) + ";"
+	case *ast.WhileStatement:
+		return "while (" + p.expr(stmt.Condition) + ") " + p.block(stmt.Body)
+	case *ast.ExpressionStatement:
+		expr := p.expr(stmt.Expression)
+		if _, isIf := stmt.Expression.(*ast.IfExpression); isIf || last {
+			return expr
+		}
+		return expr + ";"
+	default:
+		return stmt.String()
+	}
+}
+
+// block formats braces around statements. Blocks that were written on a
+// single line and hold one simple statement stay on one line.
+func (p *printer) block(block *ast.BlockStatement) string {
+	if len(block.Statements) == 0 && !p.hasComments(block.Close) {
+		return "{}"
+	}
+	if len(block.Statements) == 1 && !p.expandBlocks && block.Token.Line == block.Close.Line && !p.hasComments(block.Close) {
+		if _, isWhile := block.Statements[0].(*ast.WhileStatement); !isWhile {
+			inline := p.statement(block.Statements[0], true)
+			if !strings.Contains(inline, "\n") && len(inline)+p.depth*tabWidth < MaxWidth {
+				return "{ " + inline + " }"
+			}
+		}
+	}
+
+	var end *token.Token
+	if block.Close.Line > 0 {
+		end = &block.Close
+	}
+	p.depth++
+	lines := p.statements(block.Statements, end, true)
+	p.depth--
+	return "{\n" + indent(lines) + "\n}"
+}
+
+func indent(lines []string) string {
+	var out strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		if line != "" {
+			out.WriteString("\t" + line)
+		}
+	}
+	return out.String()
+}
+
+// Binding strength of expressions, matching the parser.
+const (
+	precLowest = iota
+	precEquals
+	precLessGreater
+	precSum
+	precProduct
+	precPrefix
+	precPostfix
+	precAtom
+)
+
+var infixPrecedence = map[string]int{
+	"==": precEquals, "!=": precEquals,
+	"<": precLessGreater, ">": precLessGreater,
+	"+": precSum, "-": precSum, "|": precSum, "^": precSum,
+	"*": precProduct, "/": precProduct, "%": precProduct, "&": precProduct, "<<": precProduct, ">>": precProduct,
+}
+
+func precedence(expr ast.Expression) int {
+	switch expr := expr.(type) {
+	case *ast.InfixExpression:
+		return infixPrecedence[expr.Operator]
+	case *ast.PrefixExpression:
+		return precPrefix
+	case *ast.CallExpression, *ast.IndexExpression, *ast.SliceExpression, *ast.MemberExpression:
+		return precPostfix
+	default:
+		return precAtom
+	}
+}
+
+// operand formats expr in parentheses if it binds less tightly than min.
+func (p *printer) operand(expr ast.Expression, min int) string {
+	if precedence(expr) < min {
+		return "(" + p.expr(expr) + ")"
+	}
+	return p.expr(expr)
+}
+
+func (p *printer) expr(expr ast.Expression) string {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return expr.Value
+	case *ast.IntegerLiteral:
+		return expr.Token.Literal
+	case *ast.Boolean:
+		if expr.Value {
+			return "true"
+		}
+		return "false"
+	case *ast.StringLiteral:
+		return `"` + expr.Value + `"`
+	case *ast.InterpolatedString:
+		var out strings.Builder
+		out.WriteString(`"`)
+		for _, part := range expr.Parts {
+			if ast.IsText(part) {
+				out.WriteString(strings.ReplaceAll(part.(*ast.StringLiteral).Value, "${", `\${`))
+			} else {
+				out.WriteString("${" + p.expr(part) + "}")
+			}
+		}
+		out.WriteString(`"`)
+		return out.String()
+	case *ast.PrefixExpression:
+		right := p.operand(expr.Right, precPrefix)
+		if inner, ok := expr.Right.(*ast.PrefixExpression); ok && expr.Operator == "-" && inner.Operator == "-" {
+			right = "(" + right + ")"
+		}
+		return expr.Operator + right
+	case *ast.InfixExpression:
+		prec := infixPrecedence[expr.Operator]
+		return p.operand(expr.Left, prec) + " " + expr.Operator + " " + p.operand(expr.Right, prec+1)
+	case *ast.IfExpression:
+		out := "if (" + p.expr(expr.Condition) + ") " + p.block(expr.Then)
+		if expr.Alt != nil {
+			out += " else " + p.block(expr.Alt)
+		}
+		return out
+	case *ast.FuncLiteral:
+		params := []string{}
+		for i, param := range expr.Parameters {
+			if typ := expr.ParamType(i); typ != nil {
+				params = append(params, p.pattern(param)+": "+typ.String())
+			} else {
+				params = append(params, p.pattern(param))
+			}
+		}
+		out := "fnc(" + strings.Join(params, ", ") + ")"
+		if expr.ReturnType != nil {
+			out += ": " + expr.ReturnType.String()
+		}
+		return out + " " + p.block(expr.Body)
+	case *ast.CallExpression:
+		return p.operand(expr.Function, precPostfix) + p.list("(", ")", expr.Token, starts(expr.Arguments), func() []string { return p.exprs(expr.Arguments) })
+	case *ast.ArrayLiteral:
+		return p.list("[", "]", expr.Token, starts(expr.Elements), func() []string { return p.exprs(expr.Elements) })
+	case *ast.HashLiteral:
+		keys := []ast.Expression{}
+		for _, pair := range expr.Pairs {
+			keys = append(keys, pair.Key)
+		}
+		return p.list("{", "}", expr.Token, starts(keys), func() []string {
+			pairs := []string{}
+			for _, pair := range expr.Pairs {
+				pairs = append(pairs, p.expr(pair.Key)+": "+p.expr(pair.Value))
+			}
+			return pairs
+		})
+	case *ast.IndexExpression:
+		return p.operand(expr.Left, precPostfix) + "[" + p.expr(expr.Index) + "]"
+	case *ast.SliceExpression:
+		out := p.operand(expr.Left, precPostfix) + "["
+		if expr.Start != nil {
+			out += p.expr(expr.Start)
+		}
+		out += ":"
+		if expr.End != nil {
+			out += p.expr(expr.End)
+		}
+		return out + "]"
+	case *ast.MemberExpression:
+		return p.operand(expr.Object, precPostfix) + "." + expr.Property.Value
+	case nil:
+		return ""
+	default:
+		return expr.String()
+	}
+}
+
+func (p *printer) exprs(exprs []ast.Expression) []string {
+	out := []string{}
+	for _, expr := range exprs {
+		out = append(out, p.expr(expr))
+	}
+	return out
+}
+
+func starts(exprs []ast.Expression) []token.Token {
+	out := []token.Token{}
+	for _, expr := range exprs {
+		out = append(out, ast.Start(expr))
+	}
+	return out
+}
+
+// list joins the formatted items on one line, or puts each on its own line
+// with a trailing comma if the enclosing statement is too long or comments
+// lie between the items. tok is the token opening the list in the source
+// and starts are where its items start.
+func (p *printer) list(open, close string, tok token.Token, starts []token.Token, format func() []string) string {
+	comments := p.listComments(tok)
+	broken := p.listDepth < p.breakLists || len(comments) > 0
+	p.listDepth++
+	items := format()
+	p.listDepth--
+
+	if !broken || len(items) == 0 && len(comments) == 0 {
+		return open + strings.Join(items, ", ") + close
+	}
+	// The open bracket is the first line, so that a comment after it stays
+	// there.
+	lines := []string{open}
+	for i, item := range items {
+		if i < len(starts) {
+			lines, comments = p.placeComments(lines, comments, &starts[i])
+		}
+		lines = append(lines, strings.Split(item+",", "\n")...)
+	}
+	lines, _ = p.placeComments(lines, comments, nil)
+	return lines[0] + "\n" + indent(lines[1:]) + "\n" + close
+}
+
+// listComments takes the pending comments that lie directly in the list
+// opened by open, leaving those in nested lists and blocks to them.
+func (p *printer) listComments(open token.Token) []token.Token {
+	if len(p.comments) == 0 {
+		return nil
+	}
+	inside := map[token.Token]bool{}
+	depth := 0
+	i := sort.Search(len(p.marks), func(i int) bool { return !before(p.marks[i], open) })
+	for ; i < len(p.marks); i++ {
+		switch mark := p.marks[i]; mark.Type {
+		case token.PARENL, token.BRACKETL, token.BRACEL:
+			depth++
+		case token.PARENR, token.BRACKETR, token.BRACER:
+			depth--
+		case token.COMMENT:
+			if depth == 1 {
+				inside[mark] = true
+			}
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if len(inside) == 0 {
+		return nil
+	}
+
+	// statement restores p.comments when it formats again, so they are
+	// copied rather than filtered in place.
+	taken, rest := []token.Token{}, []token.Token{}
+	for _, comment := range p.comments {
+		if inside[comment] {
+			taken = append(taken, comment)
+		} else {
+			rest = append(rest, comment)
+		}
+	}
+	p.comments = rest
+	return taken
+}
+
+func (p *printer) pattern(pattern ast.Pattern) string {
+	switch pattern := pattern.(type) {
+	case *ast.ArrayPattern:
+		elements := []string{}
+		for _, el := range pattern.Elements {
+			elements = append(elements, p.pattern(el))
+		}
+		if pattern.Rest != nil {
+			elements = append(elements, "..."+pattern.Rest.Value)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *ast.HashPattern:
+		pairs := []string{}
+		for _, pair := range pattern.Pairs {
+			key, isIdent := pair.Key.(*ast.Identifier)
+			value, valueIsIdent := pair.Value.(*ast.Identifier)
+			switch {
+			case isIdent && valueIsIdent && key.Value == value.Value:
+				pairs = append(pairs, key.Value)
+			case isIdent:
+				pairs = append(pairs, key.Value+": "+p.pattern(pair.Value))
+			default:
+				pairs = append(pairs, `"`+pair.KeyName()+`": `+p.pattern(pair.Value))
+			}
+		}
+		if pattern.Rest != nil {
+			pairs = append(pairs, "..."+pattern.Rest.Value)
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	default:
+		return pattern.String()
+	}
+}
@@ -0,0 +1,254 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/stdlib"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let   x=5", "let x = 5;\n"},
+		{"let x = 5; let y = x*2; y", "let x = 5;\nlet y = x * 2;\ny;\n"},
+		{"const [a, ...rest]=[1,2,3];", "const [a, ...rest] = [1, 2, 3];\n"},
+		{`let {name, "full name": full, age: a} = h;`, `let {name, "full name": full, age: a} = h;` + "\n"},
+		{"x+=1;x++", "x += 1;\nx++;\n"},
+		{"(1 + 2) * 3; 1 + (2 * 3); a - (b - c); (a - b) - c", "(1 + 2) * 3;\n1 + 2 * 3;\na - (b - c);\na - b - c;\n"},
+		{"-(-x); !(a == b); -a[0]", "-(-x);\n!(a == b);\n-a[0];\n"},
+		{"(fnc(x){x})(1); (a + b)[1:]; m.f(1,2,)", "fnc(x) { x }(1);\n(a + b)[1:];\nm.f(1, 2);\n"},
+		{`"sum: ${a+b} \${raw}"`, `"sum: ${a + b} \${raw}";` + "\n"},
+		{`"a${"b"}c"`, `"a${"b"}c";` + "\n"},
+		{`import "std/math" as m; import {max as largest, min} from "std/math"; export {m, largest};`,
+			"import \"std/math\" as m;\nimport {max as largest, min} from \"std/math\";\nexport {m, largest};\n"},
+		{"if (x > 1) { 1 } else { 2 }", "if (x > 1) { 1 } else { 2 }\n"},
+		{"if (x > 1) {\nputs(x); x }", "if (x > 1) {\n\tputs(x);\n\tx\n}\n"},
+		{"while (x > 0) { x-- }", "while (x > 0) { x--; }\n"},
+		{"while (x > 0) { while (y) { y-- } }", "while (x > 0) {\n\twhile (y) { y--; }\n}\n"},
+		{"let f = fnc() {}; return f;", "let f = fnc() {};\nreturn f;\n"},
+		{"let n:int=1; let f = fnc(a:[int], b, {x}:{x: int}):{[string]:bool} {}; let g: fnc(int,string):null = f",
+			"let n: int = 1;\nlet f = fnc(a: [int], b, {x}: {x: int}): {[string]: bool} {};\nlet g: fnc(int, string): null = f;\n"},
+		{"let f = fnc(a,b) {\n\n  let c = a + b;\n\n\n  c\n}",
+			"let f = fnc(a, b) {\n\tlet c = a + b;\n\n\tc\n};\n"},
+		{"", ""},
+	}
+
+	for i, tt := range tests {
+		got, err := Source(tt.input)
+		if err != nil {
+			t.Errorf("tests[%d] - unexpected error: %s", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("tests[%d] - wrong output.\nexpected=%q\ngot=     %q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	input := `// Package header.
+
+let x = 5 // five
+// about f
+let f = fnc(n) {
+  // inside
+  n * 2 // double
+
+  // before close
+};
+// end
+`
+	expected := `// Package header.
+
+let x = 5; // five
+// about f
+let f = fnc(n) {
+	// inside
+	n * 2 // double
+
+	// before close
+};
+// end
+`
+	got, err := Source(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, got)
+	}
+}
+
+func TestSourceBreaksLongLists(t *testing.T) {
+	input := `let h = {"first": 1000000000, "second": 2000000000, "third": 3000000000, "fourth": 4000000000, "fifth": 5};
+puts(h, [1, 2, 3]);
+puts("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", [1, 2], "cccccccccccccccccccccc");`
+	expected := `let h = {
+	"first": 1000000000,
+	"second": 2000000000,
+	"third": 3000000000,
+	"fourth": 4000000000,
+	"fifth": 5,
+};
+puts(h, [1, 2, 3]);
+puts(
+	"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+	"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+	[1, 2],
+	"cccccccccccccccccccccc",
+);
+`
+	got, err := Source(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, got)
+	}
+	if again, err := Source(got); err != nil || again != got {
+		t.Errorf("broken lists do not format to themselves: %v\n%s", err, again)
+	}
+}
+
+func TestSourceListComments(t *testing.T) {
+	input := `let h = {
+	// first
+	"a": 1, // one
+	"b": 2,
+	// last
+};
+puts(
+	1, // x
+	// before f
+	fnc() { 2 },
+);
+let y = [1, [
+	2, // two
+], 3];
+let z = [ // numbers
+  1, 2];
+`
+	expected := `let h = {
+	// first
+	"a": 1, // one
+	"b": 2,
+	// last
+};
+puts(
+	1, // x
+	// before f
+	fnc() { 2 },
+);
+let y = [1, [
+	2, // two
+], 3];
+let z = [ // numbers
+	1,
+	2,
+];
+`
+	got, err := Source(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, got)
+	}
+	if again, err := Source(got); err != nil || again != got {
+		t.Errorf("lists with comments do not format to themselves: %v\n%s", err, again)
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	if _, err := Source("let = 5;"); err == nil {
+		t.Fatalf("expected an error for invalid source")
+	}
+}
+
+// Formatting must not change the meaning of a program and formatting its
+// output again must not change it.
+func TestSourceIsStable(t *testing.T) {
+	inputs := []string{
+		"let a = [1, 2, 3]; let b = a[1:]; if (len(b) > 1) { puts(b) } else { puts(a) }",
+		"let fib = fnc(n) { if (n < 2) { return n; } fib(n - 1) + fib(n - 2) }; fib(10)",
+		"let x = 1; while (x < 100) { x *= 2; } // doubled\n-(-x) - -x",
+		`let {a, ...rest} = {"a": 1, "b": 2}; "${a}: ${rest["b"]}"`,
+	}
+	for _, name := range stdlib.Modules() {
+		src, _ := stdlib.Source(name)
+		inputs = append(inputs, src)
+	}
+
+	for i, input := range inputs {
+		once, err := Source(input)
+		if err != nil {
+			t.Fatalf("inputs[%d] - unexpected error: %s", i, err)
+		}
+		twice, err := Source(once)
+		if err != nil {
+			t.Fatalf("inputs[%d] - formatted output does not parse: %s\n%s", i, err, once)
+		}
+		if once != twice {
+			t.Errorf("inputs[%d] - formatting is not idempotent:\n%s", i, Diff("once", once, twice))
+		}
+		if parse(t, input) != parse(t, once) {
+			t.Errorf("inputs[%d] - formatting changed the program:\n%s", i, once)
+		}
+	}
+}
+
+func TestProgram(t *testing.T) {
+	input := "let add = fnc(a, b) { a + b };\nadd(1, 2)"
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	expected := "let add = fnc(a, b) { a + b };\nadd(1, 2);\n"
+	if got := Program(program); got != expected {
+		t.Errorf("wrong output.\nexpected=%q\ngot=     %q", expected, got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n12\n"
+	expected := `--- f.orig
++++ f
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -8,5 +8,4 @@
+ 8
+ 9
+ 10
+-11
+ 12
+`
+	if got := Diff("f", a, b); got != expected {
+		t.Errorf("wrong diff.\nexpected=%q\ngot=     %q", expected, got)
+	}
+	if got := Diff("f", a, a); got != "" {
+		t.Errorf("expected no diff for equal inputs, got=%q", got)
+	}
+}
+
+func parse(t *testing.T, src string) string {
+	t.Helper()
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors: %s", strings.Join(p.Errors(), "; "))
+	}
+	return program.String()
+}
@@ -1,43 +1,100 @@
 package lexer
 
 import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/Muto1907/interpreterInGo/token"
 )
 
+// Lexer decodes its input as UTF-8. Positions are byte offsets into input.
 type Lexer struct {
 	input            string
 	currCharPosition int
 	readPosition     int // 1 char after currCharPosition
-	char             byte
+	char             rune
+	line, column     int // of char, in runes
+	comments         []token.Token
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewAt(input, 1, 1)
+}
+
+// NewAt returns a lexer for input that starts at line and column of a larger
+// source, such as an expression embedded in an interpolated string.
+func NewAt(input string, line, column int) *Lexer {
+	l := &Lexer{input: input, line: line, column: column - 1}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.char == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.char = 0
 	} else {
-		l.char = l.input[l.readPosition]
+		l.char, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.currCharPosition = l.readPosition
-	l.readPosition++
+	l.readPosition += width
 }
 
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	char, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return char
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.nomWhitespace()
+	for l.char == '/' && l.peekChar() == '/' {
+		l.readComment()
+		l.nomWhitespace()
+	}
+	line, column := l.line, l.column
+	tok := l.nextToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
+
+// Tokens returns the remaining tokens of the input, ending with EOF.
+func (l *Lexer) Tokens() []token.Token {
+	tokens := []token.Token{}
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == token.EOF {
+			return tokens
+		}
+	}
+}
+
+// Comments returns the // comments skipped so far, in source order.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+func (l *Lexer) readComment() {
+	tok := token.Token{Type: token.COMMENT, Line: l.line, Column: l.column}
+	start := l.currCharPosition
+	for l.char != '\n' && l.char != 0 {
+		l.readChar()
+	}
+	tok.Literal = strings.TrimRight(l.input[start:l.currCharPosition], " \t\r")
+	l.comments = append(l.comments, tok)
+}
+
+func (l *Lexer) nextToken() token.Token {
+	var tok token.Token
 
 	switch l.char {
 	case '=':
@@ -52,13 +109,15 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		tok = newToken(token.COMMA, l.char)
 	case '+':
-		tok = newToken(token.PLUS, l.char)
+		tok = l.readOperator(token.PLUS, token.PLUS_ASSIGN, token.INCREMENT)
 	case '-':
-		tok = newToken(token.MINUS, l.char)
+		tok = l.readOperator(token.MINUS, token.MINUS_ASSIGN, token.DECREMENT)
 	case '*':
-		tok = newToken(token.MULT, l.char)
+		tok = l.readOperator(token.MULT, token.MULT_ASSIGN, "")
 	case '/':
-		tok = newToken(token.DIV, l.char)
+		tok = l.readOperator(token.DIV, token.DIV_ASSIGN, "")
+	case '%':
+		tok = l.readOperator(token.MOD, token.MOD_ASSIGN, "")
 	case '(':
 		tok = newToken(token.PARENL, l.char)
 	case ')':
@@ -68,28 +127,54 @@ func (l *Lexer) NextToken() token.Token {
 	case '}':
 		tok = newToken(token.BRACER, l.char)
 	case '<':
-		tok = newToken(token.LT, l.char)
+		if l.peekChar() == '<' {
+			l.readChar()
+			tok = token.Token{Type: token.SHL, Literal: "<<"}
+		} else {
+			tok = newToken(token.LT, l.char)
+		}
 	case '>':
-		tok = newToken(token.GT, l.char)
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.SHR, Literal: ">>"}
+		} else {
+			tok = newToken(token.GT, l.char)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			l.readChar()
 			tok = token.Token{Type: token.NOT_EQ, Literal: "!="}
-			l.readChar()
 		} else {
 			tok = newToken(token.NOT, l.char)
 		}
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
+		if strings.Contains(tok.Literal, "${") {
+			tok.Type = token.TEMPLATE
+		}
 	case '[':
 		tok = newToken(token.BRACKETL, l.char)
 	case ']':
 		tok = newToken(token.BRACKETR, l.char)
 	case ':':
 		tok = newToken(token.COLON, l.char)
+	case '.':
+		if strings.HasPrefix(l.input[l.currCharPosition:], "...") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = newToken(token.DOT, l.char)
+		}
 	case '&':
 		tok = newToken(token.AMPERSAND, l.char)
+	case '|':
+		tok = newToken(token.PIPE, l.char)
+	case '^':
+		tok = newToken(token.CARET, l.char)
+	case '~':
+		tok = newToken(token.TILDE, l.char)
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -110,49 +195,170 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+// readIdentifier expects the current char to be a letter; later chars may
+// also be digits.
 func (l *Lexer) readIdentifier() string {
 	position := l.currCharPosition
-	for isLetter((l.char)) {
+	for isLetter(l.char) || unicode.IsDigit(l.char) {
 		l.readChar()
 	}
 	return l.input[position:l.currCharPosition]
 }
 
+// readNumber reads decimal literals as well as 0x, 0o and 0b prefixed ones.
+// Digits may be separated by underscores. Prefixed literals swallow any
+// trailing letters and digits so the parser reports 0b12 as one bad literal.
 func (l *Lexer) readNumber() string {
 	startPosition := l.currCharPosition
-	for isDigit(l.char) {
+	if l.char == '0' && isNumberPrefix(l.peekChar()) {
+		l.readChar()
+		l.readChar()
+		for isDigit(l.char) || isLetter(l.char) {
+			l.readChar()
+		}
+		return l.input[startPosition:l.currCharPosition]
+	}
+	for isDigit(l.char) || l.char == '_' {
 		l.readChar()
 	}
 	return l.input[startPosition:l.currCharPosition]
 }
 
+// readOperator reads an arithmetic operator, its compound assignment form
+// (op=) or, if doubled is set, its doubled form (++, --).
+func (l *Lexer) readOperator(single, assign, doubled token.TokenType) token.Token {
+	switch {
+	case l.peekChar() == '=':
+		ch := l.char
+		l.readChar()
+		return token.Token{Type: assign, Literal: string(ch) + "="}
+	case doubled != "" && l.peekChar() == l.char && endsStatement(l.input[l.readPosition+1:]):
+		l.readChar()
+		return token.Token{Type: doubled, Literal: string(doubled)}
+	default:
+		return newToken(single, l.char)
+	}
+}
+
+// endsStatement reports whether rest, the input after a ++ or --, starts
+// with the end of a statement: a semicolon, a closing brace, a line break,
+// a comment or the end of the input, possibly after blanks. Elsewhere the
+// pair is two operators, so 5--3 stays 5 - -3.
+func endsStatement(rest string) bool {
+	rest = strings.TrimLeft(rest, " \t")
+	return rest == "" || strings.HasPrefix(rest, "//") || strings.ContainsAny(rest[:1], ";}\r\n")
+}
+
 func (l *Lexer) readString() string {
 	startPosition := l.currCharPosition + 1
-	for {
+	end := stringEnd(l.input, l.currCharPosition)
+	for l.currCharPosition < end && l.char != 0 {
 		l.readChar()
-		if l.char == '"' || l.char == 0 {
-			break
-		}
 	}
 	return l.input[startPosition:l.currCharPosition]
+}
 
+// stringEnd returns the position of the quote closing the string opened at
+// quote, skipping over quotes inside ${...} interpolations. Unterminated
+// strings end with the input.
+func stringEnd(input string, quote int) int {
+	for i := quote + 1; i < len(input); i++ {
+		switch {
+		case input[i] == '"':
+			return i
+		case strings.HasPrefix(input[i:], `\${`):
+			i++
+		case strings.HasPrefix(input[i:], "${"):
+			i = interpolationEnd(input, i+1)
+		}
+	}
+	return len(input)
+}
+
+// interpolationEnd returns the position of the brace closing the one at open,
+// skipping over nested braces and strings.
+func interpolationEnd(input string, open int) int {
+	depth := 0
+	for i := open; i < len(input); i++ {
+		switch input[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"':
+			i = stringEnd(input, i)
+		}
+	}
+	return len(input)
+}
+
+// TemplatePart is a piece of an interpolated string: either literal text or
+// the source of an embedded ${...} expression.
+type TemplatePart struct {
+	Text   string
+	IsExpr bool
+	// Offset is the byte offset of the part in the literal. For expressions
+	// it is the offset of their source, after the ${.
+	Offset int
+}
+
+// SplitTemplate splits the literal of a TEMPLATE token into its parts. A
+// backslash before ${ keeps it as literal text.
+func SplitTemplate(literal string) []TemplatePart {
+	parts := []TemplatePart{}
+	var text strings.Builder
+	textStart := 0
+	for i := 0; i < len(literal); i++ {
+		switch {
+		case strings.HasPrefix(literal[i:], `\${`):
+			text.WriteString("${")
+			i += 2
+		case strings.HasPrefix(literal[i:], "${"):
+			if text.Len() > 0 {
+				parts = append(parts, TemplatePart{Text: text.String(), Offset: textStart})
+				text.Reset()
+			}
+			end := interpolationEnd(literal, i+1)
+			parts = append(parts, TemplatePart{Text: literal[i+2 : min(end, len(literal))], IsExpr: true, Offset: i + 2})
+			i = end
+			textStart = end + 1
+		default:
+			text.WriteByte(literal[i])
+		}
+	}
+	if text.Len() > 0 {
+		parts = append(parts, TemplatePart{Text: text.String(), Offset: textStart})
+	}
+	return parts
 }
 
-func isLetter(char byte) bool {
-	return ('A' <= char && char <= 'Z') || ('a' <= char && char <= 'z') || char == '_'
+func isLetter(char rune) bool {
+	return unicode.IsLetter(char) || char == '_'
 }
 
-func isDigit(char byte) bool {
+func isDigit(char rune) bool {
 	return ('0' <= char && char <= '9')
 }
 
+func isNumberPrefix(char rune) bool {
+	switch char {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return true
+	default:
+		return false
+	}
+}
+
 func (l *Lexer) nomWhitespace() {
 	for l.char == ' ' || l.char == '\n' || l.char == '\t' || l.char == '\r' {
 		l.readChar()
 	}
 }
 
-func newToken(tokenType token.TokenType, literal byte) token.Token {
+func newToken(tokenType token.TokenType, literal rune) token.Token {
 	return token.Token{
 		Type:    tokenType,
 		Literal: string(literal),
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/Muto1907/interpreterInGo/token"
@@ -165,3 +166,299 @@ let ptr = &3;
 		}
 	}
 }
+
+func TestNumberLiteralsAndBitwiseOperators(t *testing.T) {
+	input := `0xFF 0o17 0b1010 1_000_000 0 07;
+a & b | c ^ ~d << 2 >> 1;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0o17"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0"},
+		{token.INT, "07"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.PIPE, "|"},
+		{token.IDENT, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "d"},
+		{token.SHL, "<<"},
+		{token.INT, "2"},
+		{token.SHR, ">>"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNotEqualWithoutSpaces(t *testing.T) {
+	input := `a!=b;1!=2!x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.NOT_EQ, "!="},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
+		{token.INT, "1"},
+		{token.NOT_EQ, "!="},
+		{token.INT, "2"},
+		{token.NOT, "!"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong type. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let item2 = x1y2 + größe; _tmp9 日本語 2abc €`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "item2"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "x1y2"},
+		{token.PLUS, "+"},
+		{token.IDENT, "größe"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "_tmp9"},
+		{token.IDENT, "日本語"},
+		{token.INT, "2"},
+		{token.IDENT, "abc"},
+		{token.ILLEGAL, "€"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTemplateStrings(t *testing.T) {
+	input := `"plain" "n=${n}" "${f("}")} done" "\${x}"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING, "plain"},
+		{token.TEMPLATE, "n=${n}"},
+		{token.TEMPLATE, `${f("}")} done`},
+		{token.TEMPLATE, `\${x}`},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong type. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestSplitTemplate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TemplatePart
+	}{
+		{"total: ${a + b}!", []TemplatePart{{"total: ", false, 0}, {"a + b", true, 9}, {"!", false, 15}}},
+		{"${x}${y}", []TemplatePart{{"x", true, 2}, {"y", true, 6}}},
+		{`${ {"a": 1}["a"] }`, []TemplatePart{{` {"a": 1}["a"] `, true, 2}}},
+		{`${"}"}`, []TemplatePart{{`"}"`, true, 2}}},
+		{`cost \${x}`, []TemplatePart{{"cost ${x}", false, 0}}},
+	}
+
+	for _, tt := range tests {
+		parts := SplitTemplate(tt.input)
+		if len(parts) != len(tt.expected) {
+			t.Fatalf("wrong number of parts for %q. expected=%d, got=%d (%v)", tt.input, len(tt.expected), len(parts), parts)
+		}
+		for i, part := range parts {
+			if part != tt.expected[i] {
+				t.Errorf("part %d of %q wrong. expected=%+v, got=%+v", i, tt.input, tt.expected[i], part)
+			}
+		}
+	}
+}
+
+func TestCompoundAssignmentOperators(t *testing.T) {
+	input := `x += 1; x -= 2; x *= 3; x /= 4; x %= 5; x % 6; i++; i--; --7; - -8; 5--3; x--
+y`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"}, {token.PLUS_ASSIGN, "+="}, {token.INT, "1"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "x"}, {token.MINUS_ASSIGN, "-="}, {token.INT, "2"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "x"}, {token.MULT_ASSIGN, "*="}, {token.INT, "3"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "x"}, {token.DIV_ASSIGN, "/="}, {token.INT, "4"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "x"}, {token.MOD_ASSIGN, "%="}, {token.INT, "5"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "x"}, {token.MOD, "%"}, {token.INT, "6"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "i"}, {token.INCREMENT, "++"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "i"}, {token.DECREMENT, "--"}, {token.SEMICOLON, ";"},
+		{token.MINUS, "-"}, {token.MINUS, "-"}, {token.INT, "7"}, {token.SEMICOLON, ";"},
+		{token.MINUS, "-"}, {token.MINUS, "-"}, {token.INT, "8"}, {token.SEMICOLON, ";"},
+		{token.INT, "5"}, {token.MINUS, "-"}, {token.MINUS, "-"}, {token.INT, "3"}, {token.SEMICOLON, ";"},
+		{token.IDENT, "x"}, {token.DECREMENT, "--"}, {token.IDENT, "y"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong type. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong literal. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "const x = 5;\n  x = \"größe\" + y;\n\tz"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+	}{
+		{token.CONST, 1, 1},
+		{token.IDENT, 1, 7},
+		{token.ASSIGN, 1, 9},
+		{token.INT, 1, 11},
+		{token.SEMICOLON, 1, 12},
+		{token.IDENT, 2, 3},
+		{token.ASSIGN, 2, 5},
+		{token.STRING, 2, 7},
+		{token.PLUS, 2, 15},
+		{token.IDENT, 2, 17},
+		{token.SEMICOLON, 2, 18},
+		{token.IDENT, 3, 2},
+		{token.EOF, 3, 3},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - wrong type. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - wrong position for %q. expected=%d:%d, got=%d:%d",
+				i, tok.Literal, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "// header\nlet x = 5; // five  \n  //\nx / 2"
+
+	expectedTokens := []token.TokenType{
+		token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON,
+		token.IDENT, token.DIV, token.INT, token.EOF,
+	}
+	l := New(input)
+	for i, expected := range expectedTokens {
+		tok := l.NextToken()
+		if tok.Type != expected {
+			t.Fatalf("tests[%d] - wrong type. expected=%q, got=%q", i, expected, tok.Type)
+		}
+	}
+
+	expectedComments := []token.Token{
+		{Type: token.COMMENT, Literal: "// header", Line: 1, Column: 1},
+		{Type: token.COMMENT, Literal: "// five", Line: 2, Column: 12},
+		{Type: token.COMMENT, Literal: "//", Line: 3, Column: 3},
+	}
+	comments := l.Comments()
+	if len(comments) != len(expectedComments) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d", len(expectedComments), len(comments))
+	}
+	for i, expected := range expectedComments {
+		if comments[i] != expected {
+			t.Errorf("comments[%d] wrong. expected=%+v, got=%+v", i, expected, comments[i])
+		}
+	}
+}
+
+func TestTokensJSON(t *testing.T) {
+	tokens := New("let x = 1;").Tokens()
+	data, err := json.Marshal(tokens[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `[{"type":"LET","literal":"let","line":1,"column":1},{"type":"IDENT","literal":"x","line":1,"column":5}]`
+	if string(data) != expected {
+		t.Errorf("wrong JSON.\nexpected=%s\ngot=     %s", expected, data)
+	}
+	if len(tokens) != 6 || tokens[5].Type != token.EOF {
+		t.Errorf("expected 6 tokens ending with EOF. got=%v", tokens)
+	}
+}
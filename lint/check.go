@@ -0,0 +1,297 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+type symbolKind int
+
+const (
+	variable symbolKind = iota
+	constant
+	parameter
+	imported
+)
+
+// symbol is a declared name.
+type symbol struct {
+	decl *ast.Identifier
+	kind symbolKind
+	used bool
+}
+
+// scope mirrors an environment of the evaluator: the program, a block or a
+// function call, whose parameters and body share one scope.
+type scope struct {
+	outer   *scope
+	symbols map[string]*symbol
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, symbols: map[string]*symbol{}}
+}
+
+func (s *scope) lookup(name string) *symbol {
+	for ; s != nil; s = s.outer {
+		if sym, ok := s.symbols[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// function is a function literal whose body is checked after the program.
+type function struct {
+	literal *ast.FuncLiteral
+	scope   *scope
+}
+
+// checker resolves names in source order. Function bodies run later than
+// they are defined, typically after the enclosing scope declared all its
+// names, so they are checked once the program is done; this lets functions
+// refer to themselves and to each other.
+type checker struct {
+	cfg       Config
+	builtIns  map[string]bool
+	globals   map[string]bool
+	symbols   []*symbol
+	functions []function
+	diags     []Diagnostic
+}
+
+func newChecker(cfg Config) *checker {
+	c := &checker{cfg: cfg, builtIns: map[string]bool{}, globals: map[string]bool{}}
+	for _, name := range evaluator.BuiltInNames() {
+		c.builtIns[name] = true
+	}
+	for _, name := range cfg.Globals {
+		c.globals[name] = true
+	}
+	return c
+}
+
+func (c *checker) report(rule string, tok token.Token, format string, a ...any) {
+	severity := c.cfg.severity(rule)
+	if severity == Off {
+		return
+	}
+	c.diags = append(c.diags, Diagnostic{
+		Rule:     rule,
+		Severity: severity,
+		Line:     tok.Line,
+		Column:   tok.Column,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *checker) program(program *ast.Program) {
+	c.statements(program.Statements, newScope(nil))
+	for len(c.functions) > 0 {
+		fn := c.functions[0]
+		c.functions = c.functions[1:]
+		c.function(fn)
+	}
+
+	for _, sym := range c.symbols {
+		if sym.used || strings.HasPrefix(sym.decl.Value, "_") {
+			continue
+		}
+		if sym.kind == parameter {
+			c.report(UnusedParameter, sym.decl.Token, "parameter %s is never used", sym.decl.Value)
+		} else {
+			c.report(UnusedVariable, sym.decl.Token, "%s is declared but never used", sym.decl.Value)
+		}
+	}
+}
+
+func (c *checker) function(fn function) {
+	s := newScope(fn.scope)
+	for _, param := range fn.literal.Parameters {
+		c.declare(param, s, parameter)
+	}
+	if fn.literal.Body != nil {
+		c.statements(fn.literal.Body.Statements, s)
+	}
+}
+
+func (c *checker) statements(stmts []ast.Statement, s *scope) {
+	reported := false
+	for i, stmt := range stmts {
+		if i > 0 && terminates(stmts[i-1]) && !reported {
+			c.report(UnreachableCode, ast.Start(stmt), "unreachable code")
+			reported = true
+		}
+		c.statement(stmt, s)
+	}
+}
+
+// terminates reports whether stmt always returns: it is a return statement
+// or an if whose branches both end in one.
+func terminates(stmt ast.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStatement:
+		return true
+	case *ast.ExpressionStatement:
+		ifExpr, ok := stmt.Expression.(*ast.IfExpression)
+		return ok && blockTerminates(ifExpr.Then) && blockTerminates(ifExpr.Alt)
+	}
+	return false
+}
+
+func blockTerminates(block *ast.BlockStatement) bool {
+	return block != nil && len(block.Statements) > 0 && terminates(block.Statements[len(block.Statements)-1])
+}
+
+func (c *checker) statement(stmt ast.Statement, s *scope) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		c.expression(stmt.Value, s)
+		kind := variable
+		if stmt.IsConst() {
+			kind = constant
+		}
+		if stmt.Pattern != nil {
+			c.declare(stmt.Pattern, s, kind)
+		} else if stmt.Name != nil {
+			c.declare(stmt.Name, s, kind)
+		}
+	case *ast.ReturnStatement:
+		c.expression(stmt.ReturnValue, s)
+	case *ast.ExpressionStatement:
+		c.expression(stmt.Expression, s)
+	case *ast.ReassignmentStatement:
+		c.assignment(stmt, s)
+		c.expression(stmt.Value, s)
+	case *ast.WhileStatement:
+		c.expression(stmt.Condition, s)
+		c.block(stmt.Body, s)
+	case *ast.BlockStatement:
+		c.block(stmt, s)
+	case *ast.ImportStatement:
+		if stmt.Alias != nil {
+			c.declare(stmt.Alias, s, imported)
+		}
+		for _, name := range stmt.Names {
+			c.declare(name.Binding(), s, imported)
+		}
+	case *ast.ExportStatement:
+		for _, name := range stmt.Names {
+			c.use(name, s)
+		}
+	}
+}
+
+func (c *checker) block(block *ast.BlockStatement, s *scope) {
+	if block != nil {
+		c.statements(block.Statements, newScope(s))
+	}
+}
+
+func (c *checker) assignment(stmt *ast.ReassignmentStatement, s *scope) {
+	ident, ok := stmt.Left.(*ast.Identifier)
+	if !ok {
+		c.expression(stmt.Left, s)
+		return
+	}
+	sym := s.lookup(ident.Value)
+	switch {
+	case sym == nil && c.globals[ident.Value]:
+	case sym == nil:
+		c.report(UndeclaredAssignment, ident.Token, "assignment to undeclared name %s", ident.Value)
+	case sym.kind == constant || sym.kind == imported:
+		c.report(ConstantAssignment, ident.Token, "cannot assign to constant %s", ident.Value)
+	}
+	// Compound assignments and increments read the variable.
+	if sym != nil && stmt.Operator != "=" {
+		sym.used = true
+	}
+}
+
+// declare defines the names bound by pattern in s.
+func (c *checker) declare(pattern ast.Pattern, s *scope, kind symbolKind) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if prev, ok := s.symbols[pattern.Value]; ok {
+			c.report(Redeclared, pattern.Token, "%s redeclared in this scope (previous declaration at %d:%d)",
+				pattern.Value, prev.decl.Token.Line, prev.decl.Token.Column)
+			return
+		}
+		sym := &symbol{decl: pattern, kind: kind}
+		s.symbols[pattern.Value] = sym
+		c.symbols = append(c.symbols, sym)
+	case *ast.ArrayPattern:
+		for _, element := range pattern.Elements {
+			c.declare(element, s, kind)
+		}
+		if pattern.Rest != nil {
+			c.declare(pattern.Rest, s, kind)
+		}
+	case *ast.HashPattern:
+		for _, pair := range pattern.Pairs {
+			c.declare(pair.Value, s, kind)
+		}
+		if pattern.Rest != nil {
+			c.declare(pattern.Rest, s, kind)
+		}
+	}
+}
+
+func (c *checker) use(ident *ast.Identifier, s *scope) {
+	if sym := s.lookup(ident.Value); sym != nil {
+		sym.used = true
+		return
+	}
+	if !c.builtIns[ident.Value] && !c.globals[ident.Value] {
+		c.report(UndefinedName, ident.Token, "undefined: %s", ident.Value)
+	}
+}
+
+func (c *checker) expression(expr ast.Expression, s *scope) {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		c.use(expr, s)
+	case *ast.InterpolatedString:
+		c.expressions(expr.Parts, s)
+	case *ast.PrefixExpression:
+		c.expression(expr.Right, s)
+	case *ast.InfixExpression:
+		c.expression(expr.Left, s)
+		c.expression(expr.Right, s)
+	case *ast.IfExpression:
+		c.expression(expr.Condition, s)
+		c.block(expr.Then, s)
+		c.block(expr.Alt, s)
+	case *ast.FuncLiteral:
+		c.functions = append(c.functions, function{literal: expr, scope: s})
+	case *ast.CallExpression:
+		c.expression(expr.Function, s)
+		c.expressions(expr.Arguments, s)
+	case *ast.ArrayLiteral:
+		c.expressions(expr.Elements, s)
+	case *ast.HashLiteral:
+		for _, pair := range expr.Pairs {
+			c.expression(pair.Key, s)
+			c.expression(pair.Value, s)
+		}
+	case *ast.IndexExpression:
+		c.expression(expr.Left, s)
+		c.expression(expr.Index, s)
+	case *ast.SliceExpression:
+		c.expression(expr.Left, s)
+		c.expression(expr.Start, s)
+		c.expression(expr.End, s)
+	case *ast.MemberExpression:
+		c.expression(expr.Object, s)
+	}
+}
+
+func (c *checker) expressions(exprs []ast.Expression, s *scope) {
+	for _, expr := range exprs {
+		c.expression(expr, s)
+	}
+}
@@ -0,0 +1,187 @@
+// Package lint reports likely mistakes in chimp programs without running
+// them: undefined and undeclared names, redeclarations, assignments to
+// constants, unreachable code and unused variables and parameters.
+//
+// A comment of the form
+//
+//	// lint:ignore unused-variable,unreachable-code reason
+//
+// silences the named rules on its own line, or on the next line when it
+// stands on a line by itself.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+// Severity is how serious a diagnostic is. Rules with severity Off are not
+// checked.
+type Severity int
+
+const (
+	Off Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return "off"
+	}
+}
+
+// ParseSeverity parses "off", "warning" or "error".
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range []Severity{Off, Warning, Error} {
+		if s == severity.String() {
+			return severity, nil
+		}
+	}
+	return Off, fmt.Errorf("unknown severity %q", s)
+}
+
+// Rule IDs.
+const (
+	UndefinedName        = "undefined-name"
+	UndeclaredAssignment = "undeclared-assignment"
+	Redeclared           = "redeclared"
+	ConstantAssignment   = "constant-assignment"
+	UnreachableCode      = "unreachable-code"
+	UnusedVariable       = "unused-variable"
+	UnusedParameter      = "unused-parameter"
+)
+
+const suppressionDirective = "lint:ignore"
+
+// Rule describes a check and its default severity.
+type Rule struct {
+	ID       string
+	Severity Severity
+	Doc      string
+}
+
+// Rules lists all checks.
+var Rules = []Rule{
+	{UndefinedName, Error, "a name is used that is not declared in any enclosing scope"},
+	{UndeclaredAssignment, Error, "a name is assigned to that is not declared in any enclosing scope"},
+	{Redeclared, Error, "a name is declared twice in the same scope"},
+	{ConstantAssignment, Error, "a constant or an imported name is assigned to"},
+	{UnreachableCode, Warning, "a statement follows a return in the same block"},
+	{UnusedVariable, Warning, "a variable, constant or import is never read; names starting with _ are exempt"},
+	{UnusedParameter, Warning, "a parameter is never read; names starting with _ are exempt"},
+}
+
+// Config adjusts the checks.
+type Config struct {
+	// Severities overrides the default severity of rules by ID.
+	Severities map[string]Severity
+	// Globals are names defined outside of the program, in addition to
+	// the builtin functions.
+	Globals []string
+}
+
+func (cfg Config) severity(rule string) Severity {
+	if severity, ok := cfg.Severities[rule]; ok {
+		return severity
+	}
+	for _, r := range Rules {
+		if r.ID == rule {
+			return r.Severity
+		}
+	}
+	return Off
+}
+
+// Diagnostic is a problem found in a program.
+type Diagnostic struct {
+	Rule     string
+	Severity Severity
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", d.Line, d.Column, d.Severity, d.Message, d.Rule)
+}
+
+// Source parses and checks src. It fails if src does not parse.
+func Source(src string, cfg Config) ([]Diagnostic, error) {
+	l := lexer.New(src)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+	return Program(program, l.Comments(), cfg), nil
+}
+
+// Program checks program. comments are the comments of its source, which
+// may contain suppression directives.
+func Program(program *ast.Program, comments []token.Token, cfg Config) []Diagnostic {
+	c := newChecker(cfg)
+	c.program(program)
+
+	ignored := suppressions(comments, codeLines(program))
+	diags := []Diagnostic{}
+	for _, d := range c.diags {
+		if ignored[d.Line][d.Rule] {
+			continue
+		}
+		diags = append(diags, d)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return diags
+}
+
+// codeLines returns the lines holding a token of program.
+func codeLines(program *ast.Program) map[int]bool {
+	lines := map[int]bool{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if node != nil {
+			lines[ast.TokenOf(node).Line] = true
+		}
+		return true
+	})
+	return lines
+}
+
+// suppressions maps lines to the rules ignored on them.
+func suppressions(comments []token.Token, code map[int]bool) map[int]map[string]bool {
+	ignored := map[int]map[string]bool{}
+	for _, comment := range comments {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Literal, "//"))
+		directive, rest, _ := strings.Cut(text, " ")
+		if directive != suppressionDirective {
+			continue
+		}
+		rules, _, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		line := comment.Line
+		if !code[line] {
+			line++
+		}
+		if ignored[line] == nil {
+			ignored[line] = map[string]bool{}
+		}
+		for _, rule := range strings.Split(rules, ",") {
+			ignored[line][rule] = true
+		}
+	}
+	return ignored
+}
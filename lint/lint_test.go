@@ -0,0 +1,148 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/stdlib"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; puts(y);", []string{"1:5: warning: x is declared but never used (unused-variable)", "1:17: error: undefined: y (undefined-name)"}},
+		{"let x = x + 1; x", []string{"1:9: error: undefined: x (undefined-name)"}},
+		{"let x = 1; if (true) { let x = x + 1; x }", nil},
+		{"let x = 1;\nlet x = 2; x", []string{"2:5: error: x redeclared in this scope (previous declaration at 1:5) (redeclared)"}},
+		{"let [a, a] = [1, 2]; a", []string{"1:9: error: a redeclared in this scope (previous declaration at 1:6) (redeclared)"}},
+		{"let f = fnc(a) { let a = 1; a }; f", []string{"1:22: error: a redeclared in this scope (previous declaration at 1:13) (redeclared)"}},
+		{"y = 1", []string{"1:1: error: assignment to undeclared name y (undeclared-assignment)"}},
+		{"len = 1", []string{"1:1: error: assignment to undeclared name len (undeclared-assignment)"}},
+		{"const c = 1; c += 1", []string{"1:14: error: cannot assign to constant c (constant-assignment)"}},
+		{`import "std/math" as m; m = 1`, []string{
+			"1:22: warning: m is declared but never used (unused-variable)",
+			"1:25: error: cannot assign to constant m (constant-assignment)",
+		}},
+		{"let x = 0; x = 1", []string{"1:5: warning: x is declared but never used (unused-variable)"}},
+		{"let x = 0; x++; let y = 0; y += 2", nil},
+		{"let f = fnc(a, b, _c) { a }; f", []string{"1:16: warning: parameter b is never used (unused-parameter)"}},
+		{"let _x = 1; let {name, ...rest} = {}; rest", []string{"1:18: warning: name is declared but never used (unused-variable)"}},
+		{"let f = fnc() {\n return 1;\n puts(2);\n puts(3);\n}; f", []string{"3:2: warning: unreachable code (unreachable-code)"}},
+		{"let f = fnc(x) { if (x) { return 1 } else { return 2 }; x }; f", []string{"1:57: warning: unreachable code (unreachable-code)"}},
+		{"let f = fnc(x) { if (x) { return 1 }; x }; f", nil},
+		{`let x = 1; "${x} ${y}"`, []string{"1:20: error: undefined: y (undefined-name)"}},
+		{"let x = 1; export {x, y};", []string{"1:23: error: undefined: y (undefined-name)"}},
+		{"let m = 1; m.size; m.f(size)", []string{"1:24: error: undefined: size (undefined-name)"}},
+		{`let h = {"name": 1}; let {name: n} = h; n`, nil},
+		{"let h = {name: 1}; h", []string{"1:10: error: undefined: name (undefined-name)"}},
+	}
+
+	for i, tt := range tests {
+		diags, err := Source(tt.input, Config{})
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error: %s", i, err)
+		}
+		checkDiagnostics(t, i, diags, tt.expected)
+	}
+}
+
+func TestFunctionsSeeLaterDeclarations(t *testing.T) {
+	input := `
+let isEven = fnc(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+let isOdd = fnc(n) { if (n == 0) { false } else { isEven(n - 1) } };
+let count = 0;
+let inc = fnc() { count += 1; total };
+inc();
+`
+	diags, err := Source(input, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, 0, diags, []string{"5:31: error: undefined: total (undefined-name)"})
+}
+
+func TestConfig(t *testing.T) {
+	input := "let x = 1; puts(y); native"
+	tests := []struct {
+		cfg      Config
+		expected []string
+	}{
+		{Config{Severities: map[string]Severity{UnusedVariable: Off}}, []string{
+			"1:17: error: undefined: y (undefined-name)",
+			"1:21: error: undefined: native (undefined-name)",
+		}},
+		{Config{Severities: map[string]Severity{UnusedVariable: Error, UndefinedName: Warning}, Globals: []string{"native"}}, []string{
+			"1:5: error: x is declared but never used (unused-variable)",
+			"1:17: warning: undefined: y (undefined-name)",
+		}},
+	}
+
+	for i, tt := range tests {
+		diags, err := Source(input, tt.cfg)
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error: %s", i, err)
+		}
+		checkDiagnostics(t, i, diags, tt.expected)
+	}
+}
+
+func TestSuppression(t *testing.T) {
+	input := `// lint:ignore unused-variable,undefined-name kept for later
+let x = y;
+let z = 1; // lint:ignore unused-variable
+let w = 2;
+puts(v); // lint:ignore unused-variable wrong rule
+`
+	diags, err := Source(input, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, 0, diags, []string{
+		"4:5: warning: w is declared but never used (unused-variable)",
+		"5:6: error: undefined: v (undefined-name)",
+	})
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Source("let = 1", Config{}); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, severity := range []Severity{Off, Warning, Error} {
+		got, err := ParseSeverity(severity.String())
+		if err != nil || got != severity {
+			t.Errorf("ParseSeverity(%q) = %v, %v", severity.String(), got, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestStdlibIsClean(t *testing.T) {
+	for _, name := range stdlib.Modules() {
+		src, _ := stdlib.Source(name)
+		diags, err := Source(src, Config{Globals: []string{"native"}})
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		for _, d := range diags {
+			t.Errorf("%s:%s", name, d)
+		}
+	}
+}
+
+func checkDiagnostics(t *testing.T, i int, diags []Diagnostic, expected []string) {
+	t.Helper()
+	got := []string{}
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("tests[%d] - wrong diagnostics.\nexpected=%q\ngot=     %q", i, expected, got)
+	}
+}
@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/token"
+	"github.com/Muto1907/interpreterInGo/types"
+)
+
+// document is an open text document and what is known about its program.
+type document struct {
+	uri     string
+	version int
+	text    string
+	lines   []string
+	errors  []parser.Error
+	// program, index and info describe the last version of the text that
+	// parsed. stale is set if the current version does not.
+	program *ast.Program
+	index   *index
+	info    *types.Info
+	stale   bool
+}
+
+func newDocument(uri string, version int, text string) *document {
+	doc := &document{uri: uri}
+	doc.update(version, text)
+	return doc
+}
+
+// update replaces the text and analyzes it.
+func (doc *document) update(version int, text string) {
+	doc.version, doc.text = version, text
+	doc.lines = strings.Split(text, "\n")
+
+	p := parser.New(lexer.New(text))
+	program := p.ParseProgram()
+	doc.errors = p.ErrorList()
+	if len(doc.errors) != 0 {
+		doc.stale = doc.program != nil
+		return
+	}
+	doc.program, doc.stale = program, false
+	doc.index = resolve(program)
+	doc.info = types.NewInfo()
+	types.Check(program, doc.info)
+}
+
+// analyzed reports whether positions in the current text can be looked up.
+func (doc *document) analyzed() bool {
+	return doc.program != nil && !doc.stale
+}
+
+func (doc *document) line(n int) string {
+	if n < 1 || n > len(doc.lines) {
+		return ""
+	}
+	return doc.lines[n-1]
+}
+
+// position converts a source position to an LSP position.
+func (doc *document) position(p pos) Position {
+	character := 0
+	column := 1
+	for _, r := range doc.line(p.line) {
+		if column >= p.column {
+			break
+		}
+		character += utf16.RuneLen(r)
+		column++
+	}
+	return Position{Line: p.line - 1, Character: character}
+}
+
+// pos converts an LSP position to a source position.
+func (doc *document) pos(position Position) pos {
+	character := 0
+	column := 1
+	for _, r := range doc.line(position.Line + 1) {
+		if character >= position.Character {
+			break
+		}
+		character += utf16.RuneLen(r)
+		column++
+	}
+	return pos{position.Line + 1, column}
+}
+
+func (doc *document) identRange(ident *ast.Identifier) Range {
+	return Range{Start: doc.position(posOf(ident.Token)), End: doc.position(identEnd(ident))}
+}
+
+func (doc *document) tokenRange(tok token.Token) Range {
+	start := posOf(tok)
+	end := pos{start.line, start.column + utf8.RuneCountInString(tok.Literal)}
+	if tok.Type == token.EOF || strings.Contains(tok.Literal, "\n") {
+		end = start
+	}
+	return Range{Start: doc.position(start), End: doc.position(end)}
+}
+
+// fullRange spans the whole text.
+func (doc *document) fullRange() Range {
+	last := len(doc.lines)
+	return Range{End: doc.position(pos{last, utf8.RuneCountInString(doc.line(last)) + 1})}
+}
+
+func (doc *document) diagnostics() []Diagnostic {
+	diags := []Diagnostic{}
+	for _, e := range doc.errors {
+		diags = append(diags, Diagnostic{
+			Range:    doc.tokenRange(e.Token),
+			Severity: SeverityError,
+			Source:   "chimp",
+			Message:  e.Message,
+		})
+	}
+	return diags
+}
@@ -0,0 +1,317 @@
+package lsp
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+type declKind int
+
+const (
+	variable declKind = iota
+	constant
+	parameter
+	imported
+)
+
+// decl is a declared name and the identifiers that refer to it. The name
+// can be referred to from the position from on.
+type decl struct {
+	ident *ast.Identifier
+	kind  declKind
+	scope *scope
+	from  pos
+	refs  []*ast.Identifier
+}
+
+// pos is a line and a rune column, both starting at 1, like the positions
+// of tokens.
+type pos struct {
+	line, column int
+}
+
+func posOf(tok token.Token) pos {
+	return pos{tok.Line, tok.Column}
+}
+
+func (p pos) before(q pos) bool {
+	return p.line < q.line || p.line == q.line && p.column < q.column
+}
+
+// identEnd returns the position just after ident.
+func identEnd(ident *ast.Identifier) pos {
+	return pos{ident.Token.Line, ident.Token.Column + utf8.RuneCountInString(ident.Token.Literal)}
+}
+
+// scope is the program, a block or a function, whose parameters and body
+// share one scope, and the part of the source it spans.
+type scope struct {
+	outer      *scope
+	decls      map[string]*decl
+	start, end pos
+	function   bool
+}
+
+func (s *scope) lookup(name string) *decl {
+	for ; s != nil; s = s.outer {
+		if d, ok := s.decls[name]; ok {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *scope) contains(p pos) bool {
+	return !p.before(s.start) && !s.end.before(p)
+}
+
+// ident is an identifier in the source and the declaration it refers to,
+// which is nil for builtins and undefined names.
+type ident struct {
+	ident *ast.Identifier
+	decl  *decl
+}
+
+// index relates the identifiers of a program to their declarations.
+type index struct {
+	decls  []*decl
+	idents []ident
+	scopes []*scope
+}
+
+// at returns the identifier at p.
+func (ix *index) at(p pos) (ident, bool) {
+	for _, id := range ix.idents {
+		if !p.before(posOf(id.ident.Token)) && !identEnd(id.ident).before(p) {
+			return id, true
+		}
+	}
+	return ident{}, false
+}
+
+// visible returns the declarations that can be referred to at p, the
+// innermost first. Names declared later in an enclosing scope are visible
+// inside functions, which run after their scope declared everything.
+func (ix *index) visible(p pos) []*decl {
+	var inner *scope
+	for _, s := range ix.scopes {
+		if s.contains(p) && (inner == nil || inner.start.before(s.start)) {
+			inner = s
+		}
+	}
+	decls := []*decl{}
+	seen := map[string]bool{}
+	deferred := false
+	for s := inner; s != nil; s = s.outer {
+		for _, d := range ix.decls {
+			if d.scope != s || seen[d.ident.Value] {
+				continue
+			}
+			if deferred || !p.before(d.from) {
+				decls = append(decls, d)
+				seen[d.ident.Value] = true
+			}
+		}
+		deferred = deferred || s.function
+	}
+	return decls
+}
+
+// function is a function literal whose body is resolved after the program.
+type function struct {
+	literal *ast.FuncLiteral
+	scope   *scope
+}
+
+// resolver binds names the way the evaluator does. Like the linter it
+// resolves function bodies after the program, so functions see the names
+// declared after them.
+type resolver struct {
+	index     *index
+	functions []function
+}
+
+func resolve(program *ast.Program) *index {
+	r := &resolver{index: &index{}}
+	s := r.newScope(nil, pos{1, 1}, pos{math.MaxInt, math.MaxInt}, false)
+	r.statements(program.Statements, s)
+	for len(r.functions) > 0 {
+		fn := r.functions[0]
+		r.functions = r.functions[1:]
+		r.function(fn)
+	}
+	return r.index
+}
+
+func (r *resolver) newScope(outer *scope, start, end pos, function bool) *scope {
+	s := &scope{outer: outer, decls: map[string]*decl{}, start: start, end: end, function: function}
+	r.index.scopes = append(r.index.scopes, s)
+	return s
+}
+
+// blockEnd returns the position of the closing brace of block, or end if
+// the block is not closed.
+func blockEnd(block *ast.BlockStatement, end pos) pos {
+	if block == nil || block.Close.Line == 0 {
+		return end
+	}
+	return posOf(block.Close)
+}
+
+func (r *resolver) function(fn function) {
+	end := blockEnd(fn.literal.Body, fn.scope.end)
+	s := r.newScope(fn.scope, posOf(fn.literal.Token), end, true)
+	for _, param := range fn.literal.Parameters {
+		r.declare(param, s, parameter)
+	}
+	if fn.literal.Body != nil {
+		r.statements(fn.literal.Body.Statements, s)
+	}
+}
+
+// statements resolves stmts. The names a statement declares are visible
+// from the next statement on.
+func (r *resolver) statements(stmts []ast.Statement, s *scope) {
+	for i, stmt := range stmts {
+		declared := len(r.index.decls)
+		r.statement(stmt, s)
+		from := s.end
+		if i+1 < len(stmts) {
+			from = posOf(ast.Start(stmts[i+1]))
+		}
+		for _, d := range r.index.decls[declared:] {
+			if d.scope == s {
+				d.from = from
+			}
+		}
+	}
+}
+
+func (r *resolver) statement(stmt ast.Statement, s *scope) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		r.expression(stmt.Value, s)
+		kind := variable
+		if stmt.IsConst() {
+			kind = constant
+		}
+		if stmt.Pattern != nil {
+			r.declare(stmt.Pattern, s, kind)
+		} else if stmt.Name != nil {
+			r.declare(stmt.Name, s, kind)
+		}
+	case *ast.ReturnStatement:
+		r.expression(stmt.ReturnValue, s)
+	case *ast.ExpressionStatement:
+		r.expression(stmt.Expression, s)
+	case *ast.ReassignmentStatement:
+		r.expression(stmt.Left, s)
+		r.expression(stmt.Value, s)
+	case *ast.WhileStatement:
+		r.expression(stmt.Condition, s)
+		r.block(stmt.Body, s)
+	case *ast.BlockStatement:
+		r.block(stmt, s)
+	case *ast.ImportStatement:
+		if stmt.Alias != nil {
+			r.declare(stmt.Alias, s, imported)
+		}
+		for _, name := range stmt.Names {
+			r.declare(name.Binding(), s, imported)
+		}
+	case *ast.ExportStatement:
+		for _, name := range stmt.Names {
+			r.use(name, s)
+		}
+	}
+}
+
+func (r *resolver) block(block *ast.BlockStatement, s *scope) {
+	if block != nil {
+		r.statements(block.Statements, r.newScope(s, posOf(block.Token), blockEnd(block, s.end), false))
+	}
+}
+
+// declare defines the names bound by pattern in s.
+func (r *resolver) declare(pattern ast.Pattern, s *scope, kind declKind) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		d := &decl{ident: pattern, kind: kind, scope: s, from: posOf(pattern.Token)}
+		s.decls[pattern.Value] = d
+		r.index.decls = append(r.index.decls, d)
+		r.record(pattern, d)
+	case *ast.ArrayPattern:
+		for _, element := range pattern.Elements {
+			r.declare(element, s, kind)
+		}
+		if pattern.Rest != nil {
+			r.declare(pattern.Rest, s, kind)
+		}
+	case *ast.HashPattern:
+		for _, pair := range pattern.Pairs {
+			r.declare(pair.Value, s, kind)
+		}
+		if pattern.Rest != nil {
+			r.declare(pattern.Rest, s, kind)
+		}
+	}
+}
+
+func (r *resolver) use(ident *ast.Identifier, s *scope) {
+	r.record(ident, s.lookup(ident.Value))
+}
+
+func (r *resolver) record(id *ast.Identifier, d *decl) {
+	if d != nil {
+		d.refs = append(d.refs, id)
+	}
+	r.index.idents = append(r.index.idents, ident{ident: id, decl: d})
+}
+
+func (r *resolver) expression(expr ast.Expression, s *scope) {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		r.use(expr, s)
+	case *ast.InterpolatedString:
+		r.expressions(expr.Parts, s)
+	case *ast.PrefixExpression:
+		r.expression(expr.Right, s)
+	case *ast.InfixExpression:
+		r.expression(expr.Left, s)
+		r.expression(expr.Right, s)
+	case *ast.IfExpression:
+		r.expression(expr.Condition, s)
+		r.block(expr.Then, s)
+		r.block(expr.Alt, s)
+	case *ast.FuncLiteral:
+		r.functions = append(r.functions, function{literal: expr, scope: s})
+	case *ast.CallExpression:
+		r.expression(expr.Function, s)
+		r.expressions(expr.Arguments, s)
+	case *ast.ArrayLiteral:
+		r.expressions(expr.Elements, s)
+	case *ast.HashLiteral:
+		for _, pair := range expr.Pairs {
+			r.expression(pair.Key, s)
+			r.expression(pair.Value, s)
+		}
+	case *ast.IndexExpression:
+		r.expression(expr.Left, s)
+		r.expression(expr.Index, s)
+	case *ast.SliceExpression:
+		r.expression(expr.Left, s)
+		r.expression(expr.Start, s)
+		r.expression(expr.End, s)
+	case *ast.MemberExpression:
+		r.expression(expr.Object, s)
+	}
+}
+
+func (r *resolver) expressions(exprs []ast.Expression, s *scope) {
+	for _, expr := range exprs {
+		r.expression(expr, s)
+	}
+}
@@ -0,0 +1,422 @@
+// Package lsp implements a Language Server Protocol server for chimp
+// scripts. It reports syntax errors, shows the inferred types of names on
+// hover, finds definitions and references, lists the top-level bindings of
+// a document, completes names and formats documents.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/format"
+	"github.com/Muto1907/interpreterInGo/types"
+)
+
+// errExitWithoutShutdown is returned by Serve if the client asks the server
+// to exit without shutting it down first.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+type server struct {
+	out         io.Writer
+	docs        map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+type handler func(s *server, params json.RawMessage) (any, error)
+
+var requests = map[string]handler{
+	"initialize":                  (*server).initialize,
+	"shutdown":                    (*server).shutdownRequest,
+	"textDocument/hover":          (*server).hover,
+	"textDocument/definition":     (*server).definition,
+	"textDocument/references":     (*server).references,
+	"textDocument/documentSymbol": (*server).documentSymbol,
+	"textDocument/completion":     (*server).completion,
+	"textDocument/formatting":     (*server).formatting,
+}
+
+var notifications = map[string]handler{
+	"textDocument/didOpen":   (*server).didOpen,
+	"textDocument/didChange": (*server).didChange,
+	"textDocument/didClose":  (*server).didClose,
+}
+
+// Serve reads requests from in and writes responses to out until the
+// client sends exit or in ends.
+func Serve(in io.Reader, out io.Writer) error {
+	s := &server{out: out, docs: map[string]*document{}}
+	r := bufio.NewReader(in)
+	for {
+		msg, err := readMessage(r)
+		var rpcErr *rpcError
+		switch {
+		case errors.As(err, &rpcErr):
+			if err := s.reply(json.RawMessage("null"), nil, rpcErr); err != nil {
+				return err
+			}
+			continue
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) handle(msg *message) error {
+	if !msg.isRequest() {
+		if h, ok := notifications[msg.Method]; ok && s.initialized && !s.shutdown {
+			_, err := h(s, msg.Params)
+			return err
+		}
+		return nil
+	}
+
+	h, ok := requests[msg.Method]
+	switch {
+	case !ok:
+		return s.reply(msg.ID, nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method})
+	case !s.initialized && msg.Method != "initialize":
+		return s.reply(msg.ID, nil, &rpcError{Code: codeServerNotInitialized, Message: "server not initialized"})
+	case s.shutdown:
+		return s.reply(msg.ID, nil, &rpcError{Code: codeInvalidRequest, Message: "server is shut down"})
+	}
+	result, err := h(s, msg.Params)
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return s.reply(msg.ID, nil, rpcErr)
+	}
+	return s.reply(msg.ID, result, nil)
+}
+
+func (s *server) reply(id json.RawMessage, result any, rpcErr *rpcError) error {
+	msg := &message{ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = data
+	}
+	return writeMessage(s.out, msg)
+}
+
+func (s *server) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: method, Params: data})
+}
+
+func decode[T any](params json.RawMessage) (T, error) {
+	var v T
+	if err := json.Unmarshal(params, &v); err != nil {
+		return v, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return v, nil
+}
+
+func (s *server) document(uri string) (*document, error) {
+	doc, ok := s.docs[uri]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown document " + uri}
+	}
+	return doc, nil
+}
+
+func (s *server) initialize(json.RawMessage) (any, error) {
+	s.initialized = true
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           1,
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			DocumentSymbolProvider:     true,
+			CompletionProvider:         map[string]any{},
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "chimp"},
+	}, nil
+}
+
+func (s *server) shutdownRequest(json.RawMessage) (any, error) {
+	s.shutdown = true
+	return nil, nil
+}
+
+func (s *server) publishDiagnostics(doc *document) error {
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     doc.version,
+		Diagnostics: doc.diagnostics(),
+	})
+}
+
+func (s *server) didOpen(params json.RawMessage) (any, error) {
+	p, err := decode[DidOpenTextDocumentParams](params)
+	if err != nil {
+		return nil, nil
+	}
+	doc := newDocument(p.TextDocument.URI, p.TextDocument.Version, p.TextDocument.Text)
+	s.docs[doc.uri] = doc
+	return nil, s.publishDiagnostics(doc)
+}
+
+func (s *server) didChange(params json.RawMessage) (any, error) {
+	p, err := decode[DidChangeTextDocumentParams](params)
+	if err != nil || len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+	doc, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	doc.update(p.TextDocument.Version, p.ContentChanges[len(p.ContentChanges)-1].Text)
+	return nil, s.publishDiagnostics(doc)
+}
+
+func (s *server) didClose(params json.RawMessage) (any, error) {
+	p, err := decode[DidCloseTextDocumentParams](params)
+	if err != nil {
+		return nil, nil
+	}
+	delete(s.docs, p.TextDocument.URI)
+	return nil, s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+// identAt returns the document of params and the identifier at its
+// position, if the document is analyzed.
+func (s *server) identAt(params TextDocumentPositionParams) (*document, ident, bool, error) {
+	doc, err := s.document(params.TextDocument.URI)
+	if err != nil || !doc.analyzed() {
+		return doc, ident{}, false, err
+	}
+	id, ok := doc.index.at(doc.pos(params.Position))
+	return doc, id, ok, nil
+}
+
+func (s *server) hover(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, id, ok, err := s.identAt(p)
+	if err != nil || !ok {
+		return nil, err
+	}
+	text := describe(id, doc.info)
+	if text == "" {
+		return nil, nil
+	}
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "```chimp\n" + text + "\n```"},
+		Range:    doc.identRange(id.ident),
+	}, nil
+}
+
+// describe returns the hover text of id: how its name is declared and its
+// inferred type.
+func describe(id ident, info *types.Info) string {
+	name := id.ident.Value
+	if id.decl == nil {
+		if !isBuiltIn(name) {
+			return ""
+		}
+		if typ, ok := info.Types[id.ident].(*types.Func); ok {
+			return "builtin " + name + ": " + typ.String()
+		}
+		return "builtin " + name
+	}
+
+	typ := info.Defs[id.decl.ident]
+	switch id.decl.kind {
+	case imported:
+		return "import " + name
+	case parameter:
+		name = "(parameter) " + name
+	case constant:
+		name = "const " + name
+	default:
+		name = "let " + name
+	}
+	if typ == nil {
+		return name
+	}
+	return name + ": " + typ.String()
+}
+
+func isBuiltIn(name string) bool {
+	for _, builtIn := range evaluator.BuiltInNames() {
+		if builtIn == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *server) definition(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, id, ok, err := s.identAt(p)
+	if err != nil || !ok || id.decl == nil {
+		return nil, err
+	}
+	return Location{URI: doc.uri, Range: doc.identRange(id.decl.ident)}, nil
+}
+
+func (s *server) references(params json.RawMessage) (any, error) {
+	p, err := decode[ReferenceParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, id, ok, err := s.identAt(p.TextDocumentPositionParams)
+	if err != nil || !ok || id.decl == nil {
+		return nil, err
+	}
+	locations := []Location{}
+	for _, ref := range id.decl.refs {
+		if ref == id.decl.ident && !p.Context.IncludeDeclaration {
+			continue
+		}
+		locations = append(locations, Location{URI: doc.uri, Range: doc.identRange(ref)})
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		a, b := locations[i].Range.Start, locations[j].Range.Start
+		return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+	})
+	return locations, nil
+}
+
+// documentSymbol lists the names bound by the top-level let statements.
+func (s *server) documentSymbol(params json.RawMessage) (any, error) {
+	p, err := decode[DocumentSymbolParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil || !doc.analyzed() {
+		return nil, err
+	}
+	symbols := []SymbolInformation{}
+	for _, stmt := range doc.program.Statements {
+		let, ok := stmt.(*ast.LetStatement)
+		if !ok {
+			continue
+		}
+		kind := SymbolVariable
+		if _, ok := let.Value.(*ast.FuncLiteral); ok {
+			kind = SymbolFunction
+		} else if let.IsConst() {
+			kind = SymbolConstant
+		}
+		for _, d := range doc.index.decls {
+			if d.scope.outer == nil && isBoundBy(d.ident, let) {
+				symbols = append(symbols, SymbolInformation{
+					Name:     d.ident.Value,
+					Kind:     kind,
+					Location: Location{URI: doc.uri, Range: doc.identRange(d.ident)},
+				})
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// isBoundBy reports whether let declares ident.
+func isBoundBy(ident *ast.Identifier, let *ast.LetStatement) bool {
+	if let.Pattern == nil {
+		return let.Name == ident
+	}
+	found := false
+	ast.Inspect(let.Pattern, func(node ast.Node) bool {
+		found = found || node == ident
+		return !found
+	})
+	return found
+}
+
+func (s *server) completion(params json.RawMessage) (any, error) {
+	p, err := decode[TextDocumentPositionParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+	if doc.program != nil {
+		for _, d := range doc.index.visible(doc.pos(p.Position)) {
+			item := CompletionItem{Label: d.ident.Value, Kind: CompletionVariable}
+			switch {
+			case d.kind == imported:
+				item.Kind = CompletionModule
+			case d.kind == constant:
+				item.Kind = CompletionConstant
+			}
+			if typ := doc.info.Defs[d.ident]; typ != nil {
+				if _, ok := typ.(*types.Func); ok {
+					item.Kind = CompletionFunction
+				}
+				item.Detail = typ.String()
+			}
+			items = append(items, item)
+			seen[item.Label] = true
+		}
+	}
+	for _, name := range evaluator.BuiltInNames() {
+		if !seen[name] {
+			items = append(items, CompletionItem{Label: name, Kind: CompletionFunction, Detail: "builtin"})
+		}
+	}
+	return items, nil
+}
+
+func (s *server) formatting(params json.RawMessage) (any, error) {
+	p, err := decode[DocumentFormattingParams](params)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(doc.text)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: fmt.Sprintf("cannot format: %s", strings.ReplaceAll(err.Error(), "\n", "; "))}
+	}
+	if formatted == doc.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: doc.fullRange(), NewText: formatted}}, nil
+}
@@ -0,0 +1,348 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// client talks to a server running in the same process. Its messages are
+// written by a separate goroutine, so the server never blocks the client
+// by writing a notification it has not read yet.
+type client struct {
+	t             *testing.T
+	in            chan *message
+	out           *bufio.Reader
+	id            int
+	notifications []*message
+	done          chan error
+}
+
+func newClient(t *testing.T) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, in: make(chan *message, 100), out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		err := Serve(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	go func() {
+		for msg := range c.in {
+			if writeMessage(inW, msg) != nil {
+				break
+			}
+		}
+		inW.Close()
+	}()
+	t.Cleanup(func() { close(c.in) })
+	return c
+}
+
+func (c *client) send(msg *message) {
+	c.in <- msg
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	data, _ := json.Marshal(params)
+	c.send(&message{Method: method, Params: data})
+}
+
+// call sends a request and returns its response. Notifications that
+// arrive in the meantime are collected.
+func (c *client) call(method string, params any) *message {
+	c.t.Helper()
+	c.id++
+	id := json.RawMessage(fmt.Sprint(c.id))
+	data, _ := json.Marshal(params)
+	c.send(&message{ID: id, Method: method, Params: data})
+	for {
+		msg, err := readMessage(c.out)
+		if err != nil {
+			c.t.Fatalf("read: %s", err)
+		}
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+		if string(msg.ID) != string(id) {
+			c.t.Fatalf("response to %s, expected %s", msg.ID, id)
+		}
+		return msg
+	}
+}
+
+// result calls method and decodes the result into v.
+func (c *client) result(method string, params, v any) {
+	c.t.Helper()
+	msg := c.call(method, params)
+	if msg.Error != nil {
+		c.t.Fatalf("%s failed: %s", method, msg.Error)
+	}
+	if err := json.Unmarshal(msg.Result, v); err != nil {
+		c.t.Fatalf("%s: cannot decode %s: %s", method, msg.Result, err)
+	}
+}
+
+// diagnostics waits for the diagnostics published for uri.
+func (c *client) diagnostics(uri string) []Diagnostic {
+	c.t.Helper()
+	for {
+		for i, msg := range c.notifications {
+			if msg.Method != "textDocument/publishDiagnostics" {
+				continue
+			}
+			c.notifications = append(c.notifications[:i], c.notifications[i+1:]...)
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(msg.Params, &params); err != nil || params.URI != uri {
+				c.t.Fatalf("unexpected diagnostics %s", msg.Params)
+			}
+			return params.Diagnostics
+		}
+		msg, err := readMessage(c.out)
+		if err != nil {
+			c.t.Fatalf("read: %s", err)
+		}
+		c.notifications = append(c.notifications, msg)
+	}
+}
+
+const uri = "file:///test.chimp"
+
+// open starts a server and opens a document with the given source.
+func open(t *testing.T, src string) *client {
+	c := newClient(t)
+	c.result("initialize", map[string]any{}, &InitializeResult{})
+	c.notify("initialized", map[string]any{})
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "chimp", Version: 1, Text: src},
+	})
+	return c
+}
+
+func at(line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func rangeString(r Range) string {
+	return fmt.Sprintf("%d:%d-%d:%d", r.Start.Line, r.Start.Character, r.End.Line, r.End.Character)
+}
+
+const src = `let add = fnc(a: int, b: int): int { a + b };
+let total = add(1, 2);
+if (total > 2) {
+	let total = "big";
+	puts(total);
+}
+let twice = fnc(f) { fnc(x) { f(f(x)) } };
+let [first, ...rest] = [1, 2, 3];
+const limit = 10;
+puts(twice(add)(total), first, limit);
+puts("${limit} of ${total}");
+`
+
+func TestDiagnostics(t *testing.T) {
+	c := open(t, "let x = ;\nlet y = 1")
+	diags := c.diagnostics(uri)
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diags)
+	}
+	if diags[0].Message != "No Prefix Parse Function found for ;" || rangeString(diags[0].Range) != "0:8-0:9" {
+		t.Errorf("wrong diagnostic %+v", diags[0])
+	}
+
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "let x = 1;\nlet y = x;"}},
+	})
+	if diags := c.diagnostics(uri); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	c.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	if diags := c.diagnostics(uri); len(diags) != 0 {
+		t.Errorf("expected diagnostics to be cleared, got %v", diags)
+	}
+}
+
+func TestHover(t *testing.T) {
+	tests := []struct {
+		line, character int
+		expected        string
+	}{
+		{0, 5, "let add: fnc(int, int): int"},
+		{0, 37, "(parameter) a: int"},
+		{1, 13, "let add: fnc(int, int): int"},
+		{1, 5, "let total: int"},
+		{4, 7, `let total: string`},
+		{4, 2, "builtin puts: fnc(...any): null"},
+		{7, 15, "let rest: [int]"},
+		{8, 7, "const limit: int"},
+		{0, 0, ""},
+	}
+
+	c := open(t, src)
+	for _, tt := range tests {
+		var hover *Hover
+		c.result("textDocument/hover", at(tt.line, tt.character), &hover)
+		got := ""
+		if hover != nil {
+			got = strings.TrimSuffix(strings.TrimPrefix(hover.Contents.Value, "```chimp\n"), "\n```")
+		}
+		if got != tt.expected {
+			t.Errorf("hover at %d:%d. expected=%q got=%q", tt.line, tt.character, tt.expected, got)
+		}
+	}
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	tests := []struct {
+		line, character int
+		definition      string
+		references      []string
+	}{
+		{1, 13, "0:4-0:7", []string{"0:4-0:7", "1:12-1:15", "9:11-9:14"}},
+		{4, 7, "3:5-3:10", []string{"3:5-3:10", "4:6-4:11"}},
+		{9, 17, "1:4-1:9", []string{"1:4-1:9", "2:4-2:9", "9:16-9:21", "10:20-10:25"}},
+		{10, 10, "8:6-8:11", []string{"8:6-8:11", "9:31-9:36", "10:8-10:13"}},
+		{6, 33, "6:16-6:17", []string{"6:16-6:17", "6:30-6:31", "6:32-6:33"}},
+		{4, 2, "", nil},
+	}
+
+	c := open(t, src)
+	for _, tt := range tests {
+		var location *Location
+		c.result("textDocument/definition", at(tt.line, tt.character), &location)
+		got := ""
+		if location != nil {
+			got = rangeString(location.Range)
+		}
+		if got != tt.definition {
+			t.Errorf("definition at %d:%d. expected=%q got=%q", tt.line, tt.character, tt.definition, got)
+		}
+
+		var locations []Location
+		c.result("textDocument/references", ReferenceParams{
+			TextDocumentPositionParams: at(tt.line, tt.character),
+			Context:                    ReferenceContext{IncludeDeclaration: true},
+		}, &locations)
+		refs := []string{}
+		for _, l := range locations {
+			refs = append(refs, rangeString(l.Range))
+		}
+		if strings.Join(refs, " ") != strings.Join(tt.references, " ") {
+			t.Errorf("references at %d:%d. expected=%v got=%v", tt.line, tt.character, tt.references, refs)
+		}
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := open(t, src)
+	var symbols []SymbolInformation
+	c.result("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+	got := []string{}
+	for _, sym := range symbols {
+		got = append(got, fmt.Sprintf("%s:%d@%s", sym.Name, sym.Kind, rangeString(sym.Location.Range)))
+	}
+	expected := []string{
+		"add:12@0:4-0:7",
+		"total:13@1:4-1:9",
+		"twice:12@6:4-6:9",
+		"first:13@7:5-7:10",
+		"rest:13@7:15-7:19",
+		"limit:14@8:6-8:11",
+	}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("wrong symbols.\nexpected=%v\ngot=     %v", expected, got)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	tests := []struct {
+		line, character int
+		expected        []string
+		unexpected      []string
+	}{
+		{1, 12, []string{"add fnc(int, int): int", "len builtin"}, []string{"total", "twice", "a"}},
+		{0, 38, []string{"a int", "b int", "add fnc(int, int): int", "limit int"}, nil},
+		{4, 2, []string{"total string", "add fnc(int, int): int"}, []string{"first"}},
+		{6, 30, []string{"x any", "f any", "twice fnc(any): fnc(any): any", "rest [int]"}, nil},
+	}
+
+	c := open(t, src)
+	for _, tt := range tests {
+		var items []CompletionItem
+		c.result("textDocument/completion", at(tt.line, tt.character), &items)
+		got := map[string]string{}
+		for _, item := range items {
+			if _, ok := got[item.Label]; ok {
+				t.Errorf("duplicate completion %s at %d:%d", item.Label, tt.line, tt.character)
+			}
+			got[item.Label] = item.Detail
+		}
+		for _, e := range tt.expected {
+			label, detail, _ := strings.Cut(e, " ")
+			if d, ok := got[label]; !ok || d != detail {
+				t.Errorf("completion at %d:%d: expected %q, got %q (present %t)", tt.line, tt.character, e, d, ok)
+			}
+		}
+		for _, label := range tt.unexpected {
+			if _, ok := got[label]; ok {
+				t.Errorf("completion at %d:%d: unexpected %s", tt.line, tt.character, label)
+			}
+		}
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	c := open(t, "let  x=1\nputs( x )")
+	params := DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}}
+	var edits []TextEdit
+	c.result("textDocument/formatting", params, &edits)
+	if len(edits) != 1 {
+		t.Fatalf("expected 1 edit, got %v", edits)
+	}
+	if edits[0].NewText != "let x = 1;\nputs(x);\n" || rangeString(edits[0].Range) != "0:0-1:9" {
+		t.Errorf("wrong edit %+v", edits[0])
+	}
+
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: edits[0].NewText}},
+	})
+	c.result("textDocument/formatting", params, &edits)
+	if len(edits) != 0 {
+		t.Errorf("expected no edits for formatted source, got %v", edits)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	c := newClient(t)
+	if msg := c.call("textDocument/hover", at(0, 0)); msg.Error == nil || msg.Error.Code != codeServerNotInitialized {
+		t.Errorf("expected not initialized error, got %+v", msg)
+	}
+	c.result("initialize", map[string]any{}, &InitializeResult{})
+	if msg := c.call("textDocument/rename", at(0, 0)); msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+		t.Errorf("expected method not found error, got %+v", msg)
+	}
+	if msg := c.call("shutdown", nil); msg.Error != nil || string(msg.Result) != "null" {
+		t.Errorf("wrong shutdown response %+v", msg)
+	}
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+
+	c = newClient(t)
+	c.notify("exit", nil)
+	if err := <-c.done; err != errExitWithoutShutdown {
+		t.Errorf("expected %s, got %v", errExitWithoutShutdown, err)
+	}
+}
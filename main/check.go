@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Muto1907/interpreterInGo/types"
+)
+
+// checkCommand type checks scripts: chimp check [files]. Without files it
+// checks standard input. It fails if any type error is reported.
+func checkCommand(args []string) int {
+	if len(args) == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return checkFile("<stdin>", string(src))
+	}
+
+	code := 0
+	for _, name := range args {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		if c := checkFile(name, string(src)); c != 0 {
+			code = c
+		}
+	}
+	return code
+}
+
+func checkFile(name, src string) int {
+	errs, err := types.Source(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return 1
+	}
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", name, e)
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
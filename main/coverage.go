@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Muto1907/interpreterInGo/coverage"
+)
+
+// reportCoverage writes the coverage recorded by r to dir as lcov.info and
+// coverage.html and prints a summary. It fails if less than min percent of
+// the statements ran.
+func reportCoverage(dir string, r *coverage.Recorder, min float64) int {
+	prof, err := r.Profile()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	reports := map[string]func(io.Writer) error{
+		"lcov.info":     prof.WriteLcov,
+		"coverage.html": prof.WriteHTML,
+	}
+	for name, write := range reports {
+		if err := writeFile(filepath.Join(dir, name), write); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	statements := coverage.Percent(prof.StatementsCovered())
+	fmt.Fprintf(os.Stderr, "coverage: %.1f%% of statements, %.1f%% of branches\n",
+		statements, coverage.Percent(prof.BranchesCovered()))
+	if statements < min {
+		fmt.Fprintf(os.Stderr, "coverage %.1f%% is below %.1f%%\n", statements, min)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Muto1907/interpreterInGo/debug"
+)
+
+// debugCommand debugs a script: chimp debug file.chimp starts a debugging
+// REPL, chimp debug -dap serves the Debug Adapter Protocol over standard
+// input and output.
+func debugCommand(args []string) int {
+	flags := flag.NewFlagSet("debug", flag.ContinueOnError)
+	dap := flags.Bool("dap", false, "serve the Debug Adapter Protocol on stdin and stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *dap && flags.NArg() != 0 || !*dap && flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: chimp debug file.chimp | chimp debug -dap")
+		return 2
+	}
+
+	var err error
+	if *dap {
+		err = debug.ServeDAP(os.Stdin, os.Stdout)
+	} else {
+		err = debug.RunREPL(flags.Arg(0), os.Stdin, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
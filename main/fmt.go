@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Muto1907/interpreterInGo/format"
+)
+
+// fmtCommand formats scripts: chimp fmt [-w] [-d] [files]. Without files it
+// formats standard input to standard output.
+func fmtCommand(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := flags.Bool("w", false, "write the result back to the files")
+	diff := flags.Bool("d", false, "print diffs instead of the formatted source")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return formatFile("<stdin>", src, false, *diff)
+	}
+
+	code := 0
+	for _, name := range flags.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		if c := formatFile(name, src, *write, *diff); c != 0 {
+			code = c
+		}
+	}
+	return code
+}
+
+func formatFile(name string, src []byte, write, diff bool) int {
+	out, err := format.Source(string(src))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return 1
+	}
+	if diff {
+		fmt.Print(format.Diff(name, string(src), out))
+	}
+	if write {
+		if out == string(src) {
+			return 0
+		}
+		if err := os.WriteFile(name, []byte(out), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else if !diff {
+		fmt.Print(out)
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+)
+
+// tokensCommand prints the tokens of a script: chimp tokens [-json]
+// [-comments] [file]. Without a file it reads standard input.
+func tokensCommand(args []string) int {
+	flags := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the tokens as a JSON array")
+	comments := flags.Bool("comments", false, "include comments")
+	src, code := readSource(flags, args)
+	if code != 0 {
+		return code
+	}
+
+	l := lexer.New(src)
+	tokens := l.Tokens()
+	if *comments {
+		tokens = append(tokens, l.Comments()...)
+		sort.SliceStable(tokens, func(i, j int) bool {
+			a, b := tokens[i], tokens[j]
+			return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+		})
+	}
+
+	if *asJSON {
+		return printJSON(tokens)
+	}
+	for _, tok := range tokens {
+		fmt.Printf("%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Literal)
+	}
+	return 0
+}
+
+// astCommand prints the syntax tree of a script: chimp ast [-json] [file].
+// Without a file it reads standard input.
+func astCommand(args []string) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	src, code := readSource(flags, args)
+	if code != 0 {
+		return code
+	}
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, msg := range p.Errors() {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		return 1
+	}
+
+	if *asJSON {
+		data, err := ast.ToJSON(program)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return printJSON(json.RawMessage(data))
+	}
+	printTree(program)
+	return 0
+}
+
+// readSource parses the flags and reads the file named by the only
+// argument, or standard input if there is none.
+func readSource(flags *flag.FlagSet, args []string) (string, int) {
+	if err := flags.Parse(args); err != nil {
+		return "", 2
+	}
+	var src []byte
+	var err error
+	switch flags.NArg() {
+	case 0:
+		src, err = io.ReadAll(os.Stdin)
+	case 1:
+		src, err = os.ReadFile(flags.Arg(0))
+	default:
+		fmt.Fprintf(os.Stderr, "usage: chimp %s [flags] [file.chimp]\n", flags.Name())
+		return "", 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return "", 1
+	}
+	return string(src), 0
+}
+
+func printJSON(v any) int {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
+
+// printTree prints one node per line, indented by depth, with its position
+// and the operator, name or value it holds.
+func printTree(root ast.Node) {
+	depth := 0
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node == nil {
+			depth--
+			return false
+		}
+		line := strings.Repeat("  ", depth) + strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+		if tok := ast.TokenOf(node); tok.Line > 0 {
+			line += fmt.Sprintf(" %d:%d", tok.Line, tok.Column)
+		}
+		switch n := node.(type) {
+		case *ast.Identifier:
+			line += " " + n.Value
+		case *ast.IntegerLiteral, *ast.Boolean:
+			line += " " + n.String()
+		case *ast.StringLiteral:
+			line += fmt.Sprintf(" %q", n.Value)
+		case *ast.PrefixExpression:
+			line += " " + n.Operator
+		case *ast.InfixExpression:
+			line += " " + n.Operator
+		case *ast.ReassignmentStatement:
+			line += " " + n.Operator
+		case *ast.LetStatement:
+			if n.IsConst() {
+				line += " const"
+			}
+		}
+		fmt.Println(line)
+		depth++
+		return true
+	})
+}
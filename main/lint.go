@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/lint"
+)
+
+// lintCommand checks scripts: chimp lint [-severity rule=level,...]
+// [-globals names] [files]. Without files it checks standard input. It
+// fails if any error level diagnostic is reported.
+func lintCommand(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	severities := flags.String("severity", "", "comma separated `rule=level` overrides, level is off, warning or error")
+	globals := flags.String("globals", "", "comma separated `names` defined outside of the scripts")
+	rules := flags.Bool("rules", false, "list the rules and exit")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *rules {
+		for _, rule := range lint.Rules {
+			fmt.Printf("%-22s %-8s %s\n", rule.ID, rule.Severity, rule.Doc)
+		}
+		return 0
+	}
+
+	cfg, err := lintConfig(*severities, *globals)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return lintFile("<stdin>", string(src), cfg)
+	}
+
+	code := 0
+	for _, name := range flags.Args() {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+		if c := lintFile(name, string(src), cfg); c != 0 {
+			code = c
+		}
+	}
+	return code
+}
+
+func lintConfig(severities, globals string) (lint.Config, error) {
+	cfg := lint.Config{Severities: map[string]lint.Severity{}}
+	if globals != "" {
+		cfg.Globals = strings.Split(globals, ",")
+	}
+	if severities == "" {
+		return cfg, nil
+	}
+	for _, override := range strings.Split(severities, ",") {
+		rule, level, ok := strings.Cut(override, "=")
+		if !ok {
+			return cfg, fmt.Errorf("invalid severity override %q, want rule=level", override)
+		}
+		if !knownRule(rule) {
+			return cfg, fmt.Errorf("unknown rule %q", rule)
+		}
+		severity, err := lint.ParseSeverity(level)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Severities[rule] = severity
+	}
+	return cfg, nil
+}
+
+func knownRule(id string) bool {
+	for _, rule := range lint.Rules {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func lintFile(name, src string, cfg lint.Config) int {
+	diags, err := lint.Source(src, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		return 1
+	}
+	code := 0
+	for _, d := range diags {
+		fmt.Printf("%s:%s\n", name, d)
+		if d.Severity == lint.Error {
+			code = 1
+		}
+	}
+	return code
+}
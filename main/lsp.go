@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Muto1907/interpreterInGo/lsp"
+)
+
+// lspCommand runs a language server speaking LSP over standard input and
+// output: chimp lsp.
+func lspCommand(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "usage: chimp lsp")
+		return 2
+	}
+	if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
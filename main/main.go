@@ -4,11 +4,37 @@ import (
 	"fmt"
 	"os"
 	"os/user"
+	"strings"
 
 	"github.com/Muto1907/interpreterInGo/repl"
 )
 
+// commands are the subcommands of the chimp binary. Each gets its own
+// arguments and returns the exit code.
+var commands = map[string]func(args []string) int{
+	"run":    runCommand,
+	"fmt":    fmtCommand,
+	"tokens": tokensCommand,
+	"ast":    astCommand,
+	"lint":   lintCommand,
+	"check":  checkCommand,
+	"lsp":    lspCommand,
+	"debug":  debugCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		name, args := os.Args[1], os.Args[2:]
+		if cmd, ok := commands[name]; ok {
+			os.Exit(cmd(args))
+		}
+		if strings.HasSuffix(name, ".chimp") || strings.HasPrefix(name, "-") {
+			os.Exit(runCommand(os.Args[1:]))
+		}
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		os.Exit(2)
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
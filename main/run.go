@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Muto1907/interpreterInGo/coverage"
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/profile"
+)
+
+// runCommand evaluates a script file: chimp run [-path dirs] [-strict]
+// [-check] [-profile file [-profileformat format]] [-coverage dir
+// [-covermin percent]] file.chimp
+func runCommand(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	path := flags.String("path", os.Getenv("CHIMP_PATH"), "`dirs` searched for imports, separated by "+string(os.PathListSeparator))
+	strict := flags.Bool("strict", false, "make integer overflow an error")
+	check := flags.Bool("check", false, "type check the script before evaluating it")
+	profileFile := flags.String("profile", "", "write a profile of the script to `file`")
+	profileFormat := flags.String("profileformat", "text", "`format` of the profile: text, folded or pprof")
+	coverDir := flags.String("coverage", "", "write lcov.info and coverage.html to `dir`")
+	coverMin := flags.Float64("covermin", 0, "fail if less than `percent` of the statements ran")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: chimp run [flags] file.chimp")
+		return 2
+	}
+	writeProfile, ok := profileWriters[*profileFormat]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown profile format %q\n", *profileFormat)
+		return 2
+	}
+	if *coverMin != 0 && *coverDir == "" {
+		fmt.Fprintln(os.Stderr, "-covermin needs -coverage")
+		return 2
+	}
+
+	if *check {
+		src, err := os.ReadFile(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if code := checkFile(flags.Arg(0), string(src)); code != 0 {
+			return code
+		}
+	}
+
+	eval := evaluator.NewEval()
+	eval.SetSearchPath(filepath.SplitList(*path)...)
+	eval.SetStrictIntegers(*strict)
+	var profiler *profile.Profiler
+	if *profileFile != "" {
+		profiler = profile.New()
+		eval.SetProfiler(profiler)
+	}
+	var recorder *coverage.Recorder
+	if *coverDir != "" {
+		recorder = coverage.New()
+		eval.SetCoverage(recorder)
+	}
+	code := 0
+	if err, ok := eval.RunFile(flags.Arg(0)).(*object.Error); ok {
+		fmt.Fprintln(os.Stderr, err.Inspect())
+		code = 1
+	}
+	if profiler != nil {
+		write := func(w io.Writer) error { return writeProfile(profiler.Profile(), w) }
+		if err := writeFile(*profileFile, write); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}
+	if recorder != nil {
+		if c := reportCoverage(*coverDir, recorder, *coverMin); c != 0 {
+			code = c
+		}
+	}
+	return code
+}
+
+// profileWriters write profiles in the formats of the -profileformat flag.
+var profileWriters = map[string]func(*profile.Profile, io.Writer) error{
+	"text":   (*profile.Profile).WriteText,
+	"folded": (*profile.Profile).WriteFolded,
+	"pprof":  (*profile.Profile).WritePprof,
+}
+
+// writeFile creates the file name and writes to it with write.
+func writeFile(name string, write func(io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -2,8 +2,13 @@ package object
 
 import (
 	"bytes"
+	"cmp"
+	"encoding/binary"
 	"fmt"
 	"hash/fnv"
+	"math/big"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/Muto1907/interpreterInGo/ast"
@@ -14,6 +19,7 @@ type Heap map[uint64]HeapObject
 
 const (
 	INTEGER_OBJ  = "INTEGER"
+	BIGINT_OBJ   = "BIG_INTEGER"
 	BOOLEAN_OBJ  = "BOOLEAN"
 	NULL_OBJ     = "NULL"
 	RETURN_OBJ   = "RETURN_VALUE"
@@ -24,6 +30,7 @@ const (
 	ARRAY_OBJ    = "ARRAY"
 	HASH_OBJ     = "HASH"
 	POINTER_OBJ  = "POINER"
+	MODULE_OBJ   = "MODULE"
 )
 
 type Object interface {
@@ -41,8 +48,9 @@ func NewHeapOject(object Object) HeapObject {
 }
 
 type Environment struct {
-	State map[string]Object
-	Outer *Environment
+	State     map[string]Object
+	Outer     *Environment
+	constants map[string]bool
 }
 
 func NewEnvironment() *Environment {
@@ -55,6 +63,25 @@ func (env *Environment) Set(ident string, val Object) Object {
 	return val
 }
 
+// SetConst defines ident as a binding that can not be reassigned.
+func (env *Environment) SetConst(ident string, val Object) Object {
+	if env.constants == nil {
+		env.constants = make(map[string]bool)
+	}
+	env.constants[ident] = true
+	return env.Set(ident, val)
+}
+
+// IsConst reports whether ident resolves to a constant binding.
+func (env *Environment) IsConst(ident string) bool {
+	for e := env; e != nil; e = e.Outer {
+		if _, ok := e.State[ident]; ok {
+			return e.constants[ident]
+		}
+	}
+	return false
+}
+
 func (env *Environment) SetOuter(ident string, val Object) Object {
 	if env.Outer == nil {
 		return &Error{Message: fmt.Sprintf("Variable not initialized: %s", ident)}
@@ -87,6 +114,7 @@ func NewEnclosedEnvironment(Outer *Environment) *Environment {
 }
 
 type Hashable interface {
+	Object
 	HashKey() HashKey
 }
 type HashKey struct {
@@ -99,8 +127,127 @@ type HashPair struct {
 	Value Object
 }
 
+// AsHashable reports whether obj can be used as a hash key. Arrays are only
+// hashable when all of their elements are and they do not contain
+// themselves.
+func AsHashable(obj Object) (Hashable, bool) {
+	if !hashable(obj, map[*Array]bool{}) {
+		return nil, false
+	}
+	key, ok := obj.(Hashable)
+	return key, ok
+}
+
+// hashable checks obj, which is inside the arrays in path.
+func hashable(obj Object, path map[*Array]bool) bool {
+	arr, ok := obj.(*Array)
+	if !ok {
+		_, ok := obj.(Hashable)
+		return ok
+	}
+	if path[arr] {
+		return false
+	}
+	path[arr] = true
+	defer delete(path, arr)
+	for _, element := range arr.Elements {
+		if !hashable(element, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hash keeps its pairs in insertion order, so iterating and printing it is
+// deterministic. Keys whose HashKey collides share a bucket and are told
+// apart with Equal.
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	entries []*HashPair
+	index   map[HashKey][]*HashPair
+	// Frozen hashes are read-only for scripts; Set still works for Go code.
+	Frozen bool
+}
+
+func NewHash() *Hash {
+	return &Hash{index: make(map[HashKey][]*HashPair)}
+}
+
+func (hash *Hash) lookup(key Hashable) (*HashPair, int) {
+	for i, entry := range hash.index[key.HashKey()] {
+		if Equal(entry.Key, key) {
+			return entry, i
+		}
+	}
+	return nil, -1
+}
+
+// Get looks up the pair stored under key.
+func (hash *Hash) Get(key Hashable) (HashPair, bool) {
+	entry, _ := hash.lookup(key)
+	if entry == nil {
+		return HashPair{}, false
+	}
+	return *entry, true
+}
+
+// Set inserts a new pair at the end or updates an existing one in place.
+// Array keys are copied so later changes to the array can not corrupt the
+// hash.
+func (hash *Hash) Set(key Hashable, value Object) {
+	if entry, _ := hash.lookup(key); entry != nil {
+		entry.Value = value
+		return
+	}
+	entry := &HashPair{Key: copyKey(key), Value: value}
+	hash.index[key.HashKey()] = append(hash.index[key.HashKey()], entry)
+	hash.entries = append(hash.entries, entry)
+}
+
+// Delete removes the pair stored under key and returns it.
+func (hash *Hash) Delete(key Hashable) (HashPair, bool) {
+	entry, pos := hash.lookup(key)
+	if entry == nil {
+		return HashPair{}, false
+	}
+	hashKey := key.HashKey()
+	bucket := hash.index[hashKey]
+	if len(bucket) == 1 {
+		delete(hash.index, hashKey)
+	} else {
+		hash.index[hashKey] = append(bucket[:pos:pos], bucket[pos+1:]...)
+	}
+	for i, e := range hash.entries {
+		if e == entry {
+			hash.entries = append(hash.entries[:i], hash.entries[i+1:]...)
+			break
+		}
+	}
+	return *entry, true
+}
+
+func copyKey(key Object) Object {
+	arr, ok := key.(*Array)
+	if !ok {
+		return key
+	}
+	elements := make([]Object, len(arr.Elements))
+	for i, element := range arr.Elements {
+		elements[i] = copyKey(element)
+	}
+	return &Array{Elements: elements}
+}
+
+func (hash *Hash) Len() int {
+	return len(hash.entries)
+}
+
+// Pairs returns the pairs in insertion order.
+func (hash *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, len(hash.entries))
+	for i, entry := range hash.entries {
+		pairs[i] = *entry
+	}
+	return pairs
 }
 
 func (hash *Hash) Type() ObjectType {
@@ -110,7 +257,7 @@ func (hash *Hash) Type() ObjectType {
 func (hash *Hash) Inspect() string {
 	var output bytes.Buffer
 	pairs := []string{}
-	for _, pair := range hash.Pairs {
+	for _, pair := range hash.entries {
 		pairs = append(pairs, pair.Key.Inspect()+": "+pair.Value.Inspect())
 	}
 	output.WriteString("{")
@@ -131,10 +278,55 @@ func (i *Integer) Inspect() string {
 	return fmt.Sprintf("%d", i.Value)
 }
 
+// HashKey reinterprets the bits of the value, so distinct integers, negative
+// ones included, never share a key.
 func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// BigInteger holds integers that do not fit into an int64. Use NewInteger to
+// build results, so that values which fit are kept as plain Integers.
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (bi *BigInteger) Type() ObjectType {
+	return BIGINT_OBJ
+}
+
+func (bi *BigInteger) Inspect() string {
+	return bi.Value.String()
+}
+
+func (bi *BigInteger) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte{byte(bi.Value.Sign() + 1)})
+	h.Write(bi.Value.Bytes())
+	return HashKey{Type: bi.Type(), Value: h.Sum64()}
+}
+
+// NewInteger returns value as an Integer if it fits into an int64 and as a
+// BigInteger otherwise.
+func NewInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &BigInteger{Value: value}
+}
+
+// ToBigInt converts an Integer or BigInteger to a big.Int, which callers must
+// not modify.
+func ToBigInt(obj Object) (*big.Int, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value), true
+	case *BigInteger:
+		return obj.Value, true
+	default:
+		return nil, false
+	}
+}
+
 type Pointer struct {
 	Value uint64
 }
@@ -220,7 +412,7 @@ func (er *Error) Inspect() string {
 }
 
 type Function struct {
-	Params []*ast.Identifier
+	Params []ast.Pattern
 	Body   *ast.BlockStatement
 	Env    *Environment
 }
@@ -238,13 +430,17 @@ func (fn *Function) Inspect() string {
 
 	output.WriteString("fnc(")
 	output.WriteString(strings.Join(parameters, ", "))
-	output.WriteString(") {\n")
+	output.WriteString(") ")
 	output.WriteString(fn.Body.String())
-	output.WriteString("\n}")
 	return output.String()
 }
 
-type BuiltInFunction func(args ...Object) Object
+// Caller lets builtins call back into user defined functions.
+type Caller interface {
+	CallFunction(fnc Object, args ...Object) Object
+}
+
+type BuiltInFunction func(caller Caller, args ...Object) Object
 
 type BuiltIn struct {
 	Fnc BuiltInFunction
@@ -253,11 +449,28 @@ type BuiltIn struct {
 func (bi *BuiltIn) Type() ObjectType { return BUILTIN_OBJ }
 func (bi *BuiltIn) Inspect() string  { return "builtIn Function" }
 
+// Array is read-only once Frozen is set.
 type Array struct {
 	Elements []Object
+	Frozen   bool
 }
 
 func (arr *Array) Type() ObjectType { return ARRAY_OBJ }
+
+// HashKey combines the keys of the elements, which callers must first check
+// to be hashable and free of cycles with AsHashable.
+func (arr *Array) HashKey() HashKey {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, element := range arr.Elements {
+		key := element.(Hashable).HashKey()
+		h.Write([]byte(key.Type))
+		binary.LittleEndian.PutUint64(buf[:], key.Value)
+		h.Write(buf[:])
+	}
+	return HashKey{Type: arr.Type(), Value: h.Sum64()}
+}
+
 func (arr *Array) Inspect() string {
 	var output bytes.Buffer
 
@@ -270,3 +483,229 @@ func (arr *Array) Inspect() string {
 	output.WriteString("]")
 	return output.String()
 }
+
+// Equal reports whether two objects have the same value. Arrays and hashes
+// are compared element by element; hashes are equal when they hold the same
+// pairs, regardless of insertion order. Values that contain themselves are
+// equal when no element tells them apart.
+func Equal(a, b Object) bool {
+	return equal(a, b, map[objectPair]bool{})
+}
+
+// objectPair is two arrays or hashes being compared. Meeting it again while
+// comparing their elements means the values are cyclic, and the comparison
+// under way decides the result.
+type objectPair struct {
+	a, b Object
+}
+
+func equal(a, b Object, seen map[objectPair]bool) bool {
+	if a == b {
+		return true
+	}
+	switch a := a.(type) {
+	case *Integer:
+		if b, ok := b.(*BigInteger); ok {
+			return b.Value.Cmp(big.NewInt(a.Value)) == 0
+		}
+		b, ok := b.(*Integer)
+		return ok && a.Value == b.Value
+	case *BigInteger:
+		other, ok := ToBigInt(b)
+		return ok && a.Value.Cmp(other) == 0
+	case *String:
+		b, ok := b.(*String)
+		return ok && a.Value == b.Value
+	case *Boolean:
+		b, ok := b.(*Boolean)
+		return ok && a.Value == b.Value
+	case *NULL:
+		_, ok := b.(*NULL)
+		return ok
+	case *Pointer:
+		b, ok := b.(*Pointer)
+		return ok && a.Value == b.Value
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		if seen[objectPair{a, b}] {
+			return true
+		}
+		seen[objectPair{a, b}] = true
+		for i := range a.Elements {
+			if !equal(a.Elements[i], b.Elements[i], seen) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		if seen[objectPair{a, b}] {
+			return true
+		}
+		seen[objectPair{a, b}] = true
+		for _, pair := range a.entries {
+			other, ok := b.Get(pair.Key.(Hashable))
+			if !ok || !equal(pair.Value, other.Value, seen) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+var typeOrder = map[ObjectType]int{
+	NULL_OBJ:    0,
+	BOOLEAN_OBJ: 1,
+	INTEGER_OBJ: 2,
+	BIGINT_OBJ:  2,
+	STRING_OBJ:  3,
+	ARRAY_OBJ:   4,
+	HASH_OBJ:    5,
+	POINTER_OBJ: 6,
+}
+
+// Compare orders values: it returns a negative number when a sorts before
+// b, a positive number when it sorts after b and zero otherwise. Values of
+// different types are ordered by type, arrays lexicographically and hashes
+// by their sorted pairs. For null, booleans, numbers, strings, pointers and
+// arrays and hashes of them zero means Equal. Types without a natural order,
+// such as functions, sort after all others and compare as zero among
+// themselves, even when Equal reports them as different.
+func Compare(a, b Object) int {
+	return compare(a, b, map[objectPair]bool{})
+}
+
+func compare(a, b Object, seen map[objectPair]bool) int {
+	if a == b {
+		return 0
+	}
+	if a.Type() == BIGINT_OBJ || b.Type() == BIGINT_OBJ {
+		bigA, okA := ToBigInt(a)
+		bigB, okB := ToBigInt(b)
+		if okA && okB {
+			return bigA.Cmp(bigB)
+		}
+	}
+	if a.Type() != b.Type() {
+		return compareTypes(a.Type(), b.Type())
+	}
+	switch a := a.(type) {
+	case *Integer:
+		return cmp.Compare(a.Value, b.(*Integer).Value)
+	case *String:
+		return strings.Compare(a.Value, b.(*String).Value)
+	case *Boolean:
+		if a.Value == b.(*Boolean).Value {
+			return 0
+		}
+		if a.Value {
+			return 1
+		}
+		return -1
+	case *Pointer:
+		return cmp.Compare(a.Value, b.(*Pointer).Value)
+	case *Array:
+		if seen[objectPair{a, b}] {
+			return 0
+		}
+		seen[objectPair{a, b}] = true
+		return compareSlices(a.Elements, b.(*Array).Elements, seen)
+	case *Hash:
+		if seen[objectPair{a, b}] {
+			return 0
+		}
+		seen[objectPair{a, b}] = true
+		return compareSlices(sortedPairs(a), sortedPairs(b.(*Hash)), seen)
+	default:
+		return 0
+	}
+}
+
+func compareTypes(a, b ObjectType) int {
+	rankA, okA := typeOrder[a]
+	rankB, okB := typeOrder[b]
+	switch {
+	case okA && okB:
+		return cmp.Compare(rankA, rankB)
+	case okA:
+		return -1
+	case okB:
+		return 1
+	default:
+		return strings.Compare(string(a), string(b))
+	}
+}
+
+func compareSlices(a, b []Object, seen map[objectPair]bool) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compare(a[i], b[i], seen); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// sortedPairs flattens a hash into key, value, key, value... ordered by key.
+func sortedPairs(hash *Hash) []Object {
+	pairs := hash.Pairs()
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return Compare(pairs[i].Key, pairs[j].Key) < 0
+	})
+	flat := make([]Object, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		flat = append(flat, pair.Key, pair.Value)
+	}
+	return flat
+}
+
+// Freeze marks obj and every array and hash reachable from it as frozen.
+func Freeze(obj Object) {
+	switch obj := obj.(type) {
+	case *Array:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, el := range obj.Elements {
+			Freeze(el)
+		}
+	case *Hash:
+		if obj.Frozen {
+			return
+		}
+		obj.Frozen = true
+		for _, pair := range obj.entries {
+			Freeze(pair.Key)
+			Freeze(pair.Value)
+		}
+	}
+}
+
+// Module is an evaluated source file. Only the names it exports are visible
+// to importers; they are read live from its environment.
+type Module struct {
+	Path    string
+	Env     *Environment
+	Exports []string
+}
+
+func (mod *Module) Type() ObjectType { return MODULE_OBJ }
+func (mod *Module) Inspect() string {
+	return "module(" + mod.Path + ")"
+}
+
+// Get returns the value of an exported name.
+func (mod *Module) Get(name string) (Object, bool) {
+	if !slices.Contains(mod.Exports, name) {
+		return nil, false
+	}
+	return mod.Env.Get(name)
+}
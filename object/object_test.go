@@ -18,3 +18,182 @@ func TestStringHashKey(t *testing.T) {
 		t.Error("Same Hashkey on different-content-strings")
 	}
 }
+
+func TestHashKeepsInsertionOrder(t *testing.T) {
+	hash := NewHash()
+	for _, key := range []string{"c", "a", "b"} {
+		hash.Set(&String{Value: key}, &Integer{Value: 1})
+	}
+	hash.Set(&String{Value: "a"}, &Integer{Value: 2})
+	if hash.Inspect() != "{c: 1, a: 2, b: 1}" {
+		t.Errorf("Wrong Inspect output. got=%s", hash.Inspect())
+	}
+
+	hash.Delete(&String{Value: "c"})
+	hash.Set(&String{Value: "c"}, &Integer{Value: 3})
+	if hash.Inspect() != "{a: 2, b: 1, c: 3}" {
+		t.Errorf("Wrong Inspect output after delete. got=%s", hash.Inspect())
+	}
+}
+
+func TestEqual(t *testing.T) {
+	hash := func(pairs ...Object) *Hash {
+		h := NewHash()
+		for i := 0; i < len(pairs); i += 2 {
+			h.Set(pairs[i].(Hashable), pairs[i+1])
+		}
+		return h
+	}
+	array := func(elements ...Object) *Array {
+		return &Array{Elements: elements}
+	}
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+	a, b := &String{Value: "a"}, &String{Value: "b"}
+
+	tests := []struct {
+		left, right Object
+		expected    bool
+	}{
+		{one, &Integer{Value: 1}, true},
+		{one, two, false},
+		{one, a, false},
+		{a, &String{Value: "a"}, true},
+		{&NULL{}, &NULL{}, true},
+		{array(one, a), array(&Integer{Value: 1}, &String{Value: "a"}), true},
+		{array(one, a), array(one), false},
+		{array(array(one)), array(array(one)), true},
+		{hash(a, one, b, two), hash(b, two, a, one), true},
+		{hash(a, one), hash(a, two), false},
+		{hash(a, one), hash(b, one), false},
+		{hash(a, array(one)), hash(a, array(one)), true},
+		{&Pointer{Value: 3}, &Pointer{Value: 3}, true},
+	}
+
+	for _, tcase := range tests {
+		if Equal(tcase.left, tcase.right) != tcase.expected {
+			t.Errorf("Equal(%s, %s) expected %t", tcase.left.Inspect(), tcase.right.Inspect(), tcase.expected)
+		}
+	}
+}
+
+// Arrays and hashes that contain themselves must not send Equal and Compare
+// into endless recursion.
+func TestEqualCyclic(t *testing.T) {
+	cyclic := func(elements ...Object) *Array {
+		arr := &Array{Elements: append([]Object{nil}, elements...)}
+		arr.Elements[0] = arr
+		return arr
+	}
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+	a, b, c := cyclic(one), cyclic(one), cyclic(two)
+
+	if !Equal(a, a) || !Equal(a, b) || Equal(a, c) {
+		t.Errorf("wrong equality of cyclic arrays")
+	}
+	if Compare(a, a) != 0 || Compare(a, b) != 0 || Compare(a, c) != -1 {
+		t.Errorf("wrong order of cyclic arrays")
+	}
+
+	h := NewHash()
+	h.Set(&String{Value: "self"}, h)
+	if !Equal(h, h) || Compare(h, h) != 0 {
+		t.Errorf("a cyclic hash is not equal to itself")
+	}
+}
+
+// collidingKey always hashes to the same key, so only Equal can tell two
+// instances apart.
+type collidingKey struct{ name string }
+
+func (ck *collidingKey) Type() ObjectType { return "COLLIDING" }
+func (ck *collidingKey) Inspect() string  { return ck.name }
+func (ck *collidingKey) HashKey() HashKey { return HashKey{Type: ck.Type(), Value: 42} }
+
+func TestHashKeyCollisions(t *testing.T) {
+	first, second := &collidingKey{name: "first"}, &collidingKey{name: "second"}
+	hash := NewHash()
+	hash.Set(first, &Integer{Value: 1})
+	hash.Set(second, &Integer{Value: 2})
+
+	if hash.Len() != 2 {
+		t.Fatalf("Colliding keys overwrote each other. got %d pairs", hash.Len())
+	}
+	for key, expected := range map[*collidingKey]int64{first: 1, second: 2} {
+		pair, ok := hash.Get(key)
+		if !ok || pair.Value.(*Integer).Value != expected {
+			t.Errorf("Wrong value for colliding key %s. got=%v", key.name, pair.Value)
+		}
+	}
+
+	hash.Delete(first)
+	if _, ok := hash.Get(first); ok {
+		t.Errorf("Deleted key still present")
+	}
+	if _, ok := hash.Get(second); !ok {
+		t.Errorf("Deleting one colliding key removed the other")
+	}
+}
+
+func TestArrayHashKey(t *testing.T) {
+	tuple := func(elements ...Object) *Array { return &Array{Elements: elements} }
+	one, a := &Integer{Value: 1}, &String{Value: "a"}
+
+	if tuple(one, a).HashKey() != tuple(&Integer{Value: 1}, &String{Value: "a"}).HashKey() {
+		t.Errorf("Different Hashkey on same-content-arrays")
+	}
+	if tuple(one, a).HashKey() == tuple(a, one).HashKey() {
+		t.Errorf("Same Hashkey on differently ordered arrays")
+	}
+	if _, ok := AsHashable(tuple(one, tuple(a))); !ok {
+		t.Errorf("Array of hashable values is not hashable")
+	}
+	if _, ok := AsHashable(tuple(one, NewHash())); ok {
+		t.Errorf("Array containing a hash is hashable")
+	}
+	shared := tuple(one)
+	if _, ok := AsHashable(tuple(shared, shared)); !ok {
+		t.Errorf("Array holding the same array twice is not hashable")
+	}
+	cyclic := tuple(one)
+	cyclic.Elements[0] = tuple(cyclic)
+	if _, ok := AsHashable(cyclic); ok {
+		t.Errorf("Array containing itself is hashable")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	array := func(elements ...Object) *Array { return &Array{Elements: elements} }
+	hash := func(key Hashable, value Object) *Hash {
+		h := NewHash()
+		h.Set(key, value)
+		return h
+	}
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+	a, b := &String{Value: "a"}, &String{Value: "b"}
+
+	tests := []struct {
+		left, right Object
+		expected    int
+	}{
+		{one, two, -1},
+		{two, one, 1},
+		{one, &Integer{Value: 1}, 0},
+		{a, b, -1},
+		{&NULL{}, &Boolean{Value: false}, -1},
+		{&Boolean{Value: false}, &Boolean{Value: true}, -1},
+		{two, a, -1},
+		{a, array(), -1},
+		{array(one, two), array(one, two), 0},
+		{array(one), array(one, one), -1},
+		{array(two), array(one, two), 1},
+		{hash(a, one), hash(a, two), -1},
+		{hash(a, one), hash(b, one), -1},
+		{array(), hash(a, one), -1},
+	}
+
+	for _, tcase := range tests {
+		if got := Compare(tcase.left, tcase.right); got != tcase.expected {
+			t.Errorf("Compare(%s, %s) expected %d got %d", tcase.left.Inspect(), tcase.right.Inspect(), tcase.expected, got)
+		}
+	}
+}
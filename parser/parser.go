@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/Muto1907/interpreterInGo/ast"
@@ -27,16 +29,34 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.GT:       LESSGREATER,
-	token.LT:       LESSGREATER,
-	token.MINUS:    SUM,
-	token.PLUS:     SUM,
-	token.MULT:     PRODUCT,
-	token.DIV:      PRODUCT,
-	token.PARENL:   CALL,
-	token.BRACKETL: INDEX,
+	token.EQ:        EQUALS,
+	token.NOT_EQ:    EQUALS,
+	token.GT:        LESSGREATER,
+	token.LT:        LESSGREATER,
+	token.MINUS:     SUM,
+	token.PLUS:      SUM,
+	token.PIPE:      SUM,
+	token.CARET:     SUM,
+	token.MULT:      PRODUCT,
+	token.DIV:       PRODUCT,
+	token.MOD:       PRODUCT,
+	token.AMPERSAND: PRODUCT,
+	token.SHL:       PRODUCT,
+	token.SHR:       PRODUCT,
+	token.PARENL:    CALL,
+	token.BRACKETL:  INDEX,
+	token.DOT:       INDEX,
+}
+
+var assignOperators = map[token.TokenType]bool{
+	token.ASSIGN:       true,
+	token.PLUS_ASSIGN:  true,
+	token.MINUS_ASSIGN: true,
+	token.MULT_ASSIGN:  true,
+	token.DIV_ASSIGN:   true,
+	token.MOD_ASSIGN:   true,
+	token.INCREMENT:    true,
+	token.DECREMENT:    true,
 }
 
 type Parser struct {
@@ -44,6 +64,7 @@ type Parser struct {
 	currToken       token.Token
 	peekToken       token.Token
 	errors          []string
+	errorTokens     []token.Token
 	prefixParseFncs map[token.TokenType]prefixParseFnc
 	infixParseFncs  map[token.TokenType]infixParseFnc
 }
@@ -59,21 +80,25 @@ func New(lex *lexer.Lexer) *Parser {
 	parser.addPrefixFnc(token.INT, parser.parseIntegerLiteral)
 	parser.addPrefixFnc(token.MINUS, parser.parsePrefixExpression)
 	parser.addPrefixFnc(token.NOT, parser.parsePrefixExpression)
-	for _, tok := range []token.TokenType{token.PLUS, token.MINUS, token.DIV, token.MULT, token.EQ, token.NOT_EQ, token.LT, token.GT} {
+	for _, tok := range []token.TokenType{token.PLUS, token.MINUS, token.DIV, token.MULT, token.MOD, token.EQ, token.NOT_EQ, token.LT, token.GT,
+		token.AMPERSAND, token.PIPE, token.CARET, token.SHL, token.SHR} {
 		parser.addInfixFnc(tok, parser.parseInfixExpression)
 	}
 	parser.addPrefixFnc(token.TRUE, parser.parseBoolean)
 	parser.addPrefixFnc(token.FALSE, parser.parseBoolean)
 	parser.addPrefixFnc(token.STRING, parser.parseStringLiteral)
+	parser.addPrefixFnc(token.TEMPLATE, parser.parseInterpolatedString)
 	parser.addPrefixFnc(token.PARENL, parser.ParseGroupedExpr)
 	parser.addPrefixFnc(token.IF, parser.parseIfExpression)
 	parser.addPrefixFnc(token.FUNCTION, parser.parseFunctionLiteral)
 	parser.addPrefixFnc(token.BRACKETL, parser.parseArrayLiteral)
 	parser.addInfixFnc(token.PARENL, parser.parseCallExpression)
 	parser.addInfixFnc(token.BRACKETL, parser.parseIndexExpr)
+	parser.addInfixFnc(token.DOT, parser.parseMemberExpression)
 	parser.addPrefixFnc(token.BRACEL, parser.parseHashLiteral)
 	parser.addPrefixFnc(token.AMPERSAND, parser.parsePrefixExpression)
 	parser.addPrefixFnc(token.MULT, parser.parsePrefixExpression)
+	parser.addPrefixFnc(token.TILDE, parser.parsePrefixExpression)
 	parser.nextToken()
 	parser.nextToken()
 	return parser
@@ -83,6 +108,26 @@ func (parser *Parser) Errors() []string {
 	return parser.errors
 }
 
+// Error is a syntax error and the token at which it was detected.
+type Error struct {
+	Token   token.Token
+	Message string
+}
+
+// ErrorList returns the messages of Errors with their positions.
+func (parser *Parser) ErrorList() []Error {
+	list := []Error{}
+	for i, msg := range parser.errors {
+		list = append(list, Error{Token: parser.errorTokens[i], Message: msg})
+	}
+	return list
+}
+
+func (parser *Parser) errorAt(tok token.Token, msg string) {
+	parser.errors = append(parser.errors, msg)
+	parser.errorTokens = append(parser.errorTokens, tok)
+}
+
 func (parser *Parser) nextToken() {
 	parser.currToken = parser.peekToken
 	parser.peekToken = parser.l.NextToken()
@@ -123,12 +168,16 @@ func (parser *Parser) ParseProgram() *ast.Program {
 
 func (parser *Parser) parseStatement() ast.Statement {
 	switch parser.currToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return parser.parseLetStatement()
 	case token.RETURN:
 		return parser.parseReturnStatement()
 	case token.WHILE:
 		return parser.parseWhileStatement()
+	case token.IMPORT:
+		return parser.parseImportStatement()
+	case token.EXPORT:
+		return parser.parseExportStatement()
 	default:
 		return parser.parseExpressionOrAssignmentStatement()
 	}
@@ -140,15 +189,20 @@ func (parser *Parser) parseExpressionOrAssignmentStatement() ast.Statement {
 		return nil
 	}
 
-	if parser.peekTokenIs(token.ASSIGN) {
+	if assignOperators[parser.peekToken.Type] {
 		parser.nextToken()
 
 		assignStmt := &ast.ReassignmentStatement{
-			Token: parser.currToken,
-			Left:  leftExp,
+			Token:    parser.currToken,
+			Left:     leftExp,
+			Operator: parser.currToken.Literal,
+		}
+		if !parser.currentTokenIs(token.INCREMENT) && !parser.currentTokenIs(token.DECREMENT) {
+			parser.nextToken()
+			assignStmt.Value = parser.parseExpression(LOWEST)
+		} else if !assignable(leftExp) {
+			parser.errorAt(parser.currToken, fmt.Sprintf("%s needs a variable, index or dereference", parser.currToken.Literal))
 		}
-		parser.nextToken()
-		assignStmt.Value = parser.parseExpression(LOWEST)
 
 		if parser.peekTokenIs(token.SEMICOLON) {
 			parser.nextToken()
@@ -164,12 +218,125 @@ func (parser *Parser) parseExpressionOrAssignmentStatement() ast.Statement {
 	return stmt
 }
 
+// assignable reports whether exp is a variable, an index or a dereference,
+// which are what ++ and -- can change.
+func assignable(exp ast.Expression) bool {
+	switch exp := exp.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+		return true
+	case *ast.PrefixExpression:
+		return exp.Operator == "*"
+	}
+	return false
+}
+
+// parseImportStatement parses `import "path" as name;` and
+// `import { a, b as c } from "path";`. as and from are not keywords.
+func (parser *Parser) parseImportStatement() ast.Statement {
+	stmt := &ast.ImportStatement{Token: parser.currToken}
+	if parser.peekTokenIs(token.BRACEL) {
+		parser.nextToken()
+		stmt.Names = parser.parseImportNames()
+		if stmt.Names == nil || !parser.expectContextual("from") || !parser.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = &ast.StringLiteral{Token: parser.currToken, Value: parser.currToken.Literal}
+	} else {
+		if !parser.expectPeek(token.STRING) {
+			return nil
+		}
+		stmt.Path = &ast.StringLiteral{Token: parser.currToken, Value: parser.currToken.Literal}
+		if parser.peekTokenIs(token.IDENT) && parser.peekToken.Literal == "as" {
+			parser.nextToken()
+			if !parser.expectPeek(token.IDENT) {
+				return nil
+			}
+			stmt.Alias = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+		}
+	}
+	if parser.peekTokenIs(token.SEMICOLON) {
+		parser.nextToken()
+	}
+	return stmt
+}
+
+func (parser *Parser) parseImportNames() []ast.ImportName {
+	names := []ast.ImportName{}
+	for !parser.peekTokenIs(token.BRACER) {
+		if !parser.expectPeek(token.IDENT) {
+			return nil
+		}
+		name := ast.ImportName{Name: &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}}
+		if parser.peekTokenIs(token.IDENT) && parser.peekToken.Literal == "as" {
+			parser.nextToken()
+			if !parser.expectPeek(token.IDENT) {
+				return nil
+			}
+			name.Alias = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+		}
+		names = append(names, name)
+		if !parser.peekTokenIs(token.BRACER) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	parser.nextToken()
+	return names
+}
+
+func (parser *Parser) parseExportStatement() ast.Statement {
+	stmt := &ast.ExportStatement{Token: parser.currToken, Names: []*ast.Identifier{}}
+	if !parser.expectPeek(token.BRACEL) {
+		return nil
+	}
+	for !parser.peekTokenIs(token.BRACER) {
+		if !parser.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal})
+		if !parser.peekTokenIs(token.BRACER) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	parser.nextToken()
+	if parser.peekTokenIs(token.SEMICOLON) {
+		parser.nextToken()
+	}
+	return stmt
+}
+
+// expectContextual advances past an identifier used as a contextual
+// keyword such as from.
+func (parser *Parser) expectContextual(word string) bool {
+	if parser.peekTokenIs(token.IDENT) && parser.peekToken.Literal == word {
+		parser.nextToken()
+		return true
+	}
+	msg := fmt.Sprintf("expected next token to be %s, got %s instead", word, parser.peekToken.Type)
+	parser.errorAt(parser.peekToken, msg)
+	return false
+}
+
 func (parser *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: parser.currToken}
-	if !parser.expectPeek(token.IDENT) {
-		return nil
+	if parser.peekTokenIs(token.BRACKETL) || parser.peekTokenIs(token.BRACEL) {
+		parser.nextToken()
+		stmt.Pattern = parser.parsePattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !parser.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+	}
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+		parser.nextToken()
+		if stmt.Type = parser.parseType(); stmt.Type == nil {
+			return nil
+		}
 	}
-	stmt.Name = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
 	if !parser.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -228,7 +395,7 @@ func (parser *Parser) peekTokenIs(t token.TokenType) bool {
 }
 func (parser *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, parser.peekToken.Type)
-	parser.errors = append(parser.errors, msg)
+	parser.errorAt(parser.peekToken, msg)
 }
 func (parser *Parser) expectPeek(t token.TokenType) bool {
 	if parser.peekTokenIs(t) {
@@ -265,13 +432,19 @@ func (parser *Parser) parseIntegerLiteral() ast.Expression {
 	inte := &ast.IntegerLiteral{Token: parser.currToken}
 
 	val, err := strconv.ParseInt(parser.currToken.Literal, 0, 64)
-	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as Integer", parser.currToken.Literal)
-		parser.errors = append(parser.errors, msg)
-		return nil
+	if err == nil {
+		inte.Value = val
+		return inte
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		if value, ok := new(big.Int).SetString(parser.currToken.Literal, 0); ok {
+			inte.Big = value
+			return inte
+		}
 	}
-	inte.Value = val
-	return inte
+	msg := fmt.Sprintf("could not parse %q as Integer", parser.currToken.Literal)
+	parser.errorAt(parser.currToken, msg)
+	return nil
 }
 
 func (parser *Parser) parseStringLiteral() ast.Expression {
@@ -279,12 +452,60 @@ func (parser *Parser) parseStringLiteral() ast.Expression {
 	return str
 }
 
+func (parser *Parser) parseInterpolatedString() ast.Expression {
+	interp := &ast.InterpolatedString{Token: parser.currToken}
+	for _, part := range lexer.SplitTemplate(parser.currToken.Literal) {
+		line, column := templatePosition(interp.Token, part.Offset)
+		if !part.IsExpr {
+			tok := token.Token{Type: token.TEMPLATE, Literal: part.Text, Line: line, Column: column}
+			interp.Parts = append(interp.Parts, &ast.StringLiteral{Token: tok, Value: part.Text})
+			continue
+		}
+		sub := New(lexer.NewAt(part.Text, line, column))
+		expr := sub.parseExpression(LOWEST)
+		if !sub.peekTokenIs(token.EOF) {
+			sub.peekError(token.EOF)
+		}
+		for _, err := range sub.ErrorList() {
+			parser.errorAt(err.Token, fmt.Sprintf("in interpolation ${%s}: %s", part.Text, err.Message))
+		}
+		if len(sub.Errors()) > 0 {
+			return nil
+		}
+		interp.Parts = append(interp.Parts, expr)
+	}
+	if len(interp.Parts) == 1 && ast.IsText(interp.Parts[0]) {
+		// Only escaped ${ made the string a template.
+		text := interp.Parts[0].(*ast.StringLiteral)
+		tok := token.Token{Type: token.STRING, Literal: text.Value, Line: interp.Token.Line, Column: interp.Token.Column}
+		return &ast.StringLiteral{Token: tok, Value: text.Value}
+	}
+	return interp
+}
+
+// templatePosition returns the line and column of the byte at offset in
+// the literal of tok, a TEMPLATE token positioned at its opening quote.
+func templatePosition(tok token.Token, offset int) (line, column int) {
+	line, column = tok.Line, tok.Column+1
+	for _, char := range tok.Literal[:offset] {
+		if char == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
 func (parser *Parser) parseArrayLiteral() ast.Expression {
 	arr := &ast.ArrayLiteral{Token: parser.currToken}
 	arr.Elements = parser.parseExpressionList(token.BRACKETR)
 	return arr
 }
 
+// parseExpressionList parses expressions separated by commas up to tok. A
+// comma may follow the last one, so lists can be written one item a line.
 func (parser *Parser) parseExpressionList(tok token.TokenType) []ast.Expression {
 	res := []ast.Expression{}
 	if parser.peekTokenIs(tok) {
@@ -295,6 +516,9 @@ func (parser *Parser) parseExpressionList(tok token.TokenType) []ast.Expression
 	res = append(res, parser.parseExpression(LOWEST))
 	for parser.peekTokenIs(token.COMMA) {
 		parser.nextToken()
+		if parser.peekTokenIs(tok) {
+			break
+		}
 		parser.nextToken()
 		res = append(res, parser.parseExpression(LOWEST))
 	}
@@ -304,19 +528,51 @@ func (parser *Parser) parseExpressionList(tok token.TokenType) []ast.Expression
 	return res
 }
 
+func (parser *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	expr := &ast.MemberExpression{Token: parser.currToken, Object: left}
+	if !parser.expectPeek(token.IDENT) {
+		return nil
+	}
+	expr.Property = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+	return expr
+}
+
 func (parser *Parser) parseIndexExpr(left ast.Expression) ast.Expression {
-	indexExpr := &ast.IndexExpression{Token: parser.currToken, Left: left}
+	tok := parser.currToken
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+		return parser.parseSliceExpr(tok, left, nil)
+	}
+	parser.nextToken()
+	index := parser.parseExpression(LOWEST)
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+		return parser.parseSliceExpr(tok, left, index)
+	}
+	if !parser.expectPeek(token.BRACKETR) {
+		return nil
+	}
+	return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+}
+
+// parseSliceExpr expects the current token to be the colon of left[start:end].
+func (parser *Parser) parseSliceExpr(tok token.Token, left, start ast.Expression) ast.Expression {
+	slice := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	if parser.peekTokenIs(token.BRACKETR) {
+		parser.nextToken()
+		return slice
+	}
 	parser.nextToken()
-	indexExpr.Index = parser.parseExpression(LOWEST)
+	slice.End = parser.parseExpression(LOWEST)
 	if !parser.expectPeek(token.BRACKETR) {
 		return nil
 	}
-	return indexExpr
+	return slice
 }
 
 func (parser *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: parser.currToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+	hash.Pairs = []ast.HashPair{}
 
 	for !parser.peekTokenIs(token.BRACER) {
 		parser.nextToken()
@@ -328,7 +584,7 @@ func (parser *Parser) parseHashLiteral() ast.Expression {
 
 		parser.nextToken()
 		val := parser.parseExpression(LOWEST)
-		hash.Pairs[key] = val
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: val})
 
 		if !parser.peekTokenIs(token.BRACER) && !parser.expectPeek(token.COMMA) {
 			return nil
@@ -393,7 +649,17 @@ func (parser *Parser) parseFunctionLiteral() ast.Expression {
 	if !parser.expectPeek(token.PARENL) {
 		return nil
 	}
-	fnc.Parameters = parser.parseFunctionParameters()
+	fnc.Parameters, fnc.ParamTypes = parser.parseFunctionParameters()
+	if fnc.Parameters == nil {
+		return nil
+	}
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+		parser.nextToken()
+		if fnc.ReturnType = parser.parseType(); fnc.ReturnType == nil {
+			return nil
+		}
+	}
 	if !parser.expectPeek(token.BRACEL) {
 		return nil
 	}
@@ -414,28 +680,260 @@ func (parser *Parser) parseBlockStatement() *ast.BlockStatement {
 		}
 		parser.nextToken()
 	}
+	blck.Close = parser.currToken
 	return blck
 }
 
-func (parser *Parser) parseFunctionParameters() []*ast.Identifier {
-	parameters := []*ast.Identifier{}
+// parseFunctionParameters parses the parameters and their annotations. The
+// annotations are nil if there are none.
+func (parser *Parser) parseFunctionParameters() ([]ast.Pattern, []ast.Type) {
+	parameters := []ast.Pattern{}
+	types := []ast.Type{}
+	annotated := false
 	if parser.peekTokenIs(token.PARENR) {
 		parser.nextToken()
-		return parameters
+		return parameters, nil
 	}
-	parser.nextToken()
-	identifier := &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
-	parameters = append(parameters, identifier)
-	for parser.peekTokenIs(token.COMMA) {
+	for {
 		parser.nextToken()
+		param := parser.parsePattern()
+		if param == nil {
+			return nil, nil
+		}
+		var typ ast.Type
+		if parser.peekTokenIs(token.COLON) {
+			parser.nextToken()
+			parser.nextToken()
+			if typ = parser.parseType(); typ == nil {
+				return nil, nil
+			}
+			annotated = true
+		}
+		parameters = append(parameters, param)
+		types = append(types, typ)
+		if !parser.peekTokenIs(token.COMMA) {
+			break
+		}
 		parser.nextToken()
-		identifier = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
-		parameters = append(parameters, identifier)
 	}
 	if !parser.expectPeek(token.PARENR) {
+		return nil, nil
+	}
+	if !annotated {
+		types = nil
+	}
+	return parameters, types
+}
+
+// parseType parses a type annotation starting at the current token.
+func (parser *Parser) parseType() ast.Type {
+	switch parser.currToken.Type {
+	case token.IDENT:
+		return &ast.NamedType{Token: parser.currToken, Name: parser.currToken.Literal}
+	case token.BRACKETL:
+		typ := &ast.ArrayType{Token: parser.currToken}
+		parser.nextToken()
+		if typ.Element = parser.parseType(); typ.Element == nil || !parser.expectPeek(token.BRACKETR) {
+			return nil
+		}
+		return typ
+	case token.BRACEL:
+		if parser.peekTokenIs(token.BRACKETL) {
+			return parser.parseHashType()
+		}
+		return parser.parseRecordType()
+	case token.FUNCTION:
+		return parser.parseFuncType()
+	default:
+		msg := fmt.Sprintf("expected type, got %s instead", parser.currToken.Type)
+		parser.errorAt(parser.currToken, msg)
 		return nil
 	}
-	return parameters
+}
+
+func (parser *Parser) parseHashType() ast.Type {
+	typ := &ast.HashType{Token: parser.currToken}
+	parser.nextToken()
+	parser.nextToken()
+	if typ.Key = parser.parseType(); typ.Key == nil {
+		return nil
+	}
+	if !parser.expectPeek(token.BRACKETR) || !parser.expectPeek(token.COLON) {
+		return nil
+	}
+	parser.nextToken()
+	if typ.Value = parser.parseType(); typ.Value == nil || !parser.expectPeek(token.BRACER) {
+		return nil
+	}
+	return typ
+}
+
+func (parser *Parser) parseRecordType() ast.Type {
+	typ := &ast.RecordType{Token: parser.currToken, Fields: []ast.RecordField{}}
+	for !parser.peekTokenIs(token.BRACER) {
+		parser.nextToken()
+		var key ast.Expression
+		switch parser.currToken.Type {
+		case token.IDENT:
+			key = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+		case token.STRING:
+			key = &ast.StringLiteral{Token: parser.currToken, Value: parser.currToken.Literal}
+		default:
+			msg := fmt.Sprintf("expected record field name, got %s instead", parser.currToken.Type)
+			parser.errorAt(parser.currToken, msg)
+			return nil
+		}
+		if !parser.expectPeek(token.COLON) {
+			return nil
+		}
+		parser.nextToken()
+		field := ast.RecordField{Key: key, Type: parser.parseType()}
+		if field.Type == nil {
+			return nil
+		}
+		typ.Fields = append(typ.Fields, field)
+		if !parser.peekTokenIs(token.BRACER) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	parser.nextToken()
+	return typ
+}
+
+func (parser *Parser) parseFuncType() ast.Type {
+	typ := &ast.FuncType{Token: parser.currToken, Parameters: []ast.Type{}}
+	if !parser.expectPeek(token.PARENL) {
+		return nil
+	}
+	for !parser.peekTokenIs(token.PARENR) {
+		parser.nextToken()
+		param := parser.parseType()
+		if param == nil {
+			return nil
+		}
+		typ.Parameters = append(typ.Parameters, param)
+		if !parser.peekTokenIs(token.PARENR) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	parser.nextToken()
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+		parser.nextToken()
+		if typ.Return = parser.parseType(); typ.Return == nil {
+			return nil
+		}
+	}
+	return typ
+}
+
+// parsePattern parses a binding target starting at the current token.
+func (parser *Parser) parsePattern() ast.Pattern {
+	switch parser.currToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+	case token.BRACKETL:
+		return parser.parseArrayPattern()
+	case token.BRACEL:
+		return parser.parseHashPattern()
+	default:
+		msg := fmt.Sprintf("expected binding pattern, got %s instead", parser.currToken.Type)
+		parser.errorAt(parser.currToken, msg)
+		return nil
+	}
+}
+
+func (parser *Parser) parseArrayPattern() ast.Pattern {
+	pattern := &ast.ArrayPattern{Token: parser.currToken, Elements: []ast.Pattern{}}
+	for !parser.peekTokenIs(token.BRACKETR) {
+		parser.nextToken()
+		if parser.currentTokenIs(token.ELLIPSIS) {
+			pattern.Rest = parser.parseRestPattern(token.BRACKETR)
+			if pattern.Rest == nil {
+				return nil
+			}
+			break
+		}
+		element := parser.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+		if !parser.peekTokenIs(token.BRACKETR) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !parser.expectPeek(token.BRACKETR) {
+		return nil
+	}
+	return pattern
+}
+
+func (parser *Parser) parseHashPattern() ast.Pattern {
+	pattern := &ast.HashPattern{Token: parser.currToken, Pairs: []ast.HashPatternPair{}}
+	for !parser.peekTokenIs(token.BRACER) {
+		parser.nextToken()
+		if parser.currentTokenIs(token.ELLIPSIS) {
+			pattern.Rest = parser.parseRestPattern(token.BRACER)
+			if pattern.Rest == nil {
+				return nil
+			}
+			break
+		}
+
+		var key ast.Expression
+		switch parser.currToken.Type {
+		case token.IDENT:
+			key = &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+		case token.STRING:
+			key = &ast.StringLiteral{Token: parser.currToken, Value: parser.currToken.Literal}
+		default:
+			msg := fmt.Sprintf("expected hash pattern key, got %s instead", parser.currToken.Type)
+			parser.errorAt(parser.currToken, msg)
+			return nil
+		}
+
+		pair := ast.HashPatternPair{Key: key}
+		if parser.peekTokenIs(token.COLON) {
+			parser.nextToken()
+			parser.nextToken()
+			pair.Value = parser.parsePattern()
+			if pair.Value == nil {
+				return nil
+			}
+		} else if ident, ok := key.(*ast.Identifier); ok {
+			pair.Value = ident
+		} else {
+			parser.peekError(token.COLON)
+			return nil
+		}
+		pattern.Pairs = append(pattern.Pairs, pair)
+
+		if !parser.peekTokenIs(token.BRACER) && !parser.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	if !parser.expectPeek(token.BRACER) {
+		return nil
+	}
+	return pattern
+}
+
+// parseRestPattern parses the identifier after ... which must be the last
+// entry before closing.
+func (parser *Parser) parseRestPattern(closing token.TokenType) *ast.Identifier {
+	if !parser.expectPeek(token.IDENT) {
+		return nil
+	}
+	rest := &ast.Identifier{Token: parser.currToken, Value: parser.currToken.Literal}
+	if parser.peekTokenIs(token.COMMA) {
+		parser.nextToken()
+	}
+	if !parser.peekTokenIs(closing) {
+		parser.peekError(closing)
+		return nil
+	}
+	return rest
 }
 
 func (parser *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -458,7 +956,7 @@ func (parser *Parser) parsePrefixExpression() ast.Expression {
 
 func (parser *Parser) noPrefixParseFuncFoundError(ttype token.TokenType) {
 	msg := fmt.Sprintf("No Prefix Parse Function found for %s", ttype)
-	parser.errors = append(parser.errors, msg)
+	parser.errorAt(parser.currToken, msg)
 }
 
 func (parser *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
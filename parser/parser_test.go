@@ -56,6 +56,132 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestConstStatements(t *testing.T) {
+	input := `const limit = 10; let x = 1; const [a, b] = pair;`
+
+	parser := New(lexer.New(input))
+	program := parser.ParseProgram()
+	checkParserErrors(t, parser)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("Program does not contain 3 Statements. got = %d", len(program.Statements))
+	}
+	expected := []struct {
+		isConst bool
+		str     string
+	}{
+		{true, "const limit = 10;"},
+		{false, "let x = 1;"},
+		{true, "const [a, b] = pair;"},
+	}
+	for i, tt := range expected {
+		stmt, ok := program.Statements[i].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("Statement %d is not LetStatement. got=%T", i, program.Statements[i])
+		}
+		if stmt.IsConst() != tt.isConst {
+			t.Errorf("Statement %d IsConst wrong. expected=%t", i, tt.isConst)
+		}
+		if stmt.String() != tt.str {
+			t.Errorf("Statement %d wrong. expected=%q got=%q", i, tt.str, stmt.String())
+		}
+	}
+}
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = arr;", "[a, b]"},
+		{"let [first, ...rest] = arr;", "[first, ...rest]"},
+		{"let [] = arr;", "[]"},
+		{"let [[a, b], {c}] = arr;", "[[a, b], {c}]"},
+		{"let {name, age: years} = person;", "{name, age: years}"},
+		{`let {"full name": full, ...others,} = person;`, `{"full name": full, ...others}`},
+		{"let {pos: [x, y]} = p;", "{pos: [x, y]}"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("Program does not contain 1 Statement. got = %d", len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("Statement is not LetStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Name != nil || stmt.Pattern == nil {
+			t.Fatalf("expected a pattern binding. got Name=%v Pattern=%v", stmt.Name, stmt.Pattern)
+		}
+		if stmt.Pattern.String() != tt.expected {
+			t.Errorf("wrong pattern. expected=%q got=%q", tt.expected, stmt.Pattern.String())
+		}
+	}
+}
+
+func TestDestructuringPatternErrors(t *testing.T) {
+	tests := []string{
+		"let [a, 1] = arr;",
+		"let [...rest, a] = arr;",
+		"let {a: } = h;",
+		`let {"k"} = h;`,
+		"let {...} = h;",
+		"fnc(a, [b, 2]) { a }",
+	}
+
+	for _, input := range tests {
+		parser := New(lexer.New(input))
+		parser.ParseProgram()
+		if len(parser.Errors()) == 0 {
+			t.Errorf("expected parser errors for %s", input)
+		}
+	}
+}
+
+func TestImportExportStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib/math.chimp" as m;`, `import "lib/math.chimp" as m;`},
+		{`import "setup"`, `import "setup";`},
+		{`import { a, b as c } from "lib";`, `import {a, b as c} from "lib";`},
+		{`import {} from "lib";`, `import {} from "lib";`},
+		{`export { a, b, };`, `export {a, b};`},
+		{`let as = 1; let from = 2;`, "let as = 1;\nlet from = 2;"},
+		{`m.f(x).g`, `((m.f)(x).g);`},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+		if program.String() != tt.expected {
+			t.Errorf("wrong program. expected=%q got=%q", tt.expected, program.String())
+		}
+	}
+
+	errors := []string{
+		`import lib as m;`,
+		`import "lib" as;`,
+		`import { a } "lib";`,
+		`import { a b } from "lib";`,
+		`export a;`,
+		`m.1`,
+	}
+	for _, input := range errors {
+		parser := New(lexer.New(input))
+		parser.ParseProgram()
+		if len(parser.Errors()) == 0 {
+			t.Errorf("expected parser errors for %s", input)
+		}
+	}
+}
+
 func TestReassignmentStatements(t *testing.T) {
 
 	input := `let x = 5; x = 10;`
@@ -79,11 +205,58 @@ func TestReassignmentStatements(t *testing.T) {
 	if assName.Value != "x" {
 		t.Fatalf("Assignment Name not 'x'. got = %s", assName.Value)
 	}
+	if assStmt.Operator != "=" {
+		t.Fatalf("Assignment Operator not '='. got = %s", assStmt.Operator)
+	}
 	if !testLiteralExpr(t, assStmt.Value, 10) {
 		return
 	}
 }
 
+func TestCompoundAssignmentStatements(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedLeft     string
+		expectedOperator string
+		expectedValue    string
+	}{
+		{"x += 1;", "x", "+=", "1"},
+		{"x -= y * 2;", "x", "-=", "(y * 2)"},
+		{"arr[i] *= 3", "(arr[i])", "*=", "3"},
+		{"*p /= 2;", "(*p)", "/=", "2"},
+		{"h[\"k\"] %= 7;", `(h["k"])`, "%=", "7"},
+		{"i++;", "i", "++", ""},
+		{"arr[0]--", "(arr[0])", "--", ""},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("Program does not contain 1 Statement. got = %d", len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ReassignmentStatement)
+		if !ok {
+			t.Fatalf("Statement is not ReassignmentStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Left.String() != tt.expectedLeft {
+			t.Errorf("wrong target. expected=%q got=%q", tt.expectedLeft, stmt.Left.String())
+		}
+		if stmt.Operator != tt.expectedOperator {
+			t.Errorf("wrong operator. expected=%q got=%q", tt.expectedOperator, stmt.Operator)
+		}
+		value := ""
+		if stmt.Value != nil {
+			value = stmt.Value.String()
+		}
+		if value != tt.expectedValue {
+			t.Errorf("wrong value. expected=%q got=%q", tt.expectedValue, value)
+		}
+	}
+}
+
 func TestAssignmentStatementsWithPrefixExpression(t *testing.T) {
 	tests := []struct {
 		input        string
@@ -209,6 +382,64 @@ func TestIntegerExpr(t *testing.T) {
 
 }
 
+func TestPrefixedIntegerExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_FF_FF", 65535},
+	}
+
+	for _, tcase := range tests {
+		lex := lexer.New(tcase.input)
+		pars := New(lex)
+		program := pars.ParseProgram()
+		checkParserErrors(t, pars)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		inte, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("Statement Expression is not of Type Integer. got=%T", stmt.Expression)
+		}
+		if inte.Value != tcase.expected {
+			t.Errorf("Integer Value of %s is not %d. got=%d", tcase.input, tcase.expected, inte.Value)
+		}
+	}
+
+	for _, input := range []string{"0x", "1__0", "0b102", "0xG"} {
+		pars := New(lexer.New(input))
+		pars.ParseProgram()
+		if len(pars.Errors()) == 0 {
+			t.Errorf("Expected parser error for %q", input)
+		}
+	}
+}
+
+func TestBigIntegerExpr(t *testing.T) {
+	input := "123456789012345678901234567890"
+	lex := lexer.New(input)
+	pars := New(lex)
+	program := pars.ParseProgram()
+	checkParserErrors(t, pars)
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statement is not ExpressionStatement. got=%T", program.Statements[0])
+	}
+	inte, ok := stmt.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("Statement Expression is not of Type Integer. got=%T", stmt.Expression)
+	}
+	if inte.Big == nil || inte.Big.String() != input {
+		t.Fatalf("Integer Big Value is not %s. got=%v", input, inte.Big)
+	}
+	if inte.String() != input {
+		t.Fatalf("Integer String is not %s. got=%s", input, inte.String())
+	}
+}
+
 func TestStringExpr(t *testing.T) {
 	input := `"whats up";`
 
@@ -232,6 +463,52 @@ func TestStringExpr(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStringExpr(t *testing.T) {
+	input := `"total: ${a + b}!";`
+
+	lex := lexer.New(input)
+	parser := New(lex)
+	program := parser.ParseProgram()
+	checkParserErrors(t, parser)
+	if len(program.Statements) != 1 {
+		t.Fatalf("Number of ProgramStatements != 1. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statement is not Expressionstatement. got=%T(%v)", program.Statements[0], program.Statements[0])
+	}
+	interp, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("Expression isn't InterpolatedString. got=%T(%v)", stmt.Expression, stmt.Expression)
+	}
+	if len(interp.Parts) != 3 {
+		t.Fatalf("Wrong number of parts. expected=3 got=%d", len(interp.Parts))
+	}
+	if str, ok := interp.Parts[0].(*ast.StringLiteral); !ok || str.Value != "total: " {
+		t.Errorf("Wrong first part. got=%T(%v)", interp.Parts[0], interp.Parts[0])
+	}
+	testInfixExpr(t, interp.Parts[1], "a", "+", "b")
+	if str, ok := interp.Parts[2].(*ast.StringLiteral); !ok || str.Value != "!" {
+		t.Errorf("Wrong last part. got=%T(%v)", interp.Parts[2], interp.Parts[2])
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []string{
+		`"${}"`,
+		`"${a b}"`,
+		`"${a +}"`,
+	}
+
+	for _, input := range tests {
+		parser := New(lexer.New(input))
+		parser.ParseProgram()
+		if len(parser.Errors()) == 0 {
+			t.Errorf("expected parser errors for %s", input)
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	Tests := []struct {
 		inp          string
@@ -464,6 +741,143 @@ func TestParsingFuncParameters(t *testing.T) {
 	}
 }
 
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input      string
+		letType    string
+		paramTypes []string
+		returnType string
+	}{
+		{"let x: int = 1;", "int", nil, ""},
+		{"const [a, b]: [string] = s;", "[string]", nil, ""},
+		{"let h: {[string]: [int]} = {};", "{[string]: [int]}", nil, ""},
+		{`let p: {name: string, "zip code": int} = {};`, `{name: string, "zip code": int}`, nil, ""},
+		{"let f: fnc(int, bool): string = g;", "fnc(int, bool): string", nil, ""},
+		{"let f: fnc() = g;", "fnc()", nil, ""},
+		{"let f = fnc(x: int, y: string): bool { true };", "", []string{"int", "string"}, "bool"},
+		{"let f = fnc(x, {y}: {y: any}) { y };", "", []string{"", "{y: any}"}, ""},
+		{"let f = fnc(x, y): fnc(int): int { x };", "", nil, "fnc(int): int"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		stmt := program.Statements[0].(*ast.LetStatement)
+		if got := typeString(stmt.Type); got != tt.letType {
+			t.Errorf("%s: wrong let type. expected=%q got=%q", tt.input, tt.letType, got)
+		}
+		fnc, ok := stmt.Value.(*ast.FuncLiteral)
+		if !ok {
+			continue
+		}
+		if tt.paramTypes == nil && fnc.ParamTypes != nil {
+			t.Errorf("%s: expected no parameter types, got %v", tt.input, fnc.ParamTypes)
+		}
+		if tt.paramTypes != nil && len(fnc.ParamTypes) != len(fnc.Parameters) {
+			t.Fatalf("%s: expected %d parameter types, got %d", tt.input, len(fnc.Parameters), len(fnc.ParamTypes))
+		}
+		for i, expected := range tt.paramTypes {
+			if got := typeString(fnc.ParamTypes[i]); got != expected {
+				t.Errorf("%s: wrong type of parameter %d. expected=%q got=%q", tt.input, i, expected, got)
+			}
+		}
+		if got := typeString(fnc.ReturnType); got != tt.returnType {
+			t.Errorf("%s: wrong return type. expected=%q got=%q", tt.input, tt.returnType, got)
+		}
+	}
+}
+
+func typeString(typ ast.Type) string {
+	if typ == nil {
+		return ""
+	}
+	return typ.String()
+}
+
+func TestTypeAnnotationErrors(t *testing.T) {
+	tests := []string{
+		"let x: = 1;",
+		"let x: [int = 1;",
+		"let x: {[int]} = 1;",
+		"let x: {1: int} = 1;",
+		"let x: fnc(int = 1;",
+		"fnc(x:) { x }",
+		"fnc(x): { x }",
+	}
+
+	for _, input := range tests {
+		parser := New(lexer.New(input))
+		parser.ParseProgram()
+		if len(parser.Errors()) == 0 {
+			t.Errorf("expected parser errors for %s", input)
+		}
+	}
+}
+
+func TestErrorList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x 5;", "1:7: expected next token to be =, got INT instead"},
+		{"let x = 1;\nlet = 2;", "2:5: expected next token to be IDENT, got = instead"},
+		{"let x = )", "1:9: No Prefix Parse Function found for )"},
+		{`puts("a ${1 +} b")`, "1:14: in interpolation ${1 +}: No Prefix Parse Function found for EOF"},
+		{"puts(\"a\n  ${)}\")", "2:5: in interpolation ${)}: No Prefix Parse Function found for )"},
+		{"5--;", "1:2: -- needs a variable, index or dereference"},
+		{"f()++", "1:4: ++ needs a variable, index or dereference"},
+		{"0!0#++", "1:4: No Prefix Parse Function found for ILLEGAL"},
+		{"let y = [x--, x];", "1:13: No Prefix Parse Function found for ,"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		parser.ParseProgram()
+		list := parser.ErrorList()
+		if len(list) == 0 {
+			t.Errorf("expected parser errors for %q", tt.input)
+			continue
+		}
+		got := fmt.Sprintf("%d:%d: %s", list[0].Token.Line, list[0].Token.Column, list[0].Message)
+		if got != tt.expected {
+			t.Errorf("wrong first error for %q. expected=%q got=%q", tt.input, tt.expected, got)
+		}
+		if len(list) != len(parser.Errors()) {
+			t.Errorf("ErrorList has %d errors, Errors has %d", len(list), len(parser.Errors()))
+		}
+	}
+}
+
+func TestInterpolationPositions(t *testing.T) {
+	input := "let s = \"ab ${foo + 1}\n  ${ bar }!\";"
+	program := New(lexer.New(input)).ParseProgram()
+	interp, ok := program.Statements[0].(*ast.LetStatement).Value.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("value is not an *ast.InterpolatedString")
+	}
+
+	tests := []struct {
+		node           ast.Node
+		expectedLine   int
+		expectedColumn int
+	}{
+		{interp.Parts[0], 1, 10},
+		{interp.Parts[1].(*ast.InfixExpression).Left, 1, 15},
+		{interp.Parts[1].(*ast.InfixExpression).Right, 1, 21},
+		{interp.Parts[2], 1, 23},
+		{interp.Parts[3], 2, 6},
+		{interp.Parts[4], 2, 11},
+	}
+	for i, tt := range tests {
+		tok := ast.Start(tt.node)
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - %s at %d:%d, expected %d:%d", i, tt.node, tok.Line, tok.Column, tt.expectedLine, tt.expectedColumn)
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 1 + 2, 2 * 3)"
 	lex := lexer.New(input)
@@ -518,6 +932,11 @@ func TestCallExpressionParameterParsing(t *testing.T) {
 			expectedIdentifier: "print",
 			expectedArguments:  []string{},
 		},
+		{
+			input:              "add(1, 2 * 3,);",
+			expectedIdentifier: "add",
+			expectedArguments:  []string{"1", "(2 * 3)"},
+		},
 	}
 	for _, tcase := range Test {
 		lex := lexer.New(tcase.input)
@@ -681,6 +1100,34 @@ func TestEmptyArrayLiteralParsing(t *testing.T) {
 
 }
 
+func TestTrailingCommas(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2,]", "[1, 2]"},
+		{"[\n\t1,\n\t2,\n]", "[1, 2]"},
+		{"f(a,\n)", "f(a)"},
+		{`{"a": 1,}`, `{"a": 1}`},
+	}
+	for _, tt := range tests {
+		parser := New(lexer.New(tt.input))
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+		if got := program.Statements[0].(*ast.ExpressionStatement).Expression.String(); got != tt.expected {
+			t.Errorf("wrong expression for %q. expected=%q got=%q", tt.input, tt.expected, got)
+		}
+	}
+
+	for _, input := range []string{"[,]", "f(,)", "[1,,]", "f(1,,2)"} {
+		parser := New(lexer.New(input))
+		parser.ParseProgram()
+		if len(parser.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func TestIndexExpressionParsing(t *testing.T) {
 	input := `arr [8 + 2]`
 	lex := lexer.New(input)
@@ -701,6 +1148,36 @@ func TestIndexExpressionParsing(t *testing.T) {
 
 }
 
+func TestSliceExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`str[1:3]`, "(str[1:3])"},
+		{`str[:3]`, "(str[:3])"},
+		{`str[1 + 1:]`, "(str[(1 + 1):])"},
+		{`str[:]`, "(str[:])"},
+	}
+
+	for _, tcase := range tests {
+		lex := lexer.New(tcase.input)
+		parser := New(lex)
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+		stmt, _ := program.Statements[0].(*ast.ExpressionStatement)
+		slice, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("Wrong Expressiontype expected SliceExpression got %T", stmt.Expression)
+		}
+		if !testIdentifier(t, slice.Left, "str") {
+			return
+		}
+		if slice.String() != tcase.expected {
+			t.Errorf("expected=%q, received=%q", tcase.expected, slice.String())
+		}
+	}
+}
+
 func TestEmptyHashLiteralParsing(t *testing.T) {
 	input := "{}"
 
@@ -742,18 +1219,34 @@ func TestHashLiteralParsingStringKeys(t *testing.T) {
 		t.Errorf("Incorrect length of Hash.pairs expected %d got=%d", len(expected), len(hsh.Pairs))
 	}
 
-	for k, v := range hsh.Pairs {
+	for _, pair := range hsh.Pairs {
+		k, v := pair.Key, pair.Value
 		key, ok := k.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("Wrong key Type. Expected string got=%T", k)
 			continue
 		}
 
-		expectedVal := expected[key.String()]
+		expectedVal := expected[key.Value]
 		testIntegerLiteral(t, v, expectedVal)
 	}
 }
 
+func TestHashLiteralKeepsSourceOrder(t *testing.T) {
+	input := `{"c": 1, "a": 2, "b": 3, 4: 4}`
+
+	lex := lexer.New(input)
+	parser := New(lex)
+	program := parser.ParseProgram()
+	checkParserErrors(t, parser)
+
+	for i := 0; i < 10; i++ {
+		if program.String() != `{"c": 1, "a": 2, "b": 3, 4: 4};` {
+			t.Fatalf("Hash literal pairs out of source order. got=%s", program.String())
+		}
+	}
+}
+
 func TestHashLiteralParsingBooleanKeys(t *testing.T) {
 	input := `{true: 1, false: 0}`
 
@@ -777,7 +1270,8 @@ func TestHashLiteralParsingBooleanKeys(t *testing.T) {
 		t.Errorf("Incorrect length of Hash.pairs expected %d got=%d", len(expected), len(hsh.Pairs))
 	}
 
-	for k, v := range hsh.Pairs {
+	for _, pair := range hsh.Pairs {
+		k, v := pair.Key, pair.Value
 		key, ok := k.(*ast.Boolean)
 		if !ok {
 			t.Errorf("Wrong key Type. Expected Boolean got=%T", k)
@@ -813,7 +1307,8 @@ func TestHashLiteralParsingIntegerKeys(t *testing.T) {
 		t.Errorf("Incorrect length of Hash.pairs expected %d got=%d", len(expected), len(hsh.Pairs))
 	}
 
-	for k, v := range hsh.Pairs {
+	for _, pair := range hsh.Pairs {
+		k, v := pair.Key, pair.Value
 		key, ok := k.(*ast.IntegerLiteral)
 		if !ok {
 			t.Errorf("Wrong key Type. Expected Integer got=%T", k)
@@ -855,16 +1350,17 @@ func TestHashLiteralParsingExpressionValues(t *testing.T) {
 		},
 	}
 
-	for k, v := range hsh.Pairs {
+	for _, pair := range hsh.Pairs {
+		k, v := pair.Key, pair.Value
 		key, ok := k.(*ast.StringLiteral)
 		if !ok {
 			t.Errorf("Wrong key Type. Expected String got=%T", k)
 			continue
 		}
 
-		test, ok := expected[key.String()]
+		test, ok := expected[key.Value]
 		if !ok {
-			t.Errorf("Couldnt find test Function for key %q", key.String())
+			t.Errorf("Couldnt find test Function for key %q", key.Value)
 			continue
 		}
 		test(v)
@@ -878,108 +1374,136 @@ func TestPrecedenceParsing(t *testing.T) {
 	}{
 		{
 			"f * -b",
-			"(f * (-b))",
+			"(f * (-b));",
 		},
 		{
 			"-!f",
-			"(-(!f))",
+			"(-(!f));",
+		},
+		{
+			"a + b % c * d",
+			"(a + ((b % c) * d));",
+		},
+		{
+			"--a + b",
+			"((-(-a)) + b);",
 		},
 		{
 			"g + f + b",
-			"((g + f) + b)",
+			"((g + f) + b);",
 		},
 		{
 			"g - f + b",
-			"((g - f) + b)",
+			"((g - f) + b);",
 		},
 		{
 			"g * f * b",
-			"((g * f) * b)",
+			"((g * f) * b);",
 		},
 		{
 			"g / f * b",
-			"((g / f) * b)",
+			"((g / f) * b);",
 		},
 		{
 			"g - f / b",
-			"(g - (f / b))",
+			"(g - (f / b));",
 		},
 		{
 			"g + f / b + a * e - c",
-			"(((g + (f / b)) + (a * e)) - c)",
+			"(((g + (f / b)) + (a * e)) - c);",
 		},
 		{
 			"12 + 43; -2 * 64",
-			"(12 + 43)((-2) * 64)",
+			"(12 + 43);\n((-2) * 64);",
 		},
 		{
 			"2 < 42 == 32 < 4",
-			"((2 < 42) == (32 < 4))",
+			"((2 < 42) == (32 < 4));",
 		},
 		{
 			"532 < 42 != 332 > 41",
-			"((532 < 42) != (332 > 41))",
+			"((532 < 42) != (332 > 41));",
 		},
 		{
 			"17 - 2 * 4 == 6 * 2 + 23 * 5",
-			"((17 - (2 * 4)) == ((6 * 2) + (23 * 5)))",
+			"((17 - (2 * 4)) == ((6 * 2) + (23 * 5)));",
 		},
 
 		{
 			"true",
-			"true",
+			"true;",
 		},
 		{
 			"false",
-			"false",
+			"false;",
 		},
 		{
 			"2 < 23 == true",
-			"((2 < 23) == true)",
+			"((2 < 23) == true);",
 		},
 		{
 			"6 < 2 == false",
-			"((6 < 2) == false)",
+			"((6 < 2) == false);",
 		},
 		{
 			"3 + (2 + 5) + 1",
-			"((3 + (2 + 5)) + 1)",
+			"((3 + (2 + 5)) + 1);",
 		},
 		{
 			"3 * (2 + 5) ",
-			"(3 * (2 + 5))",
+			"(3 * (2 + 5));",
 		},
 		{
 			"(3 + 2) / 5 ",
-			"((3 + 2) / 5)",
+			"((3 + 2) / 5);",
 		},
 		{
 			"- (3 + 2) ",
-			"(-(3 + 2))",
+			"(-(3 + 2));",
 		},
 		{
 			"!(true != false)",
-			"(!(true != false))",
+			"(!(true != false));",
 		},
 		{
 			"g + print(f * b) + e",
-			"((g + print((f * b))) + e)",
+			"((g + print((f * b))) + e);",
 		},
 		{
 			"print(g, f, 1, 9 * 0, 7 + 3, show(6, 7 * 8))",
-			"print(g, f, 1, (9 * 0), (7 + 3), show(6, (7 * 8)))",
+			"print(g, f, 1, (9 * 0), (7 + 3), show(6, (7 * 8)));",
 		},
 		{
 			"print(g + f + b * e / f + g)",
-			"print((((g + f) + ((b * e) / f)) + g))",
+			"print((((g + f) + ((b * e) / f)) + g));",
 		},
 		{
 			"g * [1, 4, 5, 6, 8][3 + 2] * f",
-			"((g * ([1, 4, 5, 6, 8][(3 + 2)])) * f)",
+			"((g * ([1, 4, 5, 6, 8][(3 + 2)])) * f);",
 		},
 		{
 			"print(3 * g[5], f[1], 9 * [3, 5] [1])",
-			"print((3 * (g[5])), (f[1]), (9 * ([3, 5][1])))",
+			"print((3 * (g[5])), (f[1]), (9 * ([3, 5][1])));",
+		},
+		{
+			"a | b & c",
+			"(a | (b & c));",
+		},
+		{
+			"a ^ b << 2 + c",
+			"((a ^ (b << 2)) + c);",
+		},
+		{
+			"a & b == c | d",
+			"((a & b) == (c | d));",
+		},
+		{
+			"~a & &b",
+			"((~a) & (&b));",
+		},
+		{
+			"a >> 1 < b",
+			"((a >> 1) < b);",
 		},
 	}
 
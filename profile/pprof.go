@@ -0,0 +1,175 @@
+package profile
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// protoBuffer encodes protocol buffer messages, which is all the pprof
+// format needs.
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *protoBuffer) key(field, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+// uint64 encodes a varint field, leaving it out if it is zero.
+func (b *protoBuffer) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(x)
+}
+
+func (b *protoBuffer) int64(field int, x int64) {
+	b.uint64(field, uint64(x))
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	b.key(field, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protoBuffer) string(field int, s string) {
+	b.bytes(field, []byte(s))
+}
+
+func (b *protoBuffer) message(field int, encode func(m *protoBuffer)) {
+	m := &protoBuffer{}
+	encode(m)
+	b.bytes(field, m.data)
+}
+
+func (b *protoBuffer) packed(field int, xs []uint64) {
+	m := &protoBuffer{}
+	for _, x := range xs {
+		m.varint(x)
+	}
+	b.bytes(field, m.data)
+}
+
+// Fields of the messages in profile.proto of github.com/google/pprof.
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+	profilePeriodType    = 11
+	profilePeriod        = 12
+	profileDefaultSample = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID        = 1
+	functionName      = 2
+	functionFilename  = 4
+	functionStartLine = 5
+)
+
+// WritePprof writes the profile in the gzipped protocol buffer format of
+// pprof. Each sample has the number of events, the time in nanoseconds and
+// the number of allocations of a stack.
+func (prof *Profile) WritePprof(w io.Writer) error {
+	strs := []string{""}
+	index := map[string]int64{"": 0}
+	str := func(s string) int64 {
+		i, ok := index[s]
+		if !ok {
+			i = int64(len(strs))
+			strs = append(strs, s)
+			index[s] = i
+		}
+		return i
+	}
+
+	b := &protoBuffer{}
+	valueType := func(field int, typ, unit string) {
+		b.message(field, func(m *protoBuffer) {
+			m.int64(valueTypeType, str(typ))
+			m.int64(valueTypeUnit, str(unit))
+		})
+	}
+	valueType(profileSampleType, "samples", "count")
+	valueType(profileSampleType, "time", "nanoseconds")
+	valueType(profileSampleType, "alloc_objects", "count")
+
+	funcIDs := map[*Function]uint64{}
+	for i, fn := range prof.Functions {
+		funcIDs[fn] = uint64(i + 1)
+	}
+	locIDs := map[*Line]uint64{}
+	for i, line := range prof.Lines {
+		locIDs[line] = uint64(i + 1)
+	}
+
+	for _, sample := range prof.Samples {
+		ids := make([]uint64, len(sample.Stack))
+		for i, line := range sample.Stack {
+			ids[i] = locIDs[line]
+		}
+		b.message(profileSample, func(m *protoBuffer) {
+			m.packed(sampleLocationID, ids)
+			m.packed(sampleValue, []uint64{1, uint64(sample.Time.Nanoseconds()), uint64(sample.Allocs)})
+		})
+	}
+	for _, line := range prof.Lines {
+		b.message(profileLocation, func(m *protoBuffer) {
+			m.uint64(locationID, locIDs[line])
+			m.message(locationLine, func(l *protoBuffer) {
+				l.uint64(lineFunctionID, funcIDs[line.Function])
+				l.int64(lineLine, int64(line.Line))
+			})
+		})
+	}
+	for _, fn := range prof.Functions {
+		b.message(profileFunction, func(m *protoBuffer) {
+			m.uint64(functionID, funcIDs[fn])
+			m.int64(functionName, str(fn.Name))
+			m.int64(functionFilename, str(fn.File))
+			m.int64(functionStartLine, int64(fn.Line))
+		})
+	}
+
+	if !prof.Start.IsZero() {
+		b.int64(profileTimeNanos, prof.Start.UnixNano())
+	}
+	b.int64(profileDurationNanos, prof.Duration.Nanoseconds())
+	valueType(profilePeriodType, "time", "nanoseconds")
+	b.int64(profilePeriod, 1)
+	b.int64(profileDefaultSample, str("time"))
+	// The string table comes last, once all strings are known.
+	for _, s := range strs {
+		b.string(profileStringTable, s)
+	}
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
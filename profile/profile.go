@@ -0,0 +1,273 @@
+// Package profile measures where chimp programs spend their time. A
+// Profiler follows the call stack of an evaluator and charges the time
+// between two events (a statement, a call or a return) to the stack the
+// time was spent in. It counts calls, statements and allocations exactly,
+// where an allocation is an array, hash, string, big integer, function or
+// pointer created. The result is a Profile, which can be written as a text
+// report, as folded stacks for flame graphs or in the pprof format.
+package profile
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/object"
+)
+
+// Function is the profile of a function, or of a program or module.
+type Function struct {
+	Name string
+	File string
+	// Line is the line the function starts on.
+	Line  int
+	Calls int
+	// Self is the time spent in the function itself, Total includes the
+	// time spent in the functions it called.
+	Self, Total time.Duration
+	// Allocs is the number of objects the function allocated itself.
+	Allocs int
+}
+
+// Line is the profile of a line of a function.
+type Line struct {
+	Function *Function
+	Line     int
+	// Count is how many statements starting on the line were run.
+	Count       int
+	Self, Total time.Duration
+	Allocs      int
+
+	id int // unique in a profile
+}
+
+// Sample is the time spent and the allocations made in one stack.
+type Sample struct {
+	// Stack are the lines that were active, the innermost first.
+	Stack  []*Line
+	Time   time.Duration
+	Allocs int
+}
+
+// Profile is what a Profiler measured.
+type Profile struct {
+	Start    time.Time
+	Duration time.Duration
+	// Functions and Lines are sorted by self time, the largest first.
+	Functions []*Function
+	Lines     []*Line
+	Samples   []*Sample
+}
+
+type funcKey struct {
+	name, file string
+	line       int
+}
+
+type lineKey struct {
+	fn   *Function
+	line int
+}
+
+// activeFrame is a frame on the stack and the line it is at.
+type activeFrame struct {
+	fn   *Function
+	line *Line
+}
+
+// Profiler records a profile of the programs of an evaluator it is set as
+// the profiler of.
+type Profiler struct {
+	now   func() time.Time
+	start time.Time
+	last  time.Time
+	stack []activeFrame
+
+	funcs   map[funcKey]*Function
+	lines   map[lineKey]*Line
+	samples map[string]*Sample
+}
+
+// New returns a profiler. Install it with Evaluator.SetProfiler.
+func New() *Profiler {
+	return newProfiler(time.Now)
+}
+
+func newProfiler(now func() time.Time) *Profiler {
+	return &Profiler{
+		now:     now,
+		funcs:   map[funcKey]*Function{},
+		lines:   map[lineKey]*Line{},
+		samples: map[string]*Sample{},
+	}
+}
+
+// Enter implements evaluator.Profiler.
+func (p *Profiler) Enter(frame *evaluator.Frame) {
+	p.tick()
+	key := funcKey{frame.Name, frame.File, frame.Line}
+	fn, ok := p.funcs[key]
+	if !ok {
+		fn = &Function{Name: frame.Name, File: frame.File, Line: frame.Line}
+		p.funcs[key] = fn
+	}
+	fn.Calls++
+	p.stack = append(p.stack, activeFrame{fn: fn, line: p.line(fn, frame.Line)})
+}
+
+// Leave implements evaluator.Profiler.
+func (p *Profiler) Leave(frame *evaluator.Frame) {
+	p.tick()
+	if len(p.stack) > 0 {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+}
+
+// Statement implements evaluator.Profiler.
+func (p *Profiler) Statement(stmt ast.Statement) {
+	p.tick()
+	if len(p.stack) == 0 {
+		return
+	}
+	top := &p.stack[len(p.stack)-1]
+	top.line = p.line(top.fn, ast.Start(stmt).Line)
+	top.line.Count++
+}
+
+// Allocate implements evaluator.Profiler.
+func (p *Profiler) Allocate(obj object.Object) {
+	if len(p.stack) == 0 {
+		return
+	}
+	top := p.stack[len(p.stack)-1]
+	top.fn.Allocs++
+	top.line.Allocs++
+	p.sample().Allocs++
+}
+
+func (p *Profiler) line(fn *Function, n int) *Line {
+	key := lineKey{fn, n}
+	line, ok := p.lines[key]
+	if !ok {
+		line = &Line{Function: fn, Line: n, id: len(p.lines) + 1}
+		p.lines[key] = line
+	}
+	return line
+}
+
+// tick charges the time since the last event to the current stack.
+func (p *Profiler) tick() {
+	now := p.now()
+	if p.start.IsZero() {
+		p.start = now
+	}
+	elapsed := now.Sub(p.last)
+	p.last = now
+	if len(p.stack) == 0 {
+		return
+	}
+
+	p.sample().Time += elapsed
+	top := p.stack[len(p.stack)-1]
+	top.fn.Self += elapsed
+	top.line.Self += elapsed
+	// Recursive functions are on the stack more than once, but the time
+	// counts once towards their total.
+	seenFuncs := map[*Function]bool{}
+	seenLines := map[*Line]bool{}
+	for _, frame := range p.stack {
+		if !seenFuncs[frame.fn] {
+			seenFuncs[frame.fn] = true
+			frame.fn.Total += elapsed
+		}
+		if !seenLines[frame.line] {
+			seenLines[frame.line] = true
+			frame.line.Total += elapsed
+		}
+	}
+}
+
+// sample returns the sample of the current stack.
+func (p *Profiler) sample() *Sample {
+	var key strings.Builder
+	for _, frame := range p.stack {
+		key.WriteString(strconv.Itoa(frame.line.id))
+		key.WriteByte(';')
+	}
+	sample, ok := p.samples[key.String()]
+	if !ok {
+		sample = &Sample{}
+		for i := len(p.stack) - 1; i >= 0; i-- {
+			sample.Stack = append(sample.Stack, p.stack[i].line)
+		}
+		p.samples[key.String()] = sample
+	}
+	return sample
+}
+
+// Profile returns what the profiler measured so far.
+func (p *Profiler) Profile() *Profile {
+	prof := &Profile{Start: p.start}
+	for _, fn := range p.funcs {
+		prof.Functions = append(prof.Functions, fn)
+	}
+	for _, line := range p.lines {
+		prof.Lines = append(prof.Lines, line)
+	}
+	for _, sample := range p.samples {
+		prof.Samples = append(prof.Samples, sample)
+		prof.Duration += sample.Time
+	}
+
+	sort.Slice(prof.Functions, func(i, j int) bool {
+		a, b := prof.Functions[i], prof.Functions[j]
+		if a.Self != b.Self {
+			return a.Self > b.Self
+		}
+		return funcLess(a, b)
+	})
+	sort.Slice(prof.Lines, func(i, j int) bool {
+		a, b := prof.Lines[i], prof.Lines[j]
+		if a.Self != b.Self {
+			return a.Self > b.Self
+		}
+		return lineLess(a, b)
+	})
+	sort.Slice(prof.Samples, func(i, j int) bool {
+		return stackLess(prof.Samples[i].Stack, prof.Samples[j].Stack)
+	})
+	return prof
+}
+
+func funcLess(a, b *Function) bool {
+	if a.File != b.File {
+		return a.File < b.File
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Name < b.Name
+}
+
+func lineLess(a, b *Line) bool {
+	if a.Function.File != b.Function.File {
+		return a.Function.File < b.Function.File
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return funcLess(a.Function, b.Function)
+}
+
+// stackLess orders stacks from the outermost frame in.
+func stackLess(a, b []*Line) bool {
+	for i, j := len(a)-1, len(b)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if a[i] != b[j] {
+			return lineLess(a[i], b[j])
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,233 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Muto1907/interpreterInGo/evaluator"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/object"
+	"github.com/Muto1907/interpreterInGo/parser"
+)
+
+const script = `let sq = fnc(x) {
+	x * x
+};
+let p = &1;
+sq(2) + sq(3);`
+
+// profileScript profiles script with a clock that advances a millisecond
+// every time it is read.
+func profileScript(t *testing.T) *Profile {
+	t.Helper()
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := newProfiler(func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	})
+	eval := evaluator.NewEval()
+	eval.SetProfiler(p)
+	program := parser.New(lexer.New(script)).ParseProgram()
+	if result, ok := eval.Eval(program, object.NewEnvironment()).(*object.Error); ok {
+		t.Fatalf("unexpected error: %s", result.Message)
+	}
+	return p.Profile()
+}
+
+func TestFunctions(t *testing.T) {
+	prof := profileScript(t)
+	if prof.Duration != 10*time.Millisecond {
+		t.Errorf("wrong duration %s", prof.Duration)
+	}
+
+	tests := []struct {
+		name          string
+		calls         int
+		self, total   time.Duration
+		allocs, index int
+	}{
+		{"main", 1, 6 * time.Millisecond, 10 * time.Millisecond, 2, 0},
+		{"sq", 2, 4 * time.Millisecond, 4 * time.Millisecond, 0, 1},
+	}
+	if len(prof.Functions) != len(tests) {
+		t.Fatalf("wrong number of functions %d", len(prof.Functions))
+	}
+	for _, tt := range tests {
+		fn := prof.Functions[tt.index]
+		if fn.Name != tt.name || fn.Calls != tt.calls || fn.Self != tt.self || fn.Total != tt.total || fn.Allocs != tt.allocs {
+			t.Errorf("wrong profile of %s: %+v", tt.name, fn)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	prof := profileScript(t)
+	tests := []struct {
+		function    string
+		line, count int
+		self, total time.Duration
+		allocs      int
+	}{
+		{"main", 5, 1, 3 * time.Millisecond, 7 * time.Millisecond, 0},
+		{"main", 1, 1, 2 * time.Millisecond, 2 * time.Millisecond, 1},
+		{"sq", 1, 0, 2 * time.Millisecond, 2 * time.Millisecond, 0},
+		{"sq", 2, 2, 2 * time.Millisecond, 2 * time.Millisecond, 0},
+		{"main", 4, 1, 1 * time.Millisecond, 1 * time.Millisecond, 1},
+	}
+	if len(prof.Lines) != len(tests) {
+		t.Fatalf("wrong number of lines %d", len(prof.Lines))
+	}
+	for i, tt := range tests {
+		line := prof.Lines[i]
+		if line.Function.Name != tt.function || line.Line != tt.line || line.Count != tt.count ||
+			line.Self != tt.self || line.Total != tt.total || line.Allocs != tt.allocs {
+			t.Errorf("lines[%d]: expected %s:%d, got %s:%d %+v", i, tt.function, tt.line, line.Function.Name, line.Line, line)
+		}
+	}
+}
+
+// Allocations are the objects created by expressions and builtins, charged
+// to the function that created them.
+func TestAllocations(t *testing.T) {
+	p := New()
+	eval := evaluator.NewEval()
+	eval.SetProfiler(p)
+	program := parser.New(lexer.New(`let pair = fnc(n) { [n, "a" + "b"] };
+pair(1);
+pair(2);
+split("x y", " ");
+let big = 9223372036854775807 + 1;`)).ParseProgram()
+	if result, ok := eval.Eval(program, object.NewEnvironment()).(*object.Error); ok {
+		t.Fatalf("unexpected error: %s", result.Message)
+	}
+
+	// pair allocates its array and three strings per call. main allocates
+	// the function, the two strings passed to split, the array and two
+	// strings split returns and the big integer.
+	allocs := map[string]int{"pair": 8, "main": 7}
+	for _, fn := range p.Profile().Functions {
+		if fn.Allocs != allocs[fn.Name] {
+			t.Errorf("wrong allocs of %s. expected=%d got=%d", fn.Name, allocs[fn.Name], fn.Allocs)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var out bytes.Buffer
+	if err := profileScript(t).WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"total time 10ms",
+		"       1          6ms  60.00%         10ms 100.00%        2  main line 1",
+		"       2          4ms  40.00%          4ms  40.00%        0  sq line 1",
+		"       1          3ms  30.00%          7ms  70.00%        0  line:5 in main",
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected %q in\n%s", line, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "line:1 in sq") {
+		t.Errorf("lines that did not run are reported:\n%s", out.String())
+	}
+}
+
+func TestWriteFolded(t *testing.T) {
+	var out bytes.Buffer
+	if err := profileScript(t).WriteFolded(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "main 6000000\nmain;sq 4000000\n"
+	if out.String() != expected {
+		t.Errorf("wrong folded stacks.\nexpected=%q\ngot=     %q", expected, out.String())
+	}
+}
+
+func varint(t *testing.T, data *[]byte) uint64 {
+	t.Helper()
+	var x uint64
+	for shift := 0; ; shift += 7 {
+		if len(*data) == 0 {
+			t.Fatal("truncated message")
+		}
+		b := (*data)[0]
+		*data = (*data)[1:]
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x
+		}
+	}
+}
+
+// fields decodes the top level fields of a protocol buffer message.
+// Varints are returned as numbers, length delimited fields as bytes.
+func fields(t *testing.T, data []byte) map[int][]any {
+	t.Helper()
+	fs := map[int][]any{}
+	for len(data) > 0 {
+		key := varint(t, &data)
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			fs[field] = append(fs[field], varint(t, &data))
+		case 2:
+			n := varint(t, &data)
+			fs[field] = append(fs[field], data[:n])
+			data = data[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return fs
+}
+
+func TestWritePprof(t *testing.T) {
+	var out bytes.Buffer
+	if err := profileScript(t).WritePprof(&out); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := fields(t, data)
+	strs := []string{}
+	for _, s := range fs[profileStringTable] {
+		strs = append(strs, string(s.([]byte)))
+	}
+	if len(strs) == 0 || strs[0] != "" {
+		t.Fatalf("string table must start with the empty string: %q", strs)
+	}
+	for _, s := range []string{"main", "sq", "time", "nanoseconds", "alloc_objects"} {
+		if !strings.Contains(strings.Join(strs, "\n")+"\n", "\n"+s+"\n") {
+			t.Errorf("missing string %q in %q", s, strs)
+		}
+	}
+	if len(fs[profileSampleType]) != 3 || len(fs[profileSample]) != 5 ||
+		len(fs[profileLocation]) != 5 || len(fs[profileFunction]) != 2 {
+		t.Errorf("wrong number of sample types, samples, locations or functions")
+	}
+	if d := fs[profileDurationNanos]; len(d) != 1 || d[0].(uint64) != uint64(10*time.Millisecond) {
+		t.Errorf("wrong duration %v", d)
+	}
+
+	var total uint64
+	for _, sample := range fs[profileSample] {
+		values := fields(t, sample.([]byte))[sampleValue][0].([]byte)
+		varint(t, &values)
+		total += varint(t, &values)
+	}
+	if total != uint64(10*time.Millisecond) {
+		t.Errorf("samples add up to %d ns", total)
+	}
+}
@@ -0,0 +1,80 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// location names where a function starts, or just its file if it has no
+// name of its own.
+func (fn *Function) location() string {
+	if fn.File == "" {
+		return fmt.Sprintf("line %d", fn.Line)
+	}
+	return fmt.Sprintf("%s:%d", fn.File, fn.Line)
+}
+
+func percent(d, of time.Duration) float64 {
+	if of == 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(of)
+}
+
+// WriteText writes a report of the functions and of the lines that ran,
+// the most expensive first.
+func (prof *Profile) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "total time %s\n\n", prof.Duration)
+
+	fmt.Fprintf(bw, "%8s %12s %7s %12s %7s %8s  %s\n", "calls", "self", "self%", "total", "total%", "allocs", "function")
+	for _, fn := range prof.Functions {
+		fmt.Fprintf(bw, "%8d %12s %6.2f%% %12s %6.2f%% %8d  %s %s\n",
+			fn.Calls, fn.Self, percent(fn.Self, prof.Duration), fn.Total, percent(fn.Total, prof.Duration),
+			fn.Allocs, fn.Name, fn.location())
+	}
+
+	fmt.Fprintf(bw, "\n%8s %12s %7s %12s %7s %8s  %s\n", "count", "self", "self%", "total", "total%", "allocs", "line")
+	for _, line := range prof.Lines {
+		if line.Count == 0 {
+			continue
+		}
+		file := line.Function.File
+		if file == "" {
+			file = "line"
+		}
+		fmt.Fprintf(bw, "%8d %12s %6.2f%% %12s %6.2f%% %8d  %s:%d in %s\n",
+			line.Count, line.Self, percent(line.Self, prof.Duration), line.Total, percent(line.Total, prof.Duration),
+			line.Allocs, file, line.Line, line.Function.Name)
+	}
+	return bw.Flush()
+}
+
+// WriteFolded writes the time spent in each stack of functions in the
+// folded format of flame graph tools: the names of the functions from the
+// outermost in, separated by semicolons, and the time in nanoseconds.
+func (prof *Profile) WriteFolded(w io.Writer) error {
+	times := map[string]time.Duration{}
+	for _, sample := range prof.Samples {
+		names := []string{}
+		for i := len(sample.Stack) - 1; i >= 0; i-- {
+			names = append(names, strings.ReplaceAll(sample.Stack[i].Function.Name, ";", ":"))
+		}
+		times[strings.Join(names, ";")] += sample.Time
+	}
+
+	stacks := make([]string, 0, len(times))
+	for stack := range times {
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	bw := bufio.NewWriter(w)
+	for _, stack := range stacks {
+		fmt.Fprintf(bw, "%s %d\n", stack, times[stack].Nanoseconds())
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,44 @@
+// Package stdlib embeds the standard library modules, which are written in
+// chimp and imported as "std/<name>".
+package stdlib
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Prefix marks import paths that refer to the standard library.
+const Prefix = "std/"
+
+//go:embed *.chimp
+var files embed.FS
+
+// Source returns the source of the module imported as importPath, e.g.
+// "std/math" or "std/math.chimp".
+func Source(importPath string) (string, bool) {
+	name, ok := strings.CutPrefix(importPath, Prefix)
+	if !ok || strings.Contains(name, "/") {
+		return "", false
+	}
+	if path.Ext(name) == "" {
+		name += ".chimp"
+	}
+	src, err := files.ReadFile(name)
+	if err != nil {
+		return "", false
+	}
+	return string(src), true
+}
+
+// Modules returns the import paths of all standard library modules.
+func Modules() []string {
+	entries, _ := files.ReadDir(".")
+	modules := []string{}
+	for _, entry := range entries {
+		modules = append(modules, Prefix+strings.TrimSuffix(entry.Name(), ".chimp"))
+	}
+	sort.Strings(modules)
+	return modules
+}
@@ -0,0 +1,67 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/format"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+)
+
+func TestModules(t *testing.T) {
+	expected := []string{"std/collections", "std/iter", "std/math", "std/strings", "std/testing"}
+	modules := Modules()
+	if len(modules) != len(expected) {
+		t.Fatalf("wrong modules. expected=%v got=%v", expected, modules)
+	}
+	for i, name := range expected {
+		if modules[i] != name {
+			t.Errorf("wrong module %d. expected=%s got=%s", i, name, modules[i])
+		}
+	}
+}
+
+func TestModulesParse(t *testing.T) {
+	for _, name := range Modules() {
+		src, ok := Source(name)
+		if !ok {
+			t.Fatalf("no source for %s", name)
+		}
+		p := parser.New(lexer.New(src))
+		p.ParseProgram()
+		for _, msg := range p.Errors() {
+			t.Errorf("%s: %s", name, msg)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"std/math", true},
+		{"std/math.chimp", true},
+		{"math", false},
+		{"std/nope", false},
+		{"std/../stdlib.go", false},
+	}
+	for _, tt := range tests {
+		if _, ok := Source(tt.path); ok != tt.ok {
+			t.Errorf("Source(%q) ok=%t, expected %t", tt.path, ok, tt.ok)
+		}
+	}
+}
+
+func TestModulesAreFormatted(t *testing.T) {
+	for _, name := range Modules() {
+		src, _ := Source(name)
+		formatted, err := format.Source(src)
+		if err != nil {
+			t.Fatalf("%s does not format: %s", name, err)
+		}
+		if formatted != src {
+			t.Errorf("%s is not formatted:\n%s", name, format.Diff(name, src, formatted))
+		}
+	}
+}
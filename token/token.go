@@ -2,9 +2,12 @@ package token
 
 type TokenType string
 
+// Token carries the 1-based line and column of its first character.
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type    TokenType `json:"type"`
+	Literal string    `json:"literal"`
+	Line    int       `json:"line"`
+	Column  int       `json:"column"`
 }
 
 const (
@@ -17,6 +20,10 @@ const (
 	IDENT  = "IDENT"
 	INT    = "INT"
 	STRING = "STRING"
+	// A string literal containing ${...} interpolations
+	TEMPLATE = "TEMPLATE"
+	// Line comments are recorded by the lexer, not returned as tokens
+	COMMENT = "COMMENT"
 
 	// Operators
 	ASSIGN    = "="
@@ -30,11 +37,28 @@ const (
 	EQ        = "=="
 	NOT_EQ    = "!="
 	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	SHL       = "<<"
+	SHR       = ">>"
+	MOD       = "%"
+
+	// Compound assignment and increment
+	PLUS_ASSIGN  = "+="
+	MINUS_ASSIGN = "-="
+	MULT_ASSIGN  = "*="
+	DIV_ASSIGN   = "/="
+	MOD_ASSIGN   = "%="
+	INCREMENT    = "++"
+	DECREMENT    = "--"
 
 	// Delimeters
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	ELLIPSIS  = "..."
+	DOT       = "."
 
 	PARENL   = "("
 	PARENR   = ")"
@@ -52,6 +76,9 @@ const (
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
 	WHILE    = "WHILE"
+	CONST    = "CONST"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
 )
 
 var keywords = map[string]TokenType{
@@ -63,6 +90,9 @@ var keywords = map[string]TokenType{
 	"else":   ELSE,
 	"return": RETURN,
 	"while":  WHILE,
+	"const":  CONST,
+	"import": IMPORT,
+	"export": EXPORT,
 }
 
 func FindKeywordOrIdent(keyword string) TokenType {
@@ -0,0 +1,25 @@
+package types
+
+// builtIns are the signatures of the builtin functions whose argument and
+// result types do not depend on each other. The others have type any.
+var builtIns = map[string]*Func{
+	"len":         {Params: []Type{Any}, Result: Int},
+	"puts":        {Params: []Type{Any}, Result: Null, Variadic: true},
+	"split":       {Params: []Type{String, String}, Result: &Array{Elem: String}, Variadic: true},
+	"join":        {Params: []Type{&Array{Elem: String}, String}, Result: String},
+	"trim":        {Params: []Type{String}, Result: String},
+	"upper":       {Params: []Type{String}, Result: String},
+	"lower":       {Params: []Type{String}, Result: String},
+	"contains":    {Params: []Type{String, String}, Result: Bool},
+	"index_of":    {Params: []Type{String, String}, Result: Int},
+	"replace":     {Params: []Type{String, String, String}, Result: String},
+	"starts_with": {Params: []Type{String, String}, Result: Bool},
+	"ends_with":   {Params: []Type{String, String}, Result: Bool},
+	"repeat":      {Params: []Type{String, Int}, Result: String},
+	"chars":       {Params: []Type{String}, Result: &Array{Elem: String}},
+	"range":       {Params: []Type{Int, Int}, Result: &Array{Elem: Int}, Variadic: true},
+	"any":         {Params: []Type{&Array{Elem: Any}, Any}, Result: Bool},
+	"all":         {Params: []Type{&Array{Elem: Any}, Any}, Result: Bool},
+	"has":         {Params: []Type{&Hash{Key: Any, Value: Any}, Any}, Result: Bool},
+	"identical":   {Params: []Type{Any, Any}, Result: Bool},
+}
@@ -0,0 +1,634 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+// Error is a type error found before evaluation.
+type Error struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// Source parses and checks src. It fails if src does not parse.
+func Source(src string) ([]Error, error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "\n"))
+	}
+	return Program(program), nil
+}
+
+// Program checks program and returns the type errors sorted by position.
+func Program(program *ast.Program) []Error {
+	return Check(program, nil)
+}
+
+// Info holds the types inferred for a program.
+type Info struct {
+	// Types maps expressions to their types.
+	Types map[ast.Expression]Type
+	// Defs maps the identifiers that declare names to the declared types.
+	Defs map[*ast.Identifier]Type
+}
+
+// NewInfo returns an empty Info.
+func NewInfo() *Info {
+	return &Info{Types: map[ast.Expression]Type{}, Defs: map[*ast.Identifier]Type{}}
+}
+
+// Check is like Program and records the inferred types in info unless it
+// is nil.
+func Check(program *ast.Program, info *Info) []Error {
+	c := &checker{signatures: map[*ast.FuncLiteral]*Func{}, info: info}
+	c.statements(program.Statements, newScope(nil))
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		a, b := c.errors[i], c.errors[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return c.errors
+}
+
+// scope holds the types of the names declared in a program, block or
+// function call.
+type scope struct {
+	outer *scope
+	types map[string]Type
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, types: map[string]Type{}}
+}
+
+func (s *scope) lookup(name string) (Type, bool) {
+	for ; s != nil; s = s.outer {
+		if typ, ok := s.types[name]; ok {
+			return typ, true
+		}
+	}
+	return nil, false
+}
+
+// function collects the results of the function being checked.
+type function struct {
+	declared Type // nil unless the result is annotated
+	results  []Type
+}
+
+type checker struct {
+	errors     []Error
+	fn         *function
+	signatures map[*ast.FuncLiteral]*Func
+	info       *Info
+}
+
+func (c *checker) errorf(tok token.Token, format string, a ...any) {
+	c.errors = append(c.errors, Error{Line: tok.Line, Column: tok.Column, Message: fmt.Sprintf(format, a...)})
+}
+
+// define declares ident with type typ in s.
+func (c *checker) define(ident *ast.Identifier, typ Type, s *scope) {
+	s.types[ident.Value] = typ
+	if c.info != nil {
+		c.info.Defs[ident] = typ
+	}
+}
+
+// assign reports an error unless a value of type from can be used as a
+// value of type to.
+func (c *checker) assign(from, to Type, tok token.Token, context string) {
+	if !AssignableTo(from, to) {
+		c.errorf(tok, "cannot use %s as %s in %s", from, to, context)
+	}
+}
+
+// resolve converts an annotation to a type.
+func (c *checker) resolve(typ ast.Type) Type {
+	switch typ := typ.(type) {
+	case *ast.NamedType:
+		if basic, ok := basics[typ.Name]; ok {
+			return basic
+		}
+		c.errorf(typ.Token, "unknown type %s", typ.Name)
+		return Any
+	case *ast.ArrayType:
+		return &Array{Elem: c.resolve(typ.Element)}
+	case *ast.HashType:
+		return &Hash{Key: c.resolve(typ.Key), Value: c.resolve(typ.Value)}
+	case *ast.RecordType:
+		fields := []Field{}
+		for _, field := range typ.Fields {
+			fields = append(fields, Field{Name: field.KeyName(), Type: c.resolve(field.Type)})
+		}
+		return NewRecord(fields)
+	case *ast.FuncType:
+		fn := &Func{Params: []Type{}, Result: Any}
+		for _, param := range typ.Parameters {
+			fn.Params = append(fn.Params, c.resolve(param))
+		}
+		if typ.Return != nil {
+			fn.Result = c.resolve(typ.Return)
+		}
+		return fn
+	}
+	return Any
+}
+
+// statements checks stmts and returns the type of the value they produce,
+// which is the value of the last statement, or nil if they end with a
+// return.
+func (c *checker) statements(stmts []ast.Statement, s *scope) Type {
+	var result Type = Null
+	for _, stmt := range stmts {
+		result = c.statement(stmt, s)
+	}
+	return result
+}
+
+func (c *checker) block(block *ast.BlockStatement, s *scope) Type {
+	if block == nil {
+		return Null
+	}
+	return c.statements(block.Statements, newScope(s))
+}
+
+func (c *checker) statement(stmt ast.Statement, s *scope) Type {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		c.let(stmt, s)
+	case *ast.ReturnStatement:
+		var result Type = Null
+		if stmt.ReturnValue != nil {
+			result = c.expr(stmt.ReturnValue, s)
+		}
+		if c.fn != nil {
+			if c.fn.declared != nil {
+				c.assign(result, c.fn.declared, returnToken(stmt), "return")
+			}
+			c.fn.results = append(c.fn.results, result)
+		}
+		return nil
+	case *ast.ExpressionStatement:
+		if ifExpr, ok := stmt.Expression.(*ast.IfExpression); ok {
+			return c.ifExpr(ifExpr, s)
+		}
+		return c.expr(stmt.Expression, s)
+	case *ast.ReassignmentStatement:
+		c.assignment(stmt, s)
+	case *ast.WhileStatement:
+		c.expr(stmt.Condition, s)
+		c.block(stmt.Body, s)
+	case *ast.BlockStatement:
+		return c.block(stmt, s)
+	case *ast.ImportStatement:
+		if stmt.Alias != nil {
+			c.define(stmt.Alias, Any, s)
+		}
+		for _, name := range stmt.Names {
+			c.define(name.Binding(), Any, s)
+		}
+	}
+	return Any
+}
+
+func returnToken(stmt *ast.ReturnStatement) token.Token {
+	if stmt.ReturnValue != nil {
+		return ast.Start(stmt.ReturnValue)
+	}
+	return stmt.Token
+}
+
+func (c *checker) let(stmt *ast.LetStatement, s *scope) {
+	var declared Type
+	if stmt.Type != nil {
+		declared = c.resolve(stmt.Type)
+	}
+	// Functions may call themselves, so their names are declared with
+	// the annotated signature before the body is checked.
+	if fn, ok := stmt.Value.(*ast.FuncLiteral); ok && stmt.Name != nil {
+		if declared != nil {
+			c.define(stmt.Name, declared, s)
+		} else {
+			c.define(stmt.Name, c.signature(fn), s)
+		}
+	}
+
+	value := c.expr(stmt.Value, s)
+	if declared != nil {
+		context := "declaration"
+		if stmt.Name != nil {
+			context += " of " + stmt.Name.Value
+		}
+		c.assign(value, declared, ast.Start(stmt.Value), context)
+		value = declared
+	} else if value == Null {
+		// A variable initialized to null is assigned something else later.
+		value = Any
+	}
+
+	if stmt.Pattern != nil {
+		c.bind(stmt.Pattern, value, s)
+	} else if stmt.Name != nil {
+		c.define(stmt.Name, value, s)
+	}
+}
+
+// bind declares the names of pattern with the types of the parts of a
+// value of type typ.
+func (c *checker) bind(pattern ast.Pattern, typ Type, s *scope) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		c.define(pattern, typ, s)
+	case *ast.ArrayPattern:
+		var elem, rest Type = Any, Any
+		if array, ok := typ.(*Array); ok {
+			elem, rest = array.Elem, array
+		} else if typ != Any {
+			c.errorf(pattern.Token, "cannot destructure %s as array %s", typ, pattern)
+		}
+		for _, element := range pattern.Elements {
+			c.bind(element, elem, s)
+		}
+		if pattern.Rest != nil {
+			c.define(pattern.Rest, rest, s)
+		}
+	case *ast.HashPattern:
+		var rest Type = Any
+		value := func(key string) Type { return Any }
+		switch typ := typ.(type) {
+		case *Record:
+			value = func(key string) Type {
+				if field, ok := typ.Field(key); ok {
+					return field
+				}
+				return Any
+			}
+		case *Hash:
+			value = func(string) Type { return typ.Value }
+			rest = typ
+		default:
+			if typ != Any {
+				c.errorf(pattern.Token, "cannot destructure %s as hash %s", typ, pattern)
+			}
+		}
+		for _, pair := range pattern.Pairs {
+			c.bind(pair.Value, value(pair.KeyName()), s)
+		}
+		if pattern.Rest != nil {
+			c.define(pattern.Rest, rest, s)
+		}
+	}
+}
+
+func (c *checker) assignment(stmt *ast.ReassignmentStatement, s *scope) {
+	target := c.expr(stmt.Left, s)
+	context := "assignment"
+	if ident, ok := stmt.Left.(*ast.Identifier); ok {
+		context += " to " + ident.Value
+	}
+
+	switch stmt.Operator {
+	case "=":
+		c.assign(c.expr(stmt.Value, s), target, ast.Start(stmt.Value), context)
+	case "++", "--":
+		result := c.infix(stmt.Operator[:1], target, Int, stmt.Token)
+		c.assign(result, target, stmt.Token, context)
+	default:
+		result := c.infix(strings.TrimSuffix(stmt.Operator, "="), target, c.expr(stmt.Value, s), stmt.Token)
+		c.assign(result, target, stmt.Token, context)
+	}
+}
+
+// signature returns the type of fn as far as it is annotated.
+func (c *checker) signature(fn *ast.FuncLiteral) *Func {
+	if sig, ok := c.signatures[fn]; ok {
+		return sig
+	}
+	sig := &Func{Params: []Type{}, Result: Any}
+	for i := range fn.Parameters {
+		var param Type = Any
+		if typ := fn.ParamType(i); typ != nil {
+			param = c.resolve(typ)
+		}
+		sig.Params = append(sig.Params, param)
+	}
+	if fn.ReturnType != nil {
+		sig.Result = c.resolve(fn.ReturnType)
+	}
+	c.signatures[fn] = sig
+	return sig
+}
+
+// function checks the body of fn and returns its type. An unannotated
+// result is inferred from the returned values.
+func (c *checker) function(fn *ast.FuncLiteral, s *scope) Type {
+	sig := c.signature(fn)
+	inner := newScope(s)
+	for i, param := range fn.Parameters {
+		c.bind(param, sig.Params[i], inner)
+	}
+
+	outer := c.fn
+	c.fn = &function{}
+	if fn.ReturnType != nil {
+		c.fn.declared = sig.Result
+	}
+	defer func() { c.fn = outer }()
+
+	if fn.Body == nil {
+		return sig
+	}
+	last := c.statements(fn.Body.Statements, inner)
+	switch {
+	case c.fn.declared == nil:
+	case fallsOff(fn.Body.Statements):
+		if !AssignableTo(Null, c.fn.declared) {
+			c.errorf(fn.Body.Close, "missing return at end of function returning %s", c.fn.declared)
+		}
+	case last != nil:
+		c.assign(last, c.fn.declared, ast.Start(fn.Body.Statements[len(fn.Body.Statements)-1]), "return")
+	}
+	if last != nil {
+		c.fn.results = append(c.fn.results, last)
+	}
+	if c.fn.declared != nil || len(c.fn.results) == 0 {
+		return sig
+	}
+	result := c.fn.results[0]
+	for _, typ := range c.fn.results[1:] {
+		result = join(result, typ)
+	}
+	return &Func{Params: sig.Params, Result: result}
+}
+
+// fallsOff reports whether running stmts can reach their end without a
+// value: they are empty, end in a statement that has none or in an if
+// without else, or in an if or block some way through which falls off.
+func fallsOff(stmts []ast.Statement) bool {
+	if len(stmts) == 0 {
+		return true
+	}
+	switch stmt := stmts[len(stmts)-1].(type) {
+	case *ast.ReturnStatement:
+		return false
+	case *ast.BlockStatement:
+		return fallsOff(stmt.Statements)
+	case *ast.ExpressionStatement:
+		ifExpr, ok := stmt.Expression.(*ast.IfExpression)
+		if !ok {
+			return false
+		}
+		return ifExpr.Alt == nil || fallsOff(ifExpr.Then.Statements) || fallsOff(ifExpr.Alt.Statements)
+	}
+	return true
+}
+
+func (c *checker) ifExpr(expr *ast.IfExpression, s *scope) Type {
+	c.expr(expr.Condition, s)
+	then := c.block(expr.Then, s)
+	// Without an else the value is null when the condition is false.
+	var alt Type = Null
+	if expr.Alt != nil {
+		alt = c.block(expr.Alt, s)
+	}
+	switch {
+	case then == nil:
+		return alt
+	case alt == nil:
+		return then
+	}
+	return join(then, alt)
+}
+
+func (c *checker) exprs(exprs []ast.Expression, s *scope) []Type {
+	types := []Type{}
+	for _, expr := range exprs {
+		types = append(types, c.expr(expr, s))
+	}
+	return types
+}
+
+func (c *checker) expr(expr ast.Expression, s *scope) (typ Type) {
+	if c.info != nil && expr != nil {
+		defer func() { c.info.Types[expr] = typ }()
+	}
+	switch expr := expr.(type) {
+	case *ast.IntegerLiteral:
+		return Int
+	case *ast.StringLiteral:
+		return String
+	case *ast.Boolean:
+		return Bool
+	case *ast.InterpolatedString:
+		c.exprs(expr.Parts, s)
+		return String
+	case *ast.Identifier:
+		if typ, ok := s.lookup(expr.Value); ok {
+			return typ
+		}
+		if fn, ok := builtIns[expr.Value]; ok {
+			return fn
+		}
+		return Any
+	case *ast.PrefixExpression:
+		return c.prefix(expr, c.expr(expr.Right, s))
+	case *ast.InfixExpression:
+		return c.infix(expr.Operator, c.expr(expr.Left, s), c.expr(expr.Right, s), expr.Token)
+	case *ast.IfExpression:
+		if typ := c.ifExpr(expr, s); typ != nil {
+			return typ
+		}
+		return Any
+	case *ast.FuncLiteral:
+		return c.function(expr, s)
+	case *ast.CallExpression:
+		return c.call(expr, s)
+	case *ast.ArrayLiteral:
+		elems := c.exprs(expr.Elements, s)
+		if len(elems) == 0 {
+			return &Array{Elem: Any}
+		}
+		elem := elems[0]
+		for _, typ := range elems[1:] {
+			elem = join(elem, typ)
+		}
+		return &Array{Elem: elem}
+	case *ast.HashLiteral:
+		return c.hash(expr, s)
+	case *ast.IndexExpression:
+		return c.index(c.expr(expr.Left, s), expr.Index, s, expr.Token)
+	case *ast.SliceExpression:
+		left := c.expr(expr.Left, s)
+		for _, bound := range []ast.Expression{expr.Start, expr.End} {
+			if bound == nil {
+				continue
+			}
+			if typ := c.expr(bound, s); !AssignableTo(typ, Int) {
+				c.errorf(ast.Start(bound), "slice index is not an integer: %s", typ)
+			}
+		}
+		switch left.(type) {
+		case *Array:
+			return left
+		}
+		if left == String || left == Any {
+			return left
+		}
+		c.errorf(expr.Token, "cannot slice %s", left)
+		return Any
+	case *ast.MemberExpression:
+		c.expr(expr.Object, s)
+		return Any
+	}
+	return Any
+}
+
+func (c *checker) prefix(expr *ast.PrefixExpression, right Type) Type {
+	switch expr.Operator {
+	case "!":
+		return Bool
+	case "&":
+		return Pointer
+	case "-", "~":
+		if right == Int || right == Any {
+			return right
+		}
+	case "*":
+		if right == Pointer || right == Any {
+			return Any
+		}
+	}
+	c.errorf(expr.Token, "unknown operator: %s%s", expr.Operator, right)
+	return Any
+}
+
+// infix returns the type of left operator right, following the rules of
+// the evaluator.
+func (c *checker) infix(operator string, left, right Type, tok token.Token) Type {
+	switch operator {
+	case "==", "!=":
+		return Bool
+	}
+	if left == Any || right == Any {
+		if operator == "<" || operator == ">" {
+			return Bool
+		}
+		return Any
+	}
+	switch {
+	case left == Int && right == Int:
+		if operator == "<" || operator == ">" {
+			return Bool
+		}
+		return Int
+	case left == String && right == String:
+		switch operator {
+		case "+":
+			return String
+		case "<", ">":
+			return Bool
+		}
+	case kind(left) == "array" && kind(right) == "array" && (operator == "<" || operator == ">"):
+		return Bool
+	case kind(left) != kind(right):
+		c.errorf(tok, "type mismatch: %s %s %s", left, operator, right)
+		return Any
+	}
+	c.errorf(tok, "unknown operator: %s %s %s", left, operator, right)
+	return Any
+}
+
+func (c *checker) call(expr *ast.CallExpression, s *scope) Type {
+	callee := c.expr(expr.Function, s)
+	args := c.exprs(expr.Arguments, s)
+	fn, ok := callee.(*Func)
+	if !ok {
+		if callee != Any {
+			c.errorf(ast.Start(expr.Function), "cannot call %s", callee)
+		}
+		return Any
+	}
+
+	name := "function"
+	if ident, ok := expr.Function.(*ast.Identifier); ok {
+		name = ident.Value
+	}
+	switch {
+	case fn.Variadic && len(args) < len(fn.Params)-1:
+		c.errorf(expr.Token, "wrong number of arguments to %s: need at least %d got=%d", name, len(fn.Params)-1, len(args))
+		return fn.Result
+	case !fn.Variadic && len(args) != len(fn.Params):
+		c.errorf(expr.Token, "wrong number of arguments to %s: need=%d got=%d", name, len(fn.Params), len(args))
+		return fn.Result
+	}
+	for i, arg := range args {
+		param := fn.Params[min(i, len(fn.Params)-1)]
+		c.assign(arg, param, ast.Start(expr.Arguments[i]), fmt.Sprintf("argument %d to %s", i+1, name))
+	}
+	return fn.Result
+}
+
+func (c *checker) hash(expr *ast.HashLiteral, s *scope) Type {
+	fields := []Field{}
+	var key, value Type
+	for i, pair := range expr.Pairs {
+		k, v := c.expr(pair.Key, s), c.expr(pair.Value, s)
+		if str, ok := pair.Key.(*ast.StringLiteral); ok && fields != nil {
+			fields = append(fields, Field{Name: str.Value, Type: v})
+		} else {
+			fields = nil
+		}
+		if i == 0 {
+			key, value = k, v
+		} else {
+			key, value = join(key, k), join(value, v)
+		}
+	}
+	if fields != nil {
+		return NewRecord(fields)
+	}
+	return &Hash{Key: key, Value: value}
+}
+
+func (c *checker) index(left Type, index ast.Expression, s *scope, tok token.Token) Type {
+	indexType := c.expr(index, s)
+	switch left := left.(type) {
+	case *Array:
+		if AssignableTo(indexType, Int) {
+			return left.Elem
+		}
+	case *Hash:
+		c.assign(indexType, left.Key, ast.Start(index), "hash index")
+		return left.Value
+	case *Record:
+		if str, ok := index.(*ast.StringLiteral); ok {
+			if field, ok := left.Field(str.Value); ok {
+				return field
+			}
+		}
+		return Any
+	case Basic:
+		switch {
+		case left == Any:
+			return Any
+		case left == String && AssignableTo(indexType, Int):
+			return String
+		}
+	}
+	c.errorf(tok, "cannot index %s with %s", left, indexType)
+	return Any
+}
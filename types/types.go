@@ -0,0 +1,214 @@
+// Package types checks the optional type annotations of chimp programs and
+// infers the types of unannotated expressions where it can. The checking
+// is gradual: values of type any, and everything the checker can not
+// infer, are compatible with every type. The evaluator ignores
+// annotations, so they are only enforced by running this checker, as
+// chimp check and chimp run -check do.
+package types
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Muto1907/interpreterInGo/token"
+)
+
+// Type is the static type of a value. String returns the type in the
+// syntax of annotations.
+type Type interface {
+	String() string
+}
+
+// Basic is a type without structure.
+type Basic string
+
+const (
+	Int     Basic = "int"
+	String  Basic = "string"
+	Bool    Basic = "bool"
+	Null    Basic = "null"
+	Pointer Basic = "pointer"
+	Any     Basic = "any"
+)
+
+var basics = map[string]Basic{
+	"int":     Int,
+	"string":  String,
+	"bool":    Bool,
+	"null":    Null,
+	"pointer": Pointer,
+	"any":     Any,
+}
+
+func (b Basic) String() string { return string(b) }
+
+// Array is an array with elements of type Elem.
+type Array struct {
+	Elem Type
+}
+
+func (a *Array) String() string { return "[" + a.Elem.String() + "]" }
+
+// Hash is a hash with keys of type Key and values of type Value.
+type Hash struct {
+	Key, Value Type
+}
+
+func (h *Hash) String() string { return "{[" + h.Key.String() + "]: " + h.Value.String() + "}" }
+
+// Field is a string key of a record and the type of its value.
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Record is a hash with at least the string keys of its Fields, which are
+// sorted by name.
+type Record struct {
+	Fields []Field
+}
+
+// NewRecord returns a record with the given fields.
+func NewRecord(fields []Field) *Record {
+	sorted := append([]Field{}, fields...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return &Record{Fields: sorted}
+}
+
+// Field returns the type of the field name.
+func (r *Record) Field(name string) (Type, bool) {
+	for _, field := range r.Fields {
+		if field.Name == name {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Record) String() string {
+	fields := []string{}
+	for _, field := range r.Fields {
+		name := field.Name
+		if !isIdentifier(name) {
+			name = `"` + name + `"`
+		}
+		fields = append(fields, name+": "+field.Type.String())
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// isIdentifier reports whether name can be written as a record key
+// without quotes.
+func isIdentifier(name string) bool {
+	if name == "" || token.FindKeywordOrIdent(name) != token.IDENT {
+		return false
+	}
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Func is a function type. The last parameter of a variadic function
+// accepts any number of arguments.
+type Func struct {
+	Params   []Type
+	Result   Type
+	Variadic bool
+}
+
+func (f *Func) String() string {
+	params := []string{}
+	for i, param := range f.Params {
+		if f.Variadic && i == len(f.Params)-1 {
+			params = append(params, "..."+param.String())
+		} else {
+			params = append(params, param.String())
+		}
+	}
+	return "fnc(" + strings.Join(params, ", ") + "): " + f.Result.String()
+}
+
+// Identical reports whether a and b are the same type.
+func Identical(a, b Type) bool {
+	return a.String() == b.String()
+}
+
+// AssignableTo reports whether a value of type from can be used where a
+// value of type to is expected.
+func AssignableTo(from, to Type) bool {
+	if from == Any || to == Any {
+		return true
+	}
+	switch to := to.(type) {
+	case Basic:
+		return from == to
+	case *Array:
+		from, ok := from.(*Array)
+		return ok && AssignableTo(from.Elem, to.Elem)
+	case *Hash:
+		switch from := from.(type) {
+		case *Hash:
+			return AssignableTo(from.Key, to.Key) && AssignableTo(from.Value, to.Value)
+		case *Record:
+			for _, field := range from.Fields {
+				if !AssignableTo(String, to.Key) || !AssignableTo(field.Type, to.Value) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	case *Record:
+		from, ok := from.(*Record)
+		if !ok {
+			return false
+		}
+		for _, field := range to.Fields {
+			typ, ok := from.Field(field.Name)
+			if !ok || !AssignableTo(typ, field.Type) {
+				return false
+			}
+		}
+		return true
+	case *Func:
+		from, ok := from.(*Func)
+		if !ok || from.Variadic != to.Variadic || len(from.Params) != len(to.Params) {
+			return false
+		}
+		for i := range to.Params {
+			if !AssignableTo(to.Params[i], from.Params[i]) {
+				return false
+			}
+		}
+		return AssignableTo(from.Result, to.Result)
+	}
+	return false
+}
+
+// join returns the type of a value that is either of type a or b.
+func join(a, b Type) Type {
+	if Identical(a, b) {
+		return a
+	}
+	return Any
+}
+
+// kind names the runtime type of values of type t, which decides between
+// type mismatch and unknown operator errors.
+func kind(t Type) string {
+	switch t := t.(type) {
+	case Basic:
+		return string(t)
+	case *Array:
+		return "array"
+	case *Hash, *Record:
+		return "hash"
+	case *Func:
+		return "function"
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Muto1907/interpreterInGo/ast"
+	"github.com/Muto1907/interpreterInGo/lexer"
+	"github.com/Muto1907/interpreterInGo/parser"
+	"github.com/Muto1907/interpreterInGo/stdlib"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`let x: int = "five";`, []string{"1:14: cannot use string as int in declaration of x"}},
+		{`let x: string = "five"; let y: any = x; let z: int = y;`, nil},
+		{`let x = 5; x = "five";`, []string{"1:16: cannot use string as int in assignment to x"}},
+		{`let x = null; x = "five"; x = 1;`, nil},
+		{`let x = 5; x += "a"; x++;`, []string{"1:14: type mismatch: int + string"}},
+		{`let s = "a"; s++;`, []string{"1:15: type mismatch: string + int"}},
+		{`1 + true; "a" - "b"; -"a"; *1; !5; 1 < 2 == true`, []string{
+			"1:3: type mismatch: int + bool",
+			"1:15: unknown operator: string - string",
+			"1:22: unknown operator: -string",
+			"1:28: unknown operator: *int",
+		}},
+		{`let f = fnc(x: int, y: string): bool { x > len(y) }; f(1, "a"); f("a", 1); f(1);`, []string{
+			"1:67: cannot use string as int in argument 1 to f",
+			"1:72: cannot use int as string in argument 2 to f",
+			"1:77: wrong number of arguments to f: need=2 got=1",
+		}},
+		{`let f = fnc(x: int): string { if (x > 0) { return "pos" }; x };`, []string{"1:60: cannot use int as string in return"}},
+		{`let f = fnc(x: int): string { return x; };`, []string{"1:38: cannot use int as string in return"}},
+		{`let f = fnc(): int { };`, []string{"1:22: missing return at end of function returning int"}},
+		{`let f = fnc(x: bool): int { if (x) { 1 } };`, []string{"1:42: missing return at end of function returning int"}},
+		{`let f = fnc(x: bool): int { if (x) { return 1 } };`, []string{"1:49: missing return at end of function returning int"}},
+		{`let f = fnc(x: bool): int { if (x) { 1 } else { let y = 2; } };`, []string{"1:62: missing return at end of function returning int"}},
+		{`let f = fnc(x: bool): int { while (x) { return 1; } };`, []string{"1:53: missing return at end of function returning int"}},
+		{`let f = fnc(x: bool): int { if (x) { return 1 } else { 2 } };`, nil},
+		{`let f = fnc(x: bool): null { let y = 1; }; let g = fnc(x: bool): any { if (x) { 1 } };`, nil},
+		{`let f = fnc(x: bool) { if (x) { 1 } }; let y: int = f(true);`, nil},
+		{`let f = fnc(x: bool) { if (x) { 1 } }; let y: string = f(true) + 1;`, nil},
+		{`let f = fnc(x) { x * 2 }; let y: string = f(1);`, nil},
+		{`let f = fnc(x: int) { x * 2 }; let y: string = f(1);`, []string{"1:48: cannot use int as string in declaration of y"}},
+		{`let f = fnc(x) { if (x) { return "a" }; "b" }; let y: int = f(1);`, []string{"1:61: cannot use string as int in declaration of y"}},
+		{`let fact = fnc(n: int): int { if (n < 2) { 1 } else { n * fact(n - 1) } }; fact("3");`, []string{"1:81: cannot use string as int in argument 1 to fact"}},
+		{`let apply: fnc(fnc(int): int, int): int = fnc(f, x) { f(x) }; apply(fnc(x: int): int { x }, 1); apply(fnc(s: string): int { 1 }, 1);`,
+			[]string{"1:103: cannot use fnc(string): int as fnc(int): int in argument 1 to apply"}},
+		{`let a: [int] = [1, 2, 3]; let b: [string] = a; let c: [int] = []; let d: [any] = [1, "a"];`, []string{"1:45: cannot use [int] as [string] in declaration of b"}},
+		{`let a = [1, 2]; let s: string = a[0]; let t: string = "abc"[1]; a["x"];`, []string{
+			"1:33: cannot use int as string in declaration of s",
+			"1:66: cannot index [int] with string",
+		}},
+		{`let p: {name: string, age: int} = {"name": "ann", "age": 3, "extra": true}; let n: int = p["name"];`,
+			[]string{"1:90: cannot use string as int in declaration of n"}},
+		{`let p: {name: string, age: int} = {"name": "ann"};`, []string{`1:35: cannot use {name: string} as {age: int, name: string} in declaration of p`}},
+		{`let h: {[string]: int} = {"a": 1, "b": 2}; let v: string = h["a"]; h[1];`, []string{
+			"1:60: cannot use int as string in declaration of v",
+			"1:70: cannot use int as string in hash index",
+		}},
+		{`let h: {[string]: int} = {"a": "b"};`, []string{`1:26: cannot use {a: string} as {[string]: int} in declaration of h`}},
+		{`let [a, b] = [1, 2]; let s: string = a; let [c] = 5; let {d} = "x";`, []string{
+			"1:38: cannot use int as string in declaration of s",
+			"1:45: cannot destructure int as array [c]",
+			"1:58: cannot destructure string as hash {d}",
+		}},
+		{`let {name, ...rest} = {"name": 1}; let s: string = name;`, []string{"1:52: cannot use int as string in declaration of s"}},
+		{`let x: number = 1; let f = fnc(a: strng) { a };`, []string{"1:8: unknown type number", "1:35: unknown type strng"}},
+		{`let x = 1; x(); "s"(1); let f: fnc(int) = fnc(x: string) { x };`, []string{
+			"1:12: cannot call int",
+			"1:17: cannot call string",
+			"1:43: cannot use fnc(string): string as fnc(int): any in declaration of f",
+		}},
+		{`split("a b"); split(); puts(); puts(1, "a"); let n: int = range(3)[0]; upper(1);`, []string{
+			"1:20: wrong number of arguments to split: need at least 1 got=0",
+			"1:78: cannot use int as string in argument 1 to upper",
+		}},
+		{`let x = 1; let s = "${x + "a"}";`, []string{"1:25: type mismatch: int + string"}},
+		{`let x = 1; if (true) { let x = "a"; let y: string = x; }; let z: int = x;`, nil},
+		{`import "std/math" as m; let x: int = m.max(1, 2); let [a, ...rest] = [1, 2]; let s: [int] = rest;`, nil},
+	}
+
+	for i, tt := range tests {
+		errs, err := Source(tt.input)
+		if err != nil {
+			t.Fatalf("tests[%d] - unexpected error: %s", i, err)
+		}
+		got := []string{}
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("tests[%d] - wrong errors for %s\nexpected=%q\ngot=     %q", i, tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestInfo(t *testing.T) {
+	input := `let add = fnc(a: int, b) { a + 1 }; let [x, ...rest] = ["a"]; let n = add(1, x);`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	info := NewInfo()
+	if errs := Check(program, info); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	defs := map[string]string{}
+	for ident, typ := range info.Defs {
+		defs[ident.Value] = typ.String()
+	}
+	expected := map[string]string{
+		"add":  "fnc(int, any): int",
+		"a":    "int",
+		"b":    "any",
+		"x":    "string",
+		"rest": "[string]",
+		"n":    "int",
+	}
+	for name, typ := range expected {
+		if defs[name] != typ {
+			t.Errorf("wrong type for %s. expected=%q got=%q", name, typ, defs[name])
+		}
+	}
+
+	call := program.Statements[2].(*ast.LetStatement).Value
+	if typ := info.Types[call]; typ != Int {
+		t.Errorf("wrong type for %s. expected=int got=%v", call, typ)
+	}
+}
+
+func TestAssignableTo(t *testing.T) {
+	record := NewRecord([]Field{{"name", String}, {"age", Int}})
+	tests := []struct {
+		from, to Type
+		expected bool
+	}{
+		{Int, Int, true},
+		{Int, String, false},
+		{Any, Int, true},
+		{Int, Any, true},
+		{&Array{Elem: Int}, &Array{Elem: Any}, true},
+		{&Array{Elem: Int}, &Array{Elem: String}, false},
+		{record, NewRecord([]Field{{"name", String}}), true},
+		{NewRecord([]Field{{"name", String}}), record, false},
+		{record, &Hash{Key: String, Value: Any}, true},
+		{record, &Hash{Key: String, Value: Int}, false},
+		{&Hash{Key: String, Value: Int}, record, false},
+		{&Func{Params: []Type{Any}, Result: Int}, &Func{Params: []Type{String}, Result: Any}, true},
+		{&Func{Params: []Type{String}, Result: Int}, &Func{Params: []Type{Any}, Result: Int}, true},
+		{&Func{Params: []Type{String}, Result: Int}, &Func{Params: []Type{Int}, Result: Int}, false},
+		{&Func{Params: []Type{}, Result: Int}, &Func{Params: []Type{Int}, Result: Int}, false},
+	}
+
+	for _, tt := range tests {
+		if got := AssignableTo(tt.from, tt.to); got != tt.expected {
+			t.Errorf("AssignableTo(%s, %s) = %t, expected %t", tt.from, tt.to, got, tt.expected)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		typ      Type
+		expected string
+	}{
+		{&Array{Elem: &Hash{Key: String, Value: Int}}, "[{[string]: int}]"},
+		{NewRecord([]Field{{"zip code", Int}, {"name", String}, {"if", Bool}}), `{"if": bool, name: string, "zip code": int}`},
+		{&Func{Params: []Type{Int, String}, Result: Bool}, "fnc(int, string): bool"},
+		{builtIns["puts"], "fnc(...any): null"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.typ.String(); got != tt.expected {
+			t.Errorf("wrong string. expected=%q got=%q", tt.expected, got)
+		}
+	}
+}
+
+func TestStdlibChecks(t *testing.T) {
+	for _, name := range stdlib.Modules() {
+		src, _ := stdlib.Source(name)
+		errs, err := Source(src)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		for _, e := range errs {
+			t.Errorf("%s:%s", name, e)
+		}
+	}
+}